@@ -0,0 +1,139 @@
+package calendar
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/scheduling"
+)
+
+// ReviewerCalendar has an event for each booking of the reviewer in the challenges
+func ReviewerCalendar(reviewer models.Reviewer, challenges ...models.ChallengeSetup) Calendar {
+	events := make([]Event, 0, len(reviewer.Bookings))
+	for _, challenge := range challenges {
+		events = append(events, bookingEvents(reviewer, challenge, false)...)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+
+	name := fmt.Sprintf("Bookings - %s", reviewer.Name)
+	if len(challenges) == 1 {
+		name = fmt.Sprintf("%s bookings - %s", challenges[0].Name, reviewer.Name)
+	}
+	return Calendar{
+		Name:   name,
+		Events: events,
+	}
+}
+
+// ChallengeCalendar has an event for each booking of all reviewers of the challenge
+func ChallengeCalendar(challenge models.ChallengeSetup, reviewers []models.Reviewer) Calendar {
+	events := make([]Event, 0, 50)
+	for _, reviewer := range reviewers {
+		events = append(events, bookingEvents(reviewer, challenge, true)...)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+
+	return Calendar{
+		Name:   fmt.Sprintf("%s bookings", challenge.Name),
+		Events: events,
+	}
+}
+
+func bookingEvents(reviewer models.Reviewer, challenge models.ChallengeSetup, withReviewer bool) []Event {
+	events := make([]Event, 0, len(reviewer.Bookings))
+	for _, booking := range reviewer.Bookings {
+		if !booking.IsForChallenge(challenge.ID) {
+			continue
+		}
+		event, err := bookingEvent(booking, reviewer, challenge, withReviewer)
+		if err != nil {
+			log.Println("[ERROR] Booking is not for a valid slot - ", booking.Occurrence.Key())
+			continue
+		}
+		events = append(events, event)
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	return events
+}
+
+func bookingEvent(booking models.Booking, reviewer models.Reviewer, challenge models.ChallengeSetup, withReviewer bool) (Event, error) {
+	slot := challenge.Slots[booking.Occurrence.SlotID]
+	if slot == nil {
+		return Event{}, fmt.Errorf("[ERROR] Unknown slot - %s", booking.Occurrence.SlotID)
+	}
+	weekStart, err := scheduling.WeekOfOccurrence(booking.Occurrence)
+	if err != nil {
+		return Event{}, err
+	}
+	start, end, err := slot.Interval(weekStart)
+	if err != nil {
+		return Event{}, err
+	}
+
+	summary := models.BookingKindLabel(booking.Kind)
+	if booking.CandidateName != "" {
+		summary = fmt.Sprintf("%s: %s", summary, booking.CandidateName)
+	}
+	if withReviewer {
+		summary = fmt.Sprintf("%s (%s)", summary, reviewer.Name)
+	}
+
+	description := []string{fmt.Sprintf("Challenge: %s", challenge.Name)}
+	if booking.Notes != "" {
+		description = append(description, booking.Notes)
+	}
+
+	return Event{
+		UID:         bookingUID(booking, reviewer),
+		Start:       start,
+		End:         end,
+		Summary:     summary,
+		Description: strings.Join(description, "\n"),
+		URL:         booking.ChallengeURL,
+		Created:     booking.CreatedAt,
+	}, nil
+}
+
+// bookingUID is stable across feed refreshes, so calendar clients update the events instead of duplicating them
+func bookingUID(booking models.Booking, reviewer models.Reviewer) string {
+	id := booking.ID
+	if id == "" {
+		// Bookings migrated from the week keyed schedule have no ID
+		id = fmt.Sprintf("%s-%s", reviewer.ID, booking.Occurrence.Key())
+	}
+	return fmt.Sprintf("%s@challenge-bot", id)
+}
+
+// PairingCalendar has the live pairing session the candidate picked, with the reviewers of the session
+func PairingCalendar(candidate models.Candidate, challenge models.ChallengeSetup, reviewers []models.Reviewer) (Calendar, error) {
+	if !candidate.HasPairing() {
+		return Calendar{}, fmt.Errorf("[ERROR] Candidate has no pairing session - %s", candidate.ID)
+	}
+	booking := models.Booking{
+		ID:            fmt.Sprintf("pairing-%s", candidate.ID),
+		Occurrence:    candidate.Pairing,
+		CandidateName: candidate.Name,
+		ChallengeURL:  candidate.ChallengeURL,
+		Kind:          models.LivePairing,
+		CreatedAt:     candidate.CreatedAt,
+	}
+	event, err := bookingEvent(booking, models.Reviewer{}, challenge, false)
+	if err != nil {
+		return Calendar{}, err
+	}
+
+	names := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		names = append(names, reviewer.Name)
+	}
+	if len(names) > 0 {
+		event.Description = fmt.Sprintf("%s\nReviewers: %s", event.Description, strings.Join(names, ", "))
+	}
+	return Calendar{
+		Name:   fmt.Sprintf("%s live pairing", challenge.Name),
+		Events: []Event{event},
+	}, nil
+}
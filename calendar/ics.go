@@ -0,0 +1,118 @@
+package calendar
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	productID     = "-//challenge-bot//Reviewer Bookings//EN"
+	utcTimeFormat = "20060102T150405Z"
+	// Lines longer than 75 octets are folded, see RFC 5545 section 3.1
+	maxLineLength = 75
+)
+
+type Event struct {
+	UID         string
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	URL         string
+	Created     time.Time
+}
+
+// Calendar is an iCalendar (RFC 5545) object with a list of events
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Write writes the calendar in the iCalendar format
+func (c Calendar) Write(w io.Writer, now time.Time) error {
+	lw := lineWriter{w: w}
+	lw.line("BEGIN", "VCALENDAR")
+	lw.line("VERSION", "2.0")
+	lw.line("PRODID", productID)
+	lw.line("CALSCALE", "GREGORIAN")
+	lw.line("METHOD", "PUBLISH")
+	if c.Name != "" {
+		lw.line("X-WR-CALNAME", escapeText(c.Name))
+	}
+
+	for _, event := range c.Events {
+		lw.line("BEGIN", "VEVENT")
+		lw.line("UID", event.UID)
+		lw.line("DTSTAMP", formatTime(now))
+		if !event.Created.IsZero() {
+			lw.line("CREATED", formatTime(event.Created))
+		}
+		lw.line("DTSTART", formatTime(event.Start))
+		lw.line("DTEND", formatTime(event.End))
+		lw.line("SUMMARY", escapeText(event.Summary))
+		if event.Description != "" {
+			lw.line("DESCRIPTION", escapeText(event.Description))
+		}
+		if event.URL != "" {
+			lw.line("URL", event.URL)
+		}
+		lw.line("END", "VEVENT")
+	}
+
+	lw.line("END", "VCALENDAR")
+	return lw.err
+}
+
+func (c Calendar) String() string {
+	var buf bytes.Buffer
+	c.Write(&buf, time.Now())
+	return buf.String()
+}
+
+type lineWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (lw *lineWriter) line(name, value string) {
+	if lw.err != nil {
+		return
+	}
+	_, lw.err = io.WriteString(lw.w, foldLine(fmt.Sprintf("%s:%s", name, value)))
+}
+
+// foldLine splits the content line into lines of at most 75 octets, continuation lines start with a space.
+// Multi-byte UTF-8 characters are never split.
+func foldLine(line string) string {
+	var b strings.Builder
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > maxLineLength {
+			b.WriteString("\r\n ")
+			length = 1
+		}
+		b.WriteRune(r)
+		length += size
+	}
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+func escapeText(text string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		";", "\\;",
+		",", "\\,",
+		"\r\n", "\\n",
+		"\n", "\\n",
+	)
+	return replacer.Replace(text)
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(utcTimeFormat)
+}
@@ -0,0 +1,89 @@
+package calendar
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritingCalendar(t *testing.T) {
+	now := time.Date(2020, 1, 2, 8, 0, 0, 0, time.UTC)
+	cal := Calendar{
+		Name: "Reviews",
+		Events: []Event{
+			Event{
+				UID:         "abc@challenge-bot",
+				Start:       time.Date(2020, 1, 6, 9, 0, 0, 0, time.UTC),
+				End:         time.Date(2020, 1, 6, 11, 0, 0, 0, time.UTC),
+				Summary:     "Code review: Doe, Jane",
+				Description: "Notes; line one\nline two",
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := cal.Write(&buf, now)
+
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//challenge-bot//Reviewer Bookings//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Reviews",
+		"BEGIN:VEVENT",
+		"UID:abc@challenge-bot",
+		"DTSTAMP:20200102T080000Z",
+		"DTSTART:20200106T090000Z",
+		"DTEND:20200106T110000Z",
+		"SUMMARY:Code review: Doe\\, Jane",
+		"DESCRIPTION:Notes\\; line one\\nline two",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n"), buf.String())
+}
+
+func TestFoldingLongLines(t *testing.T) {
+	folded := foldLine("DESCRIPTION:" + strings.Repeat("ü", 40))
+
+	lines := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+	assert.Equal(t, 2, len(lines))
+	for _, line := range lines {
+		assert.True(t, len(line) <= maxLineLength)
+	}
+	assert.True(t, strings.HasPrefix(lines[1], " "))
+	assert.Equal(t, "DESCRIPTION:"+strings.Repeat("ü", 40), lines[0]+strings.TrimPrefix(lines[1], " "))
+}
+
+func TestBookingEventsUseSlotTimeZone(t *testing.T) {
+	challenge := models.ChallengeSetup{
+		Name: "Mobile",
+		Slots: map[models.SlotID]*models.Slot{
+			"MondayMorning": &models.Slot{ID: "MondayMorning", Day: "Monday", StartTime: "9:00", EndTime: "11:00", TimeZone: "Europe/Berlin"},
+		},
+	}
+	reviewer := models.Reviewer{
+		ID:   "jane-123",
+		Name: "jane",
+		Bookings: map[string]models.Booking{
+			"2020-01-06_MondayMorning": models.Booking{
+				Occurrence:    models.SlotOccurrence{Date: "2020-01-06", SlotID: "MondayMorning"},
+				CandidateName: "John",
+				Kind:          models.LivePairing,
+			},
+		},
+	}
+
+	cal := ReviewerCalendar(reviewer, challenge)
+
+	assert.Equal(t, 1, len(cal.Events))
+	assert.Equal(t, time.Date(2020, 1, 6, 8, 0, 0, 0, time.UTC), cal.Events[0].Start.UTC())
+	assert.Equal(t, "Live pairing: John", cal.Events[0].Summary)
+	assert.Equal(t, "jane-123-2020-01-06_MondayMorning@challenge-bot", cal.Events[0].UID)
+}
@@ -0,0 +1,441 @@
+package calendar
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/scheduling"
+)
+
+const (
+	localTimeFormat = "20060102T150405"
+	dateValueFormat = "20060102"
+	// Upper bound of the occurrences expanded for a recurring event
+	maxOccurrences = 5000
+)
+
+type contentLine struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+type parsedEvent struct {
+	uid          string
+	start        time.Time
+	end          time.Time
+	duration     time.Duration
+	hasEnd       bool
+	allDay       bool
+	transparent  bool
+	cancelled    bool
+	rrule        string
+	exdates      []time.Time
+	recurrenceID time.Time
+}
+
+// ParseBusy reads an iCalendar file and returns the busy time blocks between from and to. Free (transparent)
+// and cancelled events are skipped, recurring events are expanded. Times without a time zone are in loc.
+func ParseBusy(r io.Reader, from, to time.Time, loc *time.Location) ([]scheduling.TimeRange, error) {
+	lines, err := readContentLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := parseEvents(lines, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Modified instances of recurring events replace the original occurrence
+	overridden := make(map[string]bool)
+	for _, event := range events {
+		if !event.recurrenceID.IsZero() {
+			overridden[occurrenceKey(event.uid, event.recurrenceID)] = true
+		}
+	}
+
+	busy := make([]scheduling.TimeRange, 0, len(events))
+	for _, event := range events {
+		if event.transparent || event.cancelled {
+			continue
+		}
+		for _, block := range event.expand(to) {
+			if !event.recurrenceID.IsZero() || !overridden[occurrenceKey(event.uid, block.Start)] {
+				if block.Overlaps(from, to) {
+					busy = append(busy, block)
+				}
+			}
+		}
+	}
+
+	sort.Slice(busy, func(i, j int) bool { return busy[i].Start.Before(busy[j].Start) })
+	return busy, nil
+}
+
+// readContentLines unfolds the lines and splits them into name, parameters and value
+func readContentLines(r io.Reader) ([]contentLine, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	unfolded := make([]string, 0, 100)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(unfolded) > 0 {
+			unfolded[len(unfolded)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			unfolded = append(unfolded, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(unfolded) == 0 || !strings.EqualFold(unfolded[0], "BEGIN:VCALENDAR") {
+		return nil, errors.New("[ERROR] Not an iCalendar file")
+	}
+
+	lines := make([]contentLine, 0, len(unfolded))
+	for _, line := range unfolded {
+		parsed, err := parseContentLine(line)
+		if err != nil {
+			log.Println("[ERROR] Skipping invalid calendar line - ", line)
+			continue
+		}
+		lines = append(lines, parsed)
+	}
+	return lines, nil
+}
+
+func parseContentLine(line string) (contentLine, error) {
+	// The value starts after the first colon that is not in a quoted parameter value
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return contentLine{}, errors.New("[ERROR] Missing value")
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string)
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) == 2 {
+			params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], "\"")
+		}
+	}
+	return contentLine{
+		name:   strings.ToUpper(parts[0]),
+		params: params,
+		value:  line[colon+1:],
+	}, nil
+}
+
+func parseEvents(lines []contentLine, loc *time.Location) ([]parsedEvent, error) {
+	events := make([]parsedEvent, 0, 50)
+	var event *parsedEvent
+	// Components nested in events, e.g. alarms, have their own properties
+	nested := 0
+
+	for _, line := range lines {
+		switch {
+		case line.name == "BEGIN" && strings.EqualFold(line.value, "VEVENT"):
+			event = &parsedEvent{}
+			nested = 0
+			continue
+		case line.name == "END" && strings.EqualFold(line.value, "VEVENT"):
+			if event != nil {
+				if err := event.finish(); err != nil {
+					log.Println("[ERROR] Skipping calendar event - ", err)
+				} else {
+					events = append(events, *event)
+				}
+			}
+			event = nil
+			continue
+		}
+		if event == nil {
+			continue
+		}
+		if line.name == "BEGIN" {
+			nested++
+			continue
+		}
+		if line.name == "END" {
+			nested--
+			continue
+		}
+		if nested > 0 {
+			continue
+		}
+
+		var err error
+		switch line.name {
+		case "UID":
+			event.uid = line.value
+		case "DTSTART":
+			event.start, event.allDay, err = parseDateTime(line, loc)
+		case "DTEND":
+			event.end, _, err = parseDateTime(line, loc)
+			event.hasEnd = true
+		case "DURATION":
+			event.duration, err = parseDuration(line.value)
+		case "TRANSP":
+			event.transparent = strings.EqualFold(line.value, "TRANSPARENT")
+		case "STATUS":
+			event.cancelled = strings.EqualFold(line.value, "CANCELLED")
+		case "RRULE":
+			event.rrule = line.value
+		case "EXDATE":
+			for _, value := range strings.Split(line.value, ",") {
+				exdate, _, err := parseDateTime(contentLine{name: line.name, params: line.params, value: value}, loc)
+				if err == nil {
+					event.exdates = append(event.exdates, exdate)
+				}
+			}
+		case "RECURRENCE-ID":
+			event.recurrenceID, _, err = parseDateTime(line, loc)
+		}
+		if err != nil {
+			log.Println("[ERROR] Invalid calendar property - ", line.name, line.value)
+		}
+	}
+	return events, nil
+}
+
+func (e *parsedEvent) finish() error {
+	if e.start.IsZero() {
+		return fmt.Errorf("event %s has no start", e.uid)
+	}
+	if !e.hasEnd {
+		switch {
+		case e.duration > 0:
+			e.end = e.start.Add(e.duration)
+		case e.allDay:
+			e.end = e.start.AddDate(0, 0, 1)
+		default:
+			e.end = e.start
+		}
+	}
+	if e.end.Before(e.start) {
+		return fmt.Errorf("event %s ends before it starts", e.uid)
+	}
+	return nil
+}
+
+// parseDateTime reads DATE and DATE-TIME values, in UTC, with a TZID or floating
+func parseDateTime(line contentLine, loc *time.Location) (time.Time, bool, error) {
+	value := strings.TrimSpace(line.value)
+	if line.params["VALUE"] == "DATE" || len(value) == len(dateValueFormat) {
+		t, err := time.ParseInLocation(dateValueFormat, value, loc)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcTimeFormat, value)
+		return t, false, err
+	}
+
+	valueLoc := loc
+	if tzid := line.params["TZID"]; tzid != "" {
+		tzLoc, err := time.LoadLocation(tzid)
+		if err != nil {
+			log.Println("[ERROR] Unknown time zone in calendar, using the reviewer time zone - ", tzid)
+		} else {
+			valueLoc = tzLoc
+		}
+	}
+	t, err := time.ParseInLocation(localTimeFormat, value, valueLoc)
+	return t, false, err
+}
+
+var durationRegexp = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+func parseDuration(value string) (time.Duration, error) {
+	m := durationRegexp.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil {
+		return 0, fmt.Errorf("[ERROR] Invalid duration - %s", value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		n, _ := strconv.Atoi(m[i+2])
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// expand returns the busy blocks of the event and its recurrences starting before the end time
+func (e parsedEvent) expand(end time.Time) []scheduling.TimeRange {
+	length := e.end.Sub(e.start)
+	if e.rrule == "" {
+		return []scheduling.TimeRange{{Start: e.start, End: e.end}}
+	}
+
+	rule, err := parseRecurrenceRule(e.rrule, e.start.Location())
+	if err != nil {
+		log.Println("[ERROR] Unsupported recurrence, using the first occurrence only - ", e.rrule)
+		return []scheduling.TimeRange{{Start: e.start, End: e.end}}
+	}
+
+	excluded := make(map[int64]bool)
+	for _, exdate := range e.exdates {
+		excluded[exdate.Unix()] = true
+	}
+
+	blocks := make([]scheduling.TimeRange, 0, 10)
+	for _, start := range rule.occurrences(e.start, end) {
+		if !excluded[start.Unix()] {
+			blocks = append(blocks, scheduling.TimeRange{Start: start, End: start.Add(length)})
+		}
+	}
+	return blocks
+}
+
+type recurrenceRule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    []time.Weekday
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+func parseRecurrenceRule(value string, loc *time.Location) (recurrenceRule, error) {
+	rule := recurrenceRule{interval: 1}
+	var byDay []string
+	for _, part := range strings.Split(value, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch strings.ToUpper(kv[0]) {
+		case "FREQ":
+			rule.freq = strings.ToUpper(kv[1])
+		case "INTERVAL":
+			interval, err := strconv.Atoi(kv[1])
+			if err == nil && interval > 0 {
+				rule.interval = interval
+			}
+		case "COUNT":
+			rule.count, _ = strconv.Atoi(kv[1])
+		case "UNTIL":
+			until, _, err := parseDateTime(contentLine{value: kv[1], params: map[string]string{}}, loc)
+			if err != nil {
+				return rule, err
+			}
+			rule.until = until
+		case "BYDAY":
+			byDay = strings.Split(strings.ToUpper(kv[1]), ",")
+		case "WKST":
+		default:
+			return rule, fmt.Errorf("[ERROR] Unsupported recurrence part - %s", part)
+		}
+	}
+
+	switch rule.freq {
+	case "DAILY", "MONTHLY", "YEARLY":
+		if len(byDay) > 0 {
+			return rule, fmt.Errorf("[ERROR] Unsupported BYDAY for %s", rule.freq)
+		}
+	case "WEEKLY":
+		for _, code := range byDay {
+			weekday, ok := weekdayCodes[code]
+			if !ok {
+				return rule, fmt.Errorf("[ERROR] Unsupported BYDAY - %s", code)
+			}
+			rule.byDay = append(rule.byDay, weekday)
+		}
+	default:
+		return rule, fmt.Errorf("[ERROR] Unsupported frequency - %s", rule.freq)
+	}
+	return rule, nil
+}
+
+// occurrences lists the starts of the recurrences, in order, that start before end
+func (rule recurrenceRule) occurrences(start, end time.Time) []time.Time {
+	starts := make([]time.Time, 0, 10)
+	add := func(t time.Time) bool {
+		if t.Before(start) {
+			return true
+		}
+		if (rule.count > 0 && len(starts) >= rule.count) || (!rule.until.IsZero() && t.After(rule.until)) || !t.Before(end) {
+			return false
+		}
+		starts = append(starts, t)
+		return len(starts) < maxOccurrences
+	}
+
+	y, m, d := start.Date()
+	h, mi, s := start.Clock()
+	loc := start.Location()
+	for i := 0; ; i++ {
+		step := i * rule.interval
+		switch rule.freq {
+		case "DAILY":
+			if !add(time.Date(y, m, d+step, h, mi, s, 0, loc)) {
+				return starts
+			}
+		case "WEEKLY":
+			days := rule.byDay
+			if len(days) == 0 {
+				days = []time.Weekday{start.Weekday()}
+			}
+			// Weeks start on Monday
+			monday := d - (int(start.Weekday())+6)%7 + 7*step
+			offsets := make([]int, 0, len(days))
+			for _, day := range days {
+				offsets = append(offsets, (int(day)+6)%7)
+			}
+			sort.Ints(offsets)
+			for _, offset := range offsets {
+				if !add(time.Date(y, m, monday+offset, h, mi, s, 0, loc)) {
+					return starts
+				}
+			}
+		case "MONTHLY", "YEARLY":
+			t := time.Date(y, m+time.Month(step), d, h, mi, s, 0, loc)
+			if rule.freq == "YEARLY" {
+				t = time.Date(y+step, m, d, h, mi, s, 0, loc)
+			}
+			// Months without the day, e.g. the 31st, are skipped
+			if t.Day() == d && !add(t) {
+				return starts
+			}
+		}
+		if i > maxOccurrences {
+			return starts
+		}
+	}
+}
+
+func occurrenceKey(uid string, start time.Time) string {
+	return fmt.Sprintf("%s_%d", uid, start.Unix())
+}
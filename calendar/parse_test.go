@@ -0,0 +1,79 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keremk/challenge-bot/scheduling"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCalendar = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//Test//EN
+BEGIN:VEVENT
+UID:standup
+DTSTART;TZID=Europe/Berlin:20200106T093000
+DTEND;TZID=Europe/Berlin:20200106T100000
+RRULE:FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4
+EXDATE;TZID=Europe/Berlin:20200108T093000
+SUMMARY:Stand
+ up
+BEGIN:VALARM
+TRIGGER:-PT15M
+DTSTART:20000101T000000Z
+END:VALARM
+END:VEVENT
+BEGIN:VEVENT
+UID:standup
+RECURRENCE-ID;TZID=Europe/Berlin:20200113T093000
+DTSTART;TZID=Europe/Berlin:20200113T110000
+DURATION:PT30M
+END:VEVENT
+BEGIN:VEVENT
+UID:focus
+DTSTART:20200107T120000Z
+DTEND:20200107T130000Z
+TRANSP:TRANSPARENT
+END:VEVENT
+BEGIN:VEVENT
+UID:holiday
+DTSTART;VALUE=DATE:20200110
+END:VEVENT
+END:VCALENDAR
+`
+
+func TestParsingBusyTimes(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	from := time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 1, 20, 0, 0, 0, 0, time.UTC)
+
+	busy, err := ParseBusy(strings.NewReader(strings.ReplaceAll(testCalendar, "\n", "\r\n")), from, to, berlin)
+
+	assert.Nil(t, err)
+	expected := []scheduling.TimeRange{
+		{Start: time.Date(2020, 1, 6, 8, 30, 0, 0, time.UTC), End: time.Date(2020, 1, 6, 9, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2020, 1, 9, 23, 0, 0, 0, time.UTC), End: time.Date(2020, 1, 10, 23, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2020, 1, 13, 10, 0, 0, 0, time.UTC), End: time.Date(2020, 1, 13, 10, 30, 0, 0, time.UTC)},
+		{Start: time.Date(2020, 1, 15, 8, 30, 0, 0, time.UTC), End: time.Date(2020, 1, 15, 9, 0, 0, 0, time.UTC)},
+	}
+	assert.Equal(t, len(expected), len(busy))
+	for i := range expected {
+		assert.True(t, expected[i].Start.Equal(busy[i].Start), "start %d is %s", i, busy[i].Start)
+		assert.True(t, expected[i].End.Equal(busy[i].End), "end %d is %s", i, busy[i].End)
+	}
+}
+
+func TestParsingInvalidCalendar(t *testing.T) {
+	_, err := ParseBusy(strings.NewReader("<html></html>"), time.Now(), time.Now(), time.UTC)
+
+	assert.NotNil(t, err)
+}
+
+func TestParsingDurations(t *testing.T) {
+	d, err := parseDuration("P1DT2H30M")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 26*time.Hour+30*time.Minute, d)
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"log"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/models"
+)
+
+// Renames the slots created before the slot IDs were unique across challenges. It only needs to run once, running it
+// again does not change anything.
+func main() {
+	env := config.NewEnvironment("production")
+
+	err := models.MigrateSlotIDs(env)
+	if err != nil {
+		log.Fatal("Migrating the slot IDs failed ", err)
+	}
+	log.Println("Migrated the slot IDs")
+}
@@ -8,11 +8,12 @@ import (
 
 type Environment struct {
 	Port                     string `envconfig:"PORT" default:"4390"`
-	VerificationToken        string `envconfig:"VERIFICATION_TOKEN" required:"true"`
+	VerificationToken        string `envconfig:"VERIFICATION_TOKEN"`
 	GithubToken              string `envconfig:"GITHUB_TOKEN" required:"true"`
 	SlackClientID            string `envconfig:"SLACK_CLIENT_ID" required:"true"`
 	SlackClientSecret        string `envconfig:"SLACK_CLIENT_SECRET" required:"true"`
 	SlackRedirectURI         string `envconfig:"SLACK_REDIRECT_URI" required:"true"`
+	SlackSigningSecret       string `envconfig:"SLACK_SIGNING_SECRET" required:"true"`
 	GithubAppID              string `envconfig:"GITHUB_APP_IDENTIFIER" required:"true"`
 	GithubClientID           string `envconfig:"GITHUB_CLIENT_ID" required:"true"`
 	GithubClientSecret       string `envconfig:"GITHUB_CLIENT_SECRET" required:"true"`
@@ -24,6 +25,13 @@ type Environment struct {
 	DebugOn                  bool   `envconfig:"DEBUG_ON" required:"true"`
 	MongoDBConnectionString  string `envconfig:"MONGODB_CONNECTION_STRING" required:"true"`
 	MongoDBDatabaseName      string `envconfig:"MONGODB_DATABASE_NAME" required:"true"`
+	ServerURL                string `envconfig:"SERVER_URL"`
+	// LinkSecret signs the links sent to candidates, e.g. to pick a live pairing session
+	LinkSecret string `envconfig:"LINK_SECRET"`
+	// SlackTokenFallback accepts unsigned Slack requests with the legacy VERIFICATION_TOKEN, while migrating to signing secrets
+	SlackTokenFallback bool `envconfig:"SLACK_TOKEN_FALLBACK"`
+	// SchedulerSecret is the bearer token the scheduler calls /reminders with
+	SchedulerSecret string `envconfig:"SCHEDULER_SECRET"`
 }
 
 func NewEnvironment(params ...string) Environment {
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/calendar"
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/models"
+)
+
+type calendarHandler struct {
+	env config.Environment
+}
+
+// ServeHTTP serves the bookings calendar feeds at /calendar/reviewer/TOKEN.ics and /calendar/challenge/TOKEN.ics
+func (h calendarHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	kind, token, err := parseCalendarPath(r.URL.Path)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var cal calendar.Calendar
+	switch kind {
+	case "reviewer":
+		cal, err = h.reviewerCalendar(token)
+	case "challenge":
+		cal, err = h.challengeCalendar(token)
+	default:
+		err = errors.New("[ERROR] Unknown calendar kind")
+	}
+	if err != nil {
+		log.Println("[ERROR] Calendar not found - ", kind)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"bookings.ics\"")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		cal.Write(w, time.Now())
+	}
+}
+
+func parseCalendarPath(path string) (string, string, error) {
+	s := strings.Split(strings.TrimPrefix(path, "/calendar/"), "/")
+	if len(s) != 2 || !strings.HasSuffix(s[1], ".ics") {
+		return "", "", errors.New("[ERROR] Invalid calendar path")
+	}
+	return s[0], strings.TrimSuffix(s[1], ".ics"), nil
+}
+
+func (h calendarHandler) reviewerCalendar(token string) (calendar.Calendar, error) {
+	reviewer, err := models.GetReviewerByCalendarToken(h.env, token)
+	if err != nil {
+		return calendar.Calendar{}, err
+	}
+
+	challenges := make([]models.ChallengeSetup, 0, len(reviewer.Challenges))
+	for _, challengeID := range reviewer.ChallengeIDs() {
+		challenge, err := models.GetChallengeSetupByID(h.env, challengeID)
+		if err != nil {
+			log.Println("[ERROR] Invalid challenge for reviewer - ", challengeID, err)
+			continue
+		}
+		challenges = append(challenges, challenge)
+	}
+	return calendar.ReviewerCalendar(reviewer, challenges...), nil
+}
+
+func (h calendarHandler) challengeCalendar(token string) (calendar.Calendar, error) {
+	challenge, err := models.GetChallengeSetupByCalendarToken(h.env, token)
+	if err != nil {
+		return calendar.Calendar{}, err
+	}
+
+	reviewers, err := models.GetAllReviewersForChallenge(h.env, challenge.ID)
+	if err != nil {
+		return calendar.Calendar{}, err
+	}
+	return calendar.ChallengeCalendar(challenge, reviewers), nil
+}
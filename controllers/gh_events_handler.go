@@ -3,9 +3,12 @@ package controllers
 import (
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/github"
 	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/models"
 )
 
 type ghEventsHandler struct {
@@ -32,8 +35,14 @@ func (gh ghEventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	switch event := event.(type) {
 	case *github.PullRequestEvent:
-		if *event.Action == "opened" {
-			// log.Println("PR event")
+		gh.recordSubmission(event)
+	case *github.PullRequestReviewEvent:
+		gh.recordVerdict(event)
+	case *github.StatusEvent:
+		gh.recordBuild(event.GetRepo(), event.GetState())
+	case *github.CheckSuiteEvent:
+		if event.GetAction() == "completed" {
+			gh.recordBuild(event.GetRepo(), event.GetCheckSuite().GetConclusion())
 		}
 	case *github.InstallationEvent:
 		if *event.Action == "created" {
@@ -43,3 +52,63 @@ func (gh ghEventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		// log.Println("Event is - ", reflect.TypeOf(event))
 	}
 }
+
+// recordSubmission tracks the pull requests of the candidates on their challenge repositories, an open pull request
+// is a review pending for the reviewers of the candidate
+func (gh ghEventsHandler) recordSubmission(event *github.PullRequestEvent) {
+	action := event.GetAction()
+	if action != "opened" && action != "reopened" && action != "closed" {
+		return
+	}
+	candidate, err := models.GetCandidateByChallengeURL(gh.env, event.GetRepo().GetCloneURL())
+	if err != nil {
+		// Not a challenge repository
+		return
+	}
+
+	pr := event.GetPullRequest()
+	if action == "closed" {
+		candidate.ClosedAt = pr.GetClosedAt()
+	} else {
+		candidate.SubmissionURL = pr.GetHTMLURL()
+		candidate.SubmittedAt = pr.GetCreatedAt()
+		candidate.ClosedAt = time.Time{}
+	}
+	err = models.UpdateCandidate(gh.env, candidate)
+	if err != nil {
+		log.Println("[ERROR] Could not update candidate in db ", err)
+	}
+}
+
+// recordVerdict keeps the latest review of each reviewer of the candidate's pull request
+func (gh ghEventsHandler) recordVerdict(event *github.PullRequestReviewEvent) {
+	candidate, err := models.GetCandidateByChallengeURL(gh.env, event.GetRepo().GetCloneURL())
+	if err != nil {
+		// Not a challenge repository
+		return
+	}
+
+	review := event.GetReview()
+	if candidate.Verdicts == nil {
+		candidate.Verdicts = make(map[string]string)
+	}
+	candidate.Verdicts[review.GetUser().GetLogin()] = strings.ToLower(review.GetState())
+	err = models.UpdateCandidate(gh.env, candidate)
+	if err != nil {
+		log.Println("[ERROR] Could not update candidate in db ", err)
+	}
+}
+
+// recordBuild keeps the result of the latest CI build of the challenge repository
+func (gh ghEventsHandler) recordBuild(repo *github.Repository, status string) {
+	candidate, err := models.GetCandidateByChallengeURL(gh.env, repo.GetCloneURL())
+	if err != nil || status == "" {
+		return
+	}
+
+	candidate.CIStatus = status
+	err = models.UpdateCandidate(gh.env, candidate)
+	if err != nil {
+		log.Println("[ERROR] Could not update candidate in db ", err)
+	}
+}
@@ -0,0 +1,285 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/calendar"
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/scheduling"
+	"github.com/keremk/challenge-bot/slackops"
+)
+
+// errPairingPicked is returned when the candidate already picked a pairing session, e.g. in another tab
+var errPairingPicked = errors.New("[ERROR] Candidate already picked a pairing session")
+
+type pairingHandler struct {
+	env config.Environment
+}
+
+type pairingSlot struct {
+	Key   string
+	Label string
+}
+
+type pairingPage struct {
+	Name      string
+	Challenge string
+	Message   string
+	Booked    string
+	InviteURL string
+	Slots     []pairingSlot
+}
+
+var pairingTemplate = template.Must(template.New("pairing").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Live pairing session</title>
+</head>
+<body>
+<h1>Live pairing session{{if .Challenge}} - {{.Challenge}}{{end}}</h1>
+{{if .Message}}<p>{{.Message}}</p>{{end}}
+{{if .Booked}}
+<p>Thanks {{.Name}}, your live pairing session is booked for <strong>{{.Booked}}</strong>. The reviewers will be in touch with the details.</p>
+<p><a href="{{.InviteURL}}">Add it to your calendar</a></p>
+{{else if .Slots}}
+<p>Hi {{.Name}}, please pick a time for the live pairing session with your reviewers.</p>
+<form method="post">
+{{range .Slots}}<p><label><input type="radio" name="slot" value="{{.Key}}" required> {{.Label}}</label></p>
+{{end}}<p><button type="submit">Book</button></p>
+</form>
+{{else if .Name}}
+<p>Hi {{.Name}}, there are no open times in the coming weeks. We will get in touch with you to find a time.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// ServeHTTP serves the candidate's page to pick a live pairing session at /pairing/CANDIDATEID, and the calendar
+// invite of the picked session at /pairing/CANDIDATEID/invite.ics. The links are signed with LINK_SECRET.
+func (h pairingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	candidateID, isInvite, err := parsePairingPath(r.URL.Path)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	query := r.URL.Query()
+	err = models.VerifyLink(h.env.LinkSecret, candidateID, query.Get("expires"), query.Get("sig"), time.Now())
+	if err != nil {
+		if _, ok := err.(models.LinkExpiredError); ok {
+			writePairingPage(w, http.StatusGone, pairingPage{Message: "This link has expired, please ask us for a new one."})
+			return
+		}
+		log.Println("[ERROR] Invalid pairing link - ", err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	candidate, err := models.GetCandidate(h.env, candidateID)
+	if err != nil {
+		log.Println("[ERROR] Candidate not found - ", candidateID, err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	challenge, err := models.GetChallengeSetupByID(h.env, candidate.ChallengeID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find the challenge of the candidate - ", candidate.ChallengeID, err)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	switch {
+	case isInvite && r.Method == http.MethodGet:
+		h.serveInvite(w, candidate, challenge)
+	case r.Method == http.MethodGet:
+		h.showSlots(w, r, candidate, challenge, "")
+	case !isInvite && r.Method == http.MethodPost:
+		h.bookSlot(w, r, candidateID, challenge)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func parsePairingPath(path string) (string, bool, error) {
+	s := strings.Split(strings.TrimPrefix(path, "/pairing/"), "/")
+	switch {
+	case len(s) == 1 && s[0] != "":
+		return s[0], false, nil
+	case len(s) == 2 && s[0] != "" && s[1] == "invite.ics":
+		return s[0], true, nil
+	default:
+		return "", false, errors.New("[ERROR] Invalid pairing path")
+	}
+}
+
+func (h pairingHandler) showSlots(w http.ResponseWriter, r *http.Request, candidate models.Candidate, challenge models.ChallengeSetup, message string) {
+	page := pairingPage{
+		Name:      candidate.Name,
+		Challenge: challenge.Name,
+		Message:   message,
+	}
+	if candidate.HasPairing() {
+		page.Booked = pairingSlotLabel(challenge, candidate.Pairing)
+		page.InviteURL = fmt.Sprintf("/pairing/%s/invite.ics?%s", candidate.ID, r.URL.RawQuery)
+		writePairingPage(w, http.StatusOK, page)
+		return
+	}
+
+	reviewers, err := h.reviewers(candidate)
+	if err != nil {
+		writePairingPage(w, http.StatusInternalServerError, pairingPage{Message: "Sorry, something went wrong. Please try again later."})
+		return
+	}
+	for _, occurrence := range scheduling.PairingSlots(reviewers, challenge, time.Now(), scheduling.PairingWeeks) {
+		page.Slots = append(page.Slots, pairingSlot{
+			Key:   occurrence.Key(),
+			Label: pairingSlotLabel(challenge, occurrence),
+		})
+	}
+	writePairingPage(w, http.StatusOK, page)
+}
+
+func (h pairingHandler) bookSlot(w http.ResponseWriter, r *http.Request, candidateID string, challenge models.ChallengeSetup) {
+	occurrence, err := models.ParseSlotOccurrence(r.FormValue("slot"))
+	if err != nil {
+		writePairingPage(w, http.StatusBadRequest, pairingPage{Message: "Please pick one of the times."})
+		return
+	}
+
+	// The candidate picks the session before the reviewers are booked, so two tabs cannot both book one
+	candidate, err := models.ChangeCandidate(h.env, candidateID, func(candidate models.Candidate) (models.Candidate, error) {
+		if candidate.HasPairing() {
+			return candidate, errPairingPicked
+		}
+		candidate.Pairing = occurrence
+		return candidate, nil
+	})
+	if err != nil {
+		http.Redirect(w, r, r.URL.RequestURI(), http.StatusSeeOther)
+		return
+	}
+	reviewers, err := h.reviewers(candidate)
+	if err != nil {
+		h.releasePairing(candidate)
+		writePairingPage(w, http.StatusInternalServerError, pairingPage{Message: "Sorry, something went wrong. Please try again later."})
+		return
+	}
+	if !containsOccurrence(scheduling.PairingSlots(reviewers, challenge, time.Now(), scheduling.PairingWeeks), occurrence) {
+		candidate = h.releasePairing(candidate)
+		h.showSlots(w, r, candidate, challenge, "Sorry, this time is not available anymore. Please pick another one.")
+		return
+	}
+
+	// Each reviewer is booked only if the slot is still free for them
+	booked, err := scheduling.BookPairing(h.env, reviewers, occurrence, map[string]string{
+		"candidate_name": candidate.Name,
+		"challenge_id":   candidate.ChallengeID,
+		"challenge_url":  candidate.ChallengeURL,
+		"booked_by":      candidate.SentBy,
+		"notes":          "Picked by the candidate",
+	})
+	if err != nil {
+		candidate = h.releasePairing(candidate)
+		h.showSlots(w, r, candidate, challenge, "Sorry, this time cannot be booked anymore. Please pick another one.")
+		return
+	}
+
+	go slackops.NotifyPairing(h.env, challenge, candidate, booked)
+
+	http.Redirect(w, r, r.URL.RequestURI(), http.StatusSeeOther)
+}
+
+// releasePairing lets the candidate pick another session, when the one they picked cannot be booked
+func (h pairingHandler) releasePairing(candidate models.Candidate) models.Candidate {
+	occurrence := candidate.Pairing
+	released, err := models.ChangeCandidate(h.env, candidate.ID, func(candidate models.Candidate) (models.Candidate, error) {
+		if candidate.Pairing == occurrence {
+			candidate.Pairing = models.SlotOccurrence{}
+		}
+		return candidate, nil
+	})
+	if err != nil {
+		log.Println("[ERROR] Could not update candidate in db ", err)
+		candidate.Pairing = models.SlotOccurrence{}
+		return candidate
+	}
+	return released
+}
+
+func (h pairingHandler) serveInvite(w http.ResponseWriter, candidate models.Candidate, challenge models.ChallengeSetup) {
+	reviewers, err := h.reviewers(candidate)
+	if err != nil {
+		log.Println("[ERROR] Cannot load the reviewers of the candidate - ", err)
+	}
+	cal, err := calendar.PairingCalendar(candidate, challenge, reviewers)
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename=\"pairing.ics\"")
+	w.WriteHeader(http.StatusOK)
+	cal.Write(w, time.Now())
+}
+
+// reviewers loads the reviewers assigned to the candidate, with their capacity for the challenge
+func (h pairingHandler) reviewers(candidate models.Candidate) ([]models.Reviewer, error) {
+	reviewers := make([]models.Reviewer, 0, len(candidate.ReviewerIDs))
+	for _, slackID := range candidate.ReviewerIDs {
+		reviewer, err := models.GetReviewerBySlackID(h.env, slackID)
+		if err != nil {
+			// Removed reviewers are skipped, the others can still pair with the candidate
+			log.Println("[ERROR] Reviewer of the candidate is not registered - ", slackID, err)
+			continue
+		}
+		reviewer, err = reviewer.ForChallenge(candidate.ChallengeID)
+		if err != nil {
+			log.Println("[ERROR] Reviewer does not review the challenge of the candidate - ", slackID, err)
+			return nil, err
+		}
+		reviewers = append(reviewers, reviewer)
+	}
+	return reviewers, nil
+}
+
+func pairingSlotLabel(challenge models.ChallengeSetup, occurrence models.SlotOccurrence) string {
+	slot := challenge.Slots[occurrence.SlotID]
+	weekStart, err := scheduling.WeekOfOccurrence(occurrence)
+	if slot == nil || err != nil {
+		return occurrence.Date
+	}
+	start, end, err := slot.Interval(weekStart)
+	if err != nil {
+		return occurrence.Date
+	}
+	return fmt.Sprintf("%s - %s (%s)", start.Format("Monday, 2 January 2006, 15:04"), end.Format("15:04"), start.Location())
+}
+
+func containsOccurrence(occurrences []models.SlotOccurrence, occurrence models.SlotOccurrence) bool {
+	for _, o := range occurrences {
+		if o == occurrence {
+			return true
+		}
+	}
+	return false
+}
+
+func writePairingPage(w http.ResponseWriter, status int, page pairingPage) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	err := pairingTemplate.Execute(w, page)
+	if err != nil {
+		log.Println("[ERROR] Cannot render the pairing page - ", err)
+	}
+}
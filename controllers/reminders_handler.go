@@ -0,0 +1,46 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/slackops"
+)
+
+type remindersHandler struct {
+	env config.Environment
+}
+
+// ServeHTTP sends the due reminders and digests when Cloud Scheduler calls /reminders, with the scheduler secret as
+// the bearer token
+func (h remindersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if !verifySchedulerToken(h.env.SchedulerSecret, r.Header.Get("Authorization")) {
+		log.Println("[ERROR] Reminders request is not from the scheduler")
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// The reminders are sent before responding, the scheduler retries if they cannot be
+	err := slackops.SendReminders(h.env, time.Now())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func verifySchedulerToken(secret, authorization string) bool {
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	if secret == "" || token == authorization {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifySchedulerToken(t *testing.T) {
+	tests := []struct {
+		name          string
+		secret        string
+		authorization string
+		want          bool
+	}{
+		{"valid token", "s3cret", "Bearer s3cret", true},
+		{"wrong token", "s3cret", "Bearer other", false},
+		{"not a bearer token", "s3cret", "s3cret", false},
+		{"missing header", "s3cret", "", false},
+		{"secret not set", "", "Bearer ", false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, verifySchedulerToken(test.secret, test.authorization), test.name)
+	}
+}
+
+func TestRemindersHandlerRejects(t *testing.T) {
+	handler := remindersHandler{env: config.Environment{SchedulerSecret: "s3cret"}}
+
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		want          int
+	}{
+		{"not a post", http.MethodGet, "Bearer s3cret", http.StatusMethodNotAllowed},
+		{"wrong token", http.MethodPost, "Bearer other", http.StatusUnauthorized},
+		{"no token", http.MethodPost, "", http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(test.method, "/reminders", nil)
+		r.Header.Set("Authorization", test.authorization)
+		handler.ServeHTTP(w, r)
+		assert.Equal(t, test.want, w.Code, test.name)
+	}
+}
@@ -14,6 +14,9 @@ func SetupRoutes() {
 
 	setupSlackListeners(env)
 	setupGithubListeners(env)
+	setupCalendarListeners(env)
+	setupPairingListeners(env)
+	setupReminderListeners(env)
 
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		log.Println("[INFO] Health ok")
@@ -33,15 +36,18 @@ func SetupRoutes() {
 }
 
 func setupSlackListeners(env config.Environment) {
-	http.Handle("/commands", &commandHandler{
+	http.Handle("/commands", verifySlackRequests(env, &commandHandler{
 		env: env,
-	})
-	http.Handle("/requests", &requestsHandler{
+	}))
+	http.Handle("/requests", verifySlackRequests(env, &requestsHandler{
 		env: env,
-	})
-	http.Handle("/options", &optionsHandler{
+	}))
+	http.Handle("/options", verifySlackRequests(env, &optionsHandler{
 		env: env,
-	})
+	}))
+	http.Handle("/events", verifySlackRequests(env, &eventsHandler{
+		env: env,
+	}))
 
 	http.Handle("/auth/slack/redirect", &authHandler{
 		env: env,
@@ -62,3 +68,21 @@ func setupGithubListeners(env config.Environment) {
 		env: env,
 	})
 }
+
+func setupCalendarListeners(env config.Environment) {
+	http.Handle("/calendar/", &calendarHandler{
+		env: env,
+	})
+}
+
+func setupPairingListeners(env config.Environment) {
+	http.Handle("/pairing/", &pairingHandler{
+		env: env,
+	})
+}
+
+func setupReminderListeners(env config.Environment) {
+	http.Handle("/reminders", &remindersHandler{
+		env: env,
+	})
+}
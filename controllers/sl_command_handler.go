@@ -14,14 +14,8 @@ type commandHandler struct {
 func (h commandHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	err := slackops.ExecuteCommand(h.env, r)
 	if err != nil {
-		switch err.(type) {
-		case slackops.ValidationError:
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		default:
-			w.WriteHeader(http.StatusInternalServerError)
-			return
-		}
+		w.WriteHeader(http.StatusInternalServerError)
+		return
 	}
 	w.WriteHeader(http.StatusAccepted)
 }
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/slackops"
+)
+
+// eventsHandler receives the events of the Events API, the requests are verified by verifySlackRequests
+type eventsHandler struct {
+	env config.Environment
+}
+
+func (h eventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxSlackBodySize))
+	if err != nil {
+		log.Println("[ERROR] Unable to read the event ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Slack retries the events it did not get a response to in time
+	retryNum, _ := strconv.Atoi(r.Header.Get("X-Slack-Retry-Num"))
+	if retryNum > 0 {
+		log.Printf("[INFO] Event is retried %d time(s) - %s", retryNum, r.Header.Get("X-Slack-Retry-Reason"))
+	}
+
+	respJSON, err := slackops.HandleEvent(h.env, body, retryNum)
+	if err != nil {
+		log.Println("[ERROR] Unexpected event ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if respJSON != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(respJSON)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
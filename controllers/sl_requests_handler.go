@@ -13,18 +13,17 @@ type requestsHandler struct {
 }
 
 func (h requestsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	err := slackops.HandleRequests(h.env, r.Body)
+	respJSON, err := slackops.HandleRequests(h.env, r.Body)
 
 	if err != nil {
-		switch err.(type) {
-		case slackops.ValidationError:
-			w.WriteHeader(http.StatusUnauthorized)
-			return
-		default:
-			log.Println("[ERROR] Unexpected request ", err)
-			w.WriteHeader(http.StatusBadRequest)
-			return
-		}
+		log.Println("[ERROR] Unexpected request ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if respJSON != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(respJSON)
+		return
 	}
 	w.WriteHeader(http.StatusAccepted)
 }
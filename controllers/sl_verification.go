@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+)
+
+// Slack requests signed longer ago than this are rejected, so a captured request cannot be replayed
+const slackReplayWindow = 5 * time.Minute
+
+// Largest Slack request body that is read
+const maxSlackBodySize = 1024 * 1024
+
+var errMissingSignature = errors.New("[ERROR] Slack request is not signed")
+
+// slackVerifier checks that the requests come from Slack, before passing them to the Slack handlers. Requests are
+// signed with the signing secret of the app. Unsigned requests with the legacy verification token are accepted
+// if SLACK_TOKEN_FALLBACK is set.
+type slackVerifier struct {
+	env  config.Environment
+	next http.Handler
+}
+
+func verifySlackRequests(env config.Environment, next http.Handler) http.Handler {
+	return &slackVerifier{
+		env:  env,
+		next: next,
+	}
+}
+
+func (v slackVerifier) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxSlackBodySize))
+	r.Body.Close()
+	if err != nil {
+		log.Println("[ERROR] Unable to read the Slack request ", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	// The handlers read the body again
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	err = verifySlackSignature(v.env.SlackSigningSecret, r.Header, body, time.Now())
+	if err == errMissingSignature && v.env.SlackTokenFallback {
+		err = verifySlackToken(v.env.VerificationToken, body)
+	}
+	if err != nil {
+		log.Println("[ERROR] Unable to verify the Slack request - ", err)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	v.next.ServeHTTP(w, r)
+}
+
+// verifySlackSignature checks the X-Slack-Signature of the request, see https://api.slack.com/docs/verifying-requests-from-slack
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) error {
+	signature := header.Get("X-Slack-Signature")
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	if signature == "" || timestamp == "" {
+		return errMissingSignature
+	}
+	if secret == "" {
+		return errors.New("[ERROR] SLACK_SIGNING_SECRET is not set")
+	}
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("[ERROR] Invalid Slack request timestamp - %s", timestamp)
+	}
+	age := now.Sub(time.Unix(signedAt, 0))
+	if age > slackReplayWindow || age < -slackReplayWindow {
+		return fmt.Errorf("[ERROR] Slack request timestamp is out of the replay window - %s", timestamp)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("[ERROR] Invalid Slack request signature")
+	}
+	return nil
+}
+
+// verifySlackToken checks the legacy verification token, which is a form value of slash commands, part of the
+// JSON payload of interactions and of the JSON body of events
+func verifySlackToken(verificationToken string, body []byte) error {
+	if verificationToken == "" {
+		return errors.New("[ERROR] VERIFICATION_TOKEN is not set")
+	}
+
+	var token string
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		var err error
+		token, err = jsonToken(body)
+		if err != nil {
+			return err
+		}
+	} else {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return err
+		}
+		token = values.Get("token")
+		if payload := values.Get("payload"); payload != "" {
+			token, err = jsonToken([]byte(payload))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if !hmac.Equal([]byte(token), []byte(verificationToken)) {
+		return errors.New("[ERROR] Invalid Slack verification token")
+	}
+	return nil
+}
+
+func jsonToken(body []byte) (string, error) {
+	var p struct {
+		Token string `json:"token"`
+	}
+	err := json.Unmarshal(body, &p)
+	return p.Token, err
+}
@@ -0,0 +1,103 @@
+package controllers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+
+func slackHeader(secret string, body string, signedAt time.Time) http.Header {
+	timestamp := strconv.FormatInt(signedAt.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+
+	header := make(http.Header)
+	header.Set("X-Slack-Request-Timestamp", timestamp)
+	header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return header
+}
+
+func TestVerifySlackSignature(t *testing.T) {
+	now := time.Date(2020, 3, 2, 10, 0, 0, 0, time.UTC)
+	body := "token=xyz&command=%2Fchallenge&text=list"
+
+	tests := []struct {
+		name    string
+		secret  string
+		header  http.Header
+		body    string
+		wantErr bool
+	}{
+		{"valid signature", testSigningSecret, slackHeader(testSigningSecret, body, now.Add(-time.Minute)), body, false},
+		{"tampered body", testSigningSecret, slackHeader(testSigningSecret, body, now), body + "&user_id=U1", true},
+		{"other secret", testSigningSecret, slackHeader("other", body, now), body, true},
+		{"stale timestamp", testSigningSecret, slackHeader(testSigningSecret, body, now.Add(-6*time.Minute)), body, true},
+		{"future timestamp", testSigningSecret, slackHeader(testSigningSecret, body, now.Add(6*time.Minute)), body, true},
+		{"missing header", testSigningSecret, http.Header{}, body, true},
+		{"missing secret", "", slackHeader(testSigningSecret, body, now), body, true},
+	}
+	for _, test := range tests {
+		err := verifySlackSignature(test.secret, test.header, []byte(test.body), now)
+		assert.Equal(t, test.wantErr, err != nil, test.name)
+	}
+
+	err := verifySlackSignature(testSigningSecret, http.Header{}, []byte(body), now)
+	assert.Equal(t, errMissingSignature, err)
+}
+
+func TestVerifySlackToken(t *testing.T) {
+	payload := url.Values{"payload": {`{"type":"block_actions","token":"xyz"}`}}.Encode()
+
+	tests := []struct {
+		name    string
+		token   string
+		body    string
+		wantErr bool
+	}{
+		{"slash command", "xyz", "token=xyz&command=%2Fchallenge", false},
+		{"interaction payload", "xyz", payload, false},
+		{"event", "xyz", `{"token":"xyz","type":"event_callback"}`, false},
+		{"wrong token", "xyz", "token=abc&command=%2Fchallenge", true},
+		{"token not set", "", "token=&command=%2Fchallenge", true},
+	}
+	for _, test := range tests {
+		err := verifySlackToken(test.token, []byte(test.body))
+		assert.Equal(t, test.wantErr, err != nil, test.name)
+	}
+}
+
+func TestSlackVerifierTokenFallback(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	body := "token=xyz&command=%2Fchallenge"
+
+	tests := []struct {
+		name     string
+		fallback bool
+		want     int
+	}{
+		{"fallback on", true, http.StatusOK},
+		{"fallback off", false, http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		env := config.Environment{SlackSigningSecret: testSigningSecret, VerificationToken: "xyz", SlackTokenFallback: test.fallback}
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/slack/command", strings.NewReader(body))
+		verifySlackRequests(env, next).ServeHTTP(w, r)
+		assert.Equal(t, test.want, w.Code, test.name)
+	}
+}
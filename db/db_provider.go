@@ -13,6 +13,17 @@ const SlackTeamsCollection = "slackteams"
 const SettingsCollection = "challengesettings"
 const GithubAccountsCollection = "githubaccounts"
 const ReviewersCollection = "reviewers"
+const TagsCollection = "tags"
+const NeedsCollection = "needs"
+const CandidatesCollection = "candidates"
+const EventsCollection = "slackevents"
+const RemindersCollection = "sentreminders"
+
+// ErrExists is returned when creating a document with a key that is already used
+var ErrExists = errors.New("[ERROR] Document already exists")
+
+// ErrConflict is returned when a document keeps changing while a transaction changes it
+var ErrConflict = errors.New("[ERROR] Document changed during the transaction")
 
 type CrudOps interface {
 	Update(key string, obj interface{}) error
@@ -21,6 +32,12 @@ type CrudOps interface {
 	FindFirst(key, value string, obj interface{}) error
 	FindAll(itemType reflect.Type) (interface{}, error)
 	FindAllWithKeyValue(itemType reflect.Type, key, value string) (interface{}, error)
+	Delete(key string) error
+	// Create adds the document, or returns ErrExists if there is one with the key
+	Create(key string, obj interface{}) error
+	// Transact reads the document into obj, and writes obj back once change changed it, unless the document was
+	// written in between. change can run more than once, nothing is written if it returns an error.
+	Transact(key string, obj interface{}, change func() error) error
 }
 
 func NewStore(env config.Environment, collection string) (CrudOps, error) {
@@ -46,3 +63,9 @@ func NewStore(env config.Environment, collection string) (CrudOps, error) {
 		return nil, errors.New(errMsg)
 	}
 }
+
+// resetObject zeroes the object a document is read into, so each run of a transaction starts from the stored document
+func resetObject(obj interface{}) {
+	v := reflect.ValueOf(obj).Elem()
+	v.Set(reflect.Zero(v.Type()))
+}
@@ -31,6 +31,57 @@ func (s FirestoreDb) Update(key string, obj interface{}) error {
 	return err
 }
 
+func (s FirestoreDb) Create(key string, obj interface{}) error {
+	client, ctx, err := s.getClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	doc := client.Collection(s.collection).Doc(key)
+	err = client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.GetAll([]*firestore.DocumentRef{doc})
+		if err != nil {
+			return err
+		}
+		if docs[0].Exists() {
+			return ErrExists
+		}
+		return tx.Create(doc, obj)
+	})
+	if err != nil && err != ErrExists {
+		log.Printf("[ERROR] cannot create data in Firestore for key %s - %s", key, err)
+	}
+	return err
+}
+
+func (s FirestoreDb) Transact(key string, obj interface{}, change func() error) error {
+	client, ctx, err := s.getClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	doc := client.Collection(s.collection).Doc(key)
+	return client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		data, err := tx.Get(doc)
+		if err != nil {
+			log.Println("[ERROR] cannot find object with id=", key, err)
+			return err
+		}
+		resetObject(obj)
+		err = data.DataTo(obj)
+		if err != nil {
+			return err
+		}
+		err = change()
+		if err != nil {
+			return err
+		}
+		return tx.Set(doc, obj)
+	})
+}
+
 func (s FirestoreDb) Merge(key string, values map[string]interface{}) error {
 	client, ctx, err := s.getClient()
 	if err != nil {
@@ -47,6 +98,20 @@ func (s FirestoreDb) Merge(key string, values map[string]interface{}) error {
 	return err
 }
 
+func (s FirestoreDb) Delete(key string) error {
+	client, ctx, err := s.getClient()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	_, err = client.Collection(s.collection).Doc(key).Delete(ctx)
+	if err != nil {
+		log.Printf("[ERROR] cannot delete data from Firestore for key %s - %s", key, err)
+	}
+	return err
+}
+
 func (s FirestoreDb) FindByID(id string, obj interface{}) error {
 	client, ctx, err := s.getClient()
 	if err != nil {
@@ -14,6 +14,9 @@ import (
 
 const Mongo = "MongoDB"
 
+// Transactions replace the document only if it did not change since it was read, and read it again otherwise
+const mongoTransactAttempts = 5
+
 type MongoDB struct {
 	env        config.Environment
 	collection string
@@ -37,6 +40,69 @@ func (s MongoDB) Update(key string, obj interface{}) error {
 	return nil
 }
 
+func (s MongoDB) Create(key string, obj interface{}) error {
+	client, ctx, err := s.getClient()
+	if err != nil {
+		return err
+	}
+
+	col := client.Database(s.database).Collection(s.collection)
+
+	// Only inserts if there is no document with the key
+	filter := bson.D{{Key: "ID", Value: key}}
+	update := bson.D{{Key: "$setOnInsert", Value: obj}}
+	result, err := col.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		log.Printf("[ERROR] Unable to insert to MongoDB - %s", err)
+		return err
+	}
+	if result.UpsertedCount == 0 {
+		return ErrExists
+	}
+	return nil
+}
+
+func (s MongoDB) Transact(key string, obj interface{}, change func() error) error {
+	client, ctx, err := s.getClient()
+	if err != nil {
+		return err
+	}
+
+	col := client.Database(s.database).Collection(s.collection)
+
+	for i := 0; i < mongoTransactAttempts; i++ {
+		var current bson.D
+		err = col.FindOne(ctx, bson.D{{Key: "ID", Value: key}}).Decode(&current)
+		if err != nil {
+			log.Printf("[ERROR] Cannot find the document with ID %s in collection %s - %s", key, s.collection, err)
+			return err
+		}
+		data, err := bson.Marshal(current)
+		if err != nil {
+			return err
+		}
+		resetObject(obj)
+		err = bson.Unmarshal(data, obj)
+		if err != nil {
+			return err
+		}
+
+		err = change()
+		if err != nil {
+			return err
+		}
+		result, err := col.ReplaceOne(ctx, current, obj)
+		if err != nil {
+			log.Printf("[ERROR] Unable to update document in MongoDB - %s", err)
+			return err
+		}
+		if result.MatchedCount > 0 {
+			return nil
+		}
+	}
+	return ErrConflict
+}
+
 func (s MongoDB) Merge(key string, values map[string]interface{}) error {
 	client, ctx, err := s.getClient()
 	if err != nil {
@@ -62,6 +128,22 @@ func (s MongoDB) Merge(key string, values map[string]interface{}) error {
 	return nil
 }
 
+func (s MongoDB) Delete(key string) error {
+	client, ctx, err := s.getClient()
+	if err != nil {
+		return err
+	}
+
+	col := client.Database(s.database).Collection(s.collection)
+
+	// Updates insert a new document, so all the documents with the key are deleted
+	_, err = col.DeleteMany(ctx, bson.D{{Key: "ID", Value: key}})
+	if err != nil {
+		log.Printf("[ERROR] Unable to delete document in MongoDB - %s", err)
+	}
+	return err
+}
+
 func (s MongoDB) FindByID(id string, obj interface{}) error {
 
 	return s.FindFirst("ID", id, obj)
@@ -168,3 +168,21 @@ func TestUpdatingDocInMongoDB(t *testing.T) {
 	assert.Nil(t, err, "Could not find the updated document")
 	assert.Equal(t, testEntry{ID: "100", WorkingTitle: "owner", Category: "Cat1"}, obj)
 }
+
+func TestDeletingDocInMongoDB(t *testing.T) {
+	db := getTestDB()
+
+	err := addSearchableDocs(db)
+	assert.Nil(t, err, "Could not add the fixtures to test database")
+
+	err = db.Delete("100")
+	assert.Nil(t, err, "Could not delete the document")
+
+	var all []testEntry
+	result, err := db.FindAll(reflect.TypeOf(all))
+	all, ok := result.([]testEntry)
+
+	assert.Equal(t, true, ok)
+	assert.Nil(t, err, "Could not find what I am looking for")
+	assert.Equal(t, []testEntry{{ID: "101", WorkingTitle: "Bar", Category: "Cat1"}, {ID: "102", WorkingTitle: "Baz", Category: "Cat2"}}, all)
+}
@@ -18,6 +18,16 @@ func (s PostgreSQLDB) Update(key string, obj interface{}) error {
 	return nil
 }
 
+func (s PostgreSQLDB) Create(key string, obj interface{}) error {
+
+	return nil
+}
+
+func (s PostgreSQLDB) Transact(key string, obj interface{}, change func() error) error {
+
+	return nil
+}
+
 func (s PostgreSQLDB) Merge(key string, values map[string]interface{}) error {
 
 	return nil
@@ -42,3 +52,8 @@ func (s PostgreSQLDB) FindAllWithKeyValue(itemType reflect.Type, key, value stri
 
 	return nil, nil
 }
+
+func (s PostgreSQLDB) Delete(key string) error {
+
+	return nil
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/util"
+)
+
+// AvailabilityRule makes a reviewer available for a slot on a recurring basis, modelled after
+// the weekly subset of RFC 5545 RRULEs, e.g. every other week, only in March.
+type AvailabilityRule struct {
+	ID     string `bson:"ID"`
+	SlotID SlotID `bson:"SlotID"`
+	// Interval is the number of weeks between occurrences, counted from the week of Start
+	Interval int    `bson:"Interval"`
+	Start    string `bson:"Start"`
+	Until    string `bson:"Until"`
+	ByMonth  []int  `bson:"ByMonth"`
+}
+
+// DateRange is an inclusive range of dates a reviewer is out of office
+type DateRange struct {
+	ID     string `bson:"ID"`
+	From   string `bson:"From"`
+	To     string `bson:"To"`
+	Reason string `bson:"Reason"`
+}
+
+func NewAvailabilityRule(input map[string]string) (AvailabilityRule, error) {
+	rule := AvailabilityRule{
+		ID:     util.RandomString(8),
+		SlotID: input["slot_id"],
+	}
+	if rule.SlotID == "" {
+		return rule, InputError{Input: "slot_id", Message: "Pick the slot to be available in."}
+	}
+
+	interval, err := strconv.Atoi(input["interval"])
+	if err != nil || interval < 1 {
+		interval = 1
+	}
+	rule.Interval = interval
+
+	start, err := parseDate(input["start_date"])
+	if err != nil {
+		return rule, InputError{Input: "start_date", Message: "Pick the week the rule starts."}
+	}
+	rule.Start = start.Format(DateFormat)
+
+	if until := strings.TrimSpace(input["until"]); until != "" {
+		untilDate, err := parseDate(until)
+		if err != nil {
+			return rule, InputError{Input: "until", Message: "Use YYYY-MM-DD, e.g. 2020-06-30."}
+		}
+		if untilDate.Before(start) {
+			return rule, InputError{Input: "until", Message: "The rule has to end after it starts."}
+		}
+		rule.Until = untilDate.Format(DateFormat)
+	}
+
+	months, err := parseMonths(input["months"])
+	if err != nil {
+		return rule, InputError{Input: "months", Message: "Use month names or numbers, e.g. March, 4."}
+	}
+	rule.ByMonth = months
+
+	return rule, nil
+}
+
+// String renders the rule in RRULE notation, e.g. "FREQ=WEEKLY;INTERVAL=2;BYMONTH=3"
+func (r AvailabilityRule) String() string {
+	parts := []string{"FREQ=WEEKLY"}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, 0, len(r.ByMonth))
+		for _, month := range r.ByMonth {
+			months = append(months, strconv.Itoa(month))
+		}
+		parts = append(parts, fmt.Sprintf("BYMONTH=%s", strings.Join(months, ",")))
+	}
+	if r.Until != "" {
+		until, err := time.Parse(DateFormat, r.Until)
+		if err == nil {
+			parts = append(parts, fmt.Sprintf("UNTIL=%s", until.Format("20060102")))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+func NewDateRange(input map[string]string) (DateRange, error) {
+	from, err := parseDate(input["from_date"])
+	if err != nil {
+		return DateRange{}, InputError{Input: "from_date", Message: "Use YYYY-MM-DD, e.g. 2020-06-01."}
+	}
+
+	to := from
+	if strings.TrimSpace(input["to_date"]) != "" {
+		to, err = parseDate(input["to_date"])
+		if err != nil {
+			return DateRange{}, InputError{Input: "to_date", Message: "Use YYYY-MM-DD, e.g. 2020-06-05."}
+		}
+	}
+	if to.Before(from) {
+		return DateRange{}, InputError{Input: "to_date", Message: "The last day away has to be on or after the first one."}
+	}
+
+	return DateRange{
+		ID:     util.RandomString(8),
+		From:   from.Format(DateFormat),
+		To:     to.Format(DateFormat),
+		Reason: input["reason"],
+	}, nil
+}
+
+// Contains checks if the date (in the DateFormat) is in the range
+func (r DateRange) Contains(date string) bool {
+	return date >= r.From && date <= r.To
+}
+
+func parseDate(input string) (time.Time, error) {
+	date, err := time.Parse(DateFormat, strings.TrimSpace(input))
+	if err != nil {
+		return date, fmt.Errorf("[ERROR] Invalid date, expected YYYY-MM-DD - %s", input)
+	}
+	return date, nil
+}
+
+// parseMonths accepts a comma separated list of month numbers or names, e.g. "3, April"
+func parseMonths(input string) ([]int, error) {
+	months := make([]int, 0, 12)
+	for _, field := range strings.Split(input, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		month, err := strconv.Atoi(field)
+		if err != nil {
+			month = 0
+			for m := time.January; m <= time.December; m++ {
+				if strings.HasPrefix(strings.ToLower(m.String()), strings.ToLower(field)) && len(field) >= 3 {
+					month = int(m)
+				}
+			}
+		}
+		if month < 1 || month > 12 {
+			return nil, fmt.Errorf("[ERROR] Invalid month - %s", field)
+		}
+		months = append(months, month)
+	}
+	return months, nil
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInvalidRules(t *testing.T) {
+	valid := map[string]string{"slot_id": "MondayMorning", "start_date": "2020-03-02"}
+	rule, err := NewAvailabilityRule(valid)
+	assert.Nil(t, err)
+	assert.Equal(t, "2020-03-02", rule.Start)
+
+	tests := []struct {
+		name  string
+		input map[string]string
+		want  string
+	}{
+		{"no slot", map[string]string{"start_date": "2020-03-02"}, "slot_id"},
+		{"invalid start", map[string]string{"slot_id": "MondayMorning", "start_date": "March"}, "start_date"},
+		{"invalid end", map[string]string{"slot_id": "MondayMorning", "start_date": "2020-03-02", "until": "30.06.2020"}, "until"},
+		{"ends before it starts", map[string]string{"slot_id": "MondayMorning", "start_date": "2020-03-02", "until": "2020-02-28"}, "until"},
+		{"invalid month", map[string]string{"slot_id": "MondayMorning", "start_date": "2020-03-02", "months": "March, Smarch"}, "months"},
+	}
+	for _, test := range tests {
+		_, err := NewAvailabilityRule(test.input)
+		inputErr, ok := err.(InputError)
+		assert.True(t, ok, test.name)
+		assert.Equal(t, test.want, inputErr.Input, test.name)
+	}
+}
+
+func TestInvalidDateRanges(t *testing.T) {
+	away, err := NewDateRange(map[string]string{"from_date": "2020-03-02"})
+	assert.Nil(t, err)
+	assert.Equal(t, "2020-03-02", away.To)
+
+	tests := []struct {
+		name  string
+		input map[string]string
+		want  string
+	}{
+		{"invalid start", map[string]string{"from_date": "tomorrow"}, "from_date"},
+		{"invalid end", map[string]string{"from_date": "2020-03-02", "to_date": "friday"}, "to_date"},
+		{"ends before it starts", map[string]string{"from_date": "2020-03-02", "to_date": "2020-03-01"}, "to_date"},
+	}
+	for _, test := range tests {
+		_, err := NewDateRange(test.input)
+		inputErr, ok := err.(InputError)
+		assert.True(t, ok, test.name)
+		assert.Equal(t, test.want, inputErr.Input, test.name)
+	}
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/util"
+)
+
+// DateFormat is the calendar date format used for slot occurrences
+const DateFormat = "2006-01-02"
+
+// SlotOccurrence is a challenge slot on a concrete calendar date. The date is in the slot's own time zone.
+type SlotOccurrence struct {
+	Date   string `bson:"Date"`
+	SlotID SlotID `bson:"SlotID"`
+}
+
+func NewSlotOccurrence(date time.Time, slotID SlotID) SlotOccurrence {
+	return SlotOccurrence{
+		Date:   date.Format(DateFormat),
+		SlotID: slotID,
+	}
+}
+
+// OccurrenceInWeek returns the occurrence of the slot in the week starting on the Monday weekStart
+func (s Slot) OccurrenceInWeek(weekStart time.Time) (SlotOccurrence, error) {
+	start, _, err := s.Interval(weekStart)
+	if err != nil {
+		return SlotOccurrence{}, err
+	}
+	return NewSlotOccurrence(start, s.ID), nil
+}
+
+// Key is used to index availability and bookings, e.g. "2019-12-30_MondayMorning"
+func (o SlotOccurrence) Key() string {
+	return fmt.Sprintf("%s_%s", o.Date, o.SlotID)
+}
+
+func ParseSlotOccurrence(key string) (SlotOccurrence, error) {
+	s := strings.SplitN(key, "_", 2)
+	if len(s) < 2 {
+		return SlotOccurrence{}, fmt.Errorf("[ERROR] Invalid slot occurrence - %s", key)
+	}
+
+	occurrence := SlotOccurrence{
+		Date:   s[0],
+		SlotID: s[1],
+	}
+	_, err := occurrence.Day()
+	return occurrence, err
+}
+
+// Day returns the date of the occurrence as midnight UTC
+func (o SlotOccurrence) Day() (time.Time, error) {
+	return time.Parse(DateFormat, o.Date)
+}
+
+type BookingKind = string
+
+const (
+	CodeReview  BookingKind = "code_review"
+	LivePairing BookingKind = "live_pairing"
+	Debrief     BookingKind = "debrief"
+)
+
+// BookingKinds lists the kinds of bookings in the order they happen for a candidate
+var BookingKinds = []BookingKind{CodeReview, LivePairing, Debrief}
+
+func BookingKindLabel(kind BookingKind) string {
+	switch kind {
+	case CodeReview:
+		return "Code review"
+	case LivePairing:
+		return "Live pairing"
+	case Debrief:
+		return "Debrief"
+	default:
+		return "Booking"
+	}
+}
+
+// Booking is a reviewer booked for a slot occurrence, to review the challenge of a candidate
+type Booking struct {
+	ID         string         `bson:"ID"`
+	ReviewerID string         `bson:"ReviewerID"`
+	Occurrence SlotOccurrence `bson:"Occurrence"`
+	// The candidate and the challenge instance, i.e. the repository created for the candidate
+	CandidateName string `bson:"CandidateName"`
+	ChallengeID   string `bson:"ChallengeID"`
+	ChallengeURL  string `bson:"ChallengeURL"`
+	// BookedBy is the Slack ID of the user who made the booking
+	BookedBy  string      `bson:"BookedBy"`
+	Kind      BookingKind `bson:"Kind"`
+	Notes     string      `bson:"Notes"`
+	CreatedAt time.Time   `bson:"CreatedAt"`
+	// Reminders sent to the reviewer before the booked slot
+	DayReminderSent  bool `bson:"DayReminderSent"`
+	HourReminderSent bool `bson:"HourReminderSent"`
+}
+
+func NewBooking(reviewerID string, occurrence SlotOccurrence, input map[string]string) Booking {
+	kind := input["kind"]
+	if kind == "" {
+		kind = CodeReview
+	}
+	return Booking{
+		ID:            util.RandomString(8),
+		ReviewerID:    reviewerID,
+		Occurrence:    occurrence,
+		CandidateName: strings.TrimSpace(input["candidate_name"]),
+		ChallengeID:   input["challenge_id"],
+		ChallengeURL:  strings.TrimSpace(input["challenge_url"]),
+		BookedBy:      input["booked_by"],
+		Kind:          kind,
+		Notes:         input["notes"],
+		CreatedAt:     time.Now(),
+	}
+}
+
+// UpcomingBookings lists the bookings of the reviewer from the date ("2006-01-02") on, in date order
+func (r Reviewer) UpcomingBookings(from string) []Booking {
+	bookings := make([]Booking, 0, len(r.Bookings))
+	for _, booking := range r.Bookings {
+		if booking.Occurrence.Date >= from {
+			bookings = append(bookings, booking)
+		}
+	}
+	sort.Slice(bookings, func(i, j int) bool { return bookings[i].Occurrence.Key() < bookings[j].Occurrence.Key() })
+	return bookings
+}
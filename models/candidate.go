@@ -1,17 +1,297 @@
 package models
 
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/db"
+	"github.com/keremk/challenge-bot/util"
+)
+
 type Candidate struct {
 	Name        string `bson:"Name"`
 	GithubAlias string `bson:"GithubAlias"`
 	ResumeURL   string `bson:"ResumeURL"`
 	ChallengeID string `bson:"ChallengeID"`
+	// The challenge instance sent to the candidate, saved once the repository is created
+	ID           string `bson:"ID"`
+	ChallengeURL string `bson:"ChallengeURL"`
+	// ReviewerIDs are the Slack IDs of the reviewers assigned to the candidate
+	ReviewerIDs []string `bson:"ReviewerIDs"`
+	// SentBy is the Slack ID of the coordinator who sent the challenge
+	SentBy    string    `bson:"SentBy"`
+	CreatedAt time.Time `bson:"CreatedAt"`
+	// Pairing is the live pairing session the candidate picked, empty until they pick one
+	Pairing SlotOccurrence `bson:"Pairing"`
+	// The pull request of the candidate's solution, the review is pending until it is closed
+	SubmissionURL string    `bson:"SubmissionURL"`
+	SubmittedAt   time.Time `bson:"SubmittedAt"`
+	ClosedAt      time.Time `bson:"ClosedAt"`
+	// Deadline of the solution, zero for candidates sent before deadlines were tracked, see DueDate
+	Deadline time.Time `bson:"Deadline"`
+	// WithdrawnAt is set when the candidate is withdrawn from the process
+	WithdrawnAt time.Time `bson:"WithdrawnAt"`
+	// CIStatus is the result of the latest build of the challenge repository, e.g. success or failure
+	CIStatus string `bson:"CIStatus"`
+	// Verdicts are the states of the reviews of the pull request, e.g. approved, by the Github alias of the reviewer
+	Verdicts map[string]string `bson:"Verdicts"`
 }
 
+// ChallengeDays is the time candidates have for a challenge, unless it is extended
+const ChallengeDays = 7
+
+type CandidateState = string
+
+const (
+	// The challenge is sent, the solution is not submitted yet
+	CandidateSent    CandidateState = "sent"
+	CandidateOverdue CandidateState = "overdue"
+	// The pull request with the solution is open, waiting for the review
+	CandidateSubmitted CandidateState = "submitted"
+	CandidateClosed    CandidateState = "closed"
+	CandidateWithdrawn CandidateState = "withdrawn"
+)
+
 func NewCandidate(input map[string]string) Candidate {
+	now := time.Now()
 	return Candidate{
 		Name:        input["candidate_name"],
 		GithubAlias: input["github_alias"],
 		ResumeURL:   input["resume_URL"],
 		ChallengeID: input["challenge_id"],
+		ID:          util.RandomString(8),
+		CreatedAt:   now,
+		Deadline:    now.AddDate(0, 0, ChallengeDays),
+	}
+}
+
+func GetCandidate(env config.Environment, id string) (Candidate, error) {
+	candidate := Candidate{}
+	store, err := db.NewStore(env, db.CandidatesCollection)
+	if err != nil {
+		return candidate, err
+	}
+
+	err = store.FindByID(id, &candidate)
+	return candidate, err
+}
+
+// GetCandidateByGithubAlias finds the latest challenge sent to the candidate with the Github alias
+func GetCandidateByGithubAlias(env config.Environment, githubAlias string) (Candidate, error) {
+	store, err := db.NewStore(env, db.CandidatesCollection)
+	if err != nil {
+		return Candidate{}, err
+	}
+
+	var all []Candidate
+	result, err := store.FindAllWithKeyValue(reflect.TypeOf(all), "GithubAlias", githubAlias)
+	if err != nil {
+		return Candidate{}, err
+	}
+	all, ok := result.([]Candidate)
+	if !ok {
+		return Candidate{}, errors.New("[ERROR] Cannot convert")
+	}
+
+	var latest Candidate
+	for _, candidate := range all {
+		if candidate.CreatedAt.After(latest.CreatedAt) {
+			latest = candidate
+		}
+	}
+	if latest.ID == "" {
+		return latest, errors.New("[ERROR] No candidate with the Github alias")
+	}
+	return latest, nil
+}
+
+// FindCandidate finds the latest challenge sent to the candidate with the name or the Github alias, ignoring the case
+func FindCandidate(env config.Environment, nameOrAlias string) (Candidate, error) {
+	all, err := GetAllCandidates(env)
+	if err != nil {
+		return Candidate{}, err
+	}
+
+	var latest Candidate
+	for _, candidate := range all {
+		if !strings.EqualFold(candidate.Name, nameOrAlias) && !strings.EqualFold(candidate.GithubAlias, nameOrAlias) {
+			continue
+		}
+		if candidate.CreatedAt.After(latest.CreatedAt) {
+			latest = candidate
+		}
+	}
+	if latest.ID == "" {
+		return latest, errors.New("[ERROR] No candidate with the name or Github alias")
+	}
+	return latest, nil
+}
+
+// GetCandidateByChallengeURL finds the candidate of the challenge repository
+func GetCandidateByChallengeURL(env config.Environment, challengeURL string) (Candidate, error) {
+	candidate := Candidate{}
+	store, err := db.NewStore(env, db.CandidatesCollection)
+	if err != nil {
+		return candidate, err
+	}
+
+	err = store.FindFirst("ChallengeURL", challengeURL, &candidate)
+	return candidate, err
+}
+
+func GetAllCandidates(env config.Environment) ([]Candidate, error) {
+	store, err := db.NewStore(env, db.CandidatesCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []Candidate
+	result, err := store.FindAll(reflect.TypeOf(all))
+	if err != nil {
+		return nil, err
+	}
+	all, ok := result.([]Candidate)
+	if !ok {
+		return nil, errors.New("[ERROR] Cannot convert")
+	}
+	return all, nil
+}
+
+// GetCandidatesForChallenge lists the candidates the challenge was sent to
+func GetCandidatesForChallenge(env config.Environment, challengeID string) ([]Candidate, error) {
+	store, err := db.NewStore(env, db.CandidatesCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []Candidate
+	result, err := store.FindAllWithKeyValue(reflect.TypeOf(all), "ChallengeID", challengeID)
+	if err != nil {
+		return nil, err
+	}
+	all, ok := result.([]Candidate)
+	if !ok {
+		return nil, errors.New("[ERROR] Cannot convert")
+	}
+	return all, nil
+}
+
+func UpdateCandidate(env config.Environment, candidate Candidate) error {
+	store, err := db.NewStore(env, db.CandidatesCollection)
+	if err != nil {
+		return err
+	}
+	return store.Update(candidate.ID, candidate)
+}
+
+// ChangeCandidate changes the stored candidate without losing the changes made to them at the same time. change
+// can run more than once, so it should only change the candidate.
+func ChangeCandidate(env config.Environment, id string, change func(candidate Candidate) (Candidate, error)) (Candidate, error) {
+	candidate := Candidate{}
+	store, err := db.NewStore(env, db.CandidatesCollection)
+	if err != nil {
+		return candidate, err
+	}
+
+	err = store.Transact(id, &candidate, func() error {
+		changed, err := change(candidate)
+		if err != nil {
+			return err
+		}
+		candidate = changed
+		return nil
+	})
+	return candidate, err
+}
+
+// HasPairing checks if the candidate picked a live pairing session
+func (c Candidate) HasPairing() bool {
+	return c.Pairing.Date != ""
+}
+
+// IsReviewer checks if the reviewer with the Slack ID is assigned to the candidate
+func (c Candidate) IsReviewer(slackID string) bool {
+	for _, reviewerID := range c.ReviewerIDs {
+		if reviewerID == slackID {
+			return true
+		}
+	}
+	return false
+}
+
+// WithoutReviewer unassigns the reviewer with the Slack ID from the candidate
+func (c Candidate) WithoutReviewer(slackID string) Candidate {
+	reviewerIDs := make([]string, 0, len(c.ReviewerIDs))
+	for _, reviewerID := range c.ReviewerIDs {
+		if reviewerID != slackID {
+			reviewerIDs = append(reviewerIDs, reviewerID)
+		}
+	}
+	c.ReviewerIDs = reviewerIDs
+	return c
+}
+
+// RemoveReviewerFromCandidates unassigns a removed reviewer from all the candidates
+func RemoveReviewerFromCandidates(env config.Environment, slackID string) error {
+	candidates, err := GetAllCandidates(env)
+	if err != nil {
+		return err
+	}
+	for _, candidate := range candidates {
+		if !candidate.IsReviewer(slackID) {
+			continue
+		}
+		err = UpdateCandidate(env, candidate.WithoutReviewer(slackID))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsSubmitted checks if the candidate opened a pull request with their solution
+func (c Candidate) IsSubmitted() bool {
+	return !c.SubmittedAt.IsZero()
+}
+
+// IsReviewPending checks if the solution of the candidate is submitted, and its pull request is still open
+func (c Candidate) IsReviewPending() bool {
+	return c.IsSubmitted() && c.InFlight()
+}
+
+// InFlight checks if the candidate is still working on the challenge or waiting for its review
+func (c Candidate) InFlight() bool {
+	return c.ClosedAt.IsZero() && c.WithdrawnAt.IsZero()
+}
+
+// DueDate is the deadline of the solution
+func (c Candidate) DueDate() time.Time {
+	if c.Deadline.IsZero() {
+		return c.CreatedAt.AddDate(0, 0, ChallengeDays)
+	}
+	return c.Deadline
+}
+
+// Extend moves the deadline of the solution by the days
+func (c Candidate) Extend(days int) Candidate {
+	c.Deadline = c.DueDate().AddDate(0, 0, days)
+	return c
+}
+
+func (c Candidate) State(now time.Time) CandidateState {
+	switch {
+	case !c.WithdrawnAt.IsZero():
+		return CandidateWithdrawn
+	case !c.ClosedAt.IsZero():
+		return CandidateClosed
+	case c.IsSubmitted():
+		return CandidateSubmitted
+	case now.After(c.DueDate()):
+		return CandidateOverdue
+	default:
+		return CandidateSent
 	}
 }
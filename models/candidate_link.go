@@ -0,0 +1,48 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// PairingLinkDuration is how long a candidate can use the link to pick a live pairing session
+const PairingLinkDuration = 14 * 24 * time.Hour
+
+type LinkExpiredError struct{}
+
+func (e LinkExpiredError) Error() string {
+	return "Link is expired"
+}
+
+// SignLink signs the candidate ID and the expiry (Unix seconds) of a candidate link, so the link cannot be
+// changed to another candidate or extended
+func SignLink(secret, candidateID string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s:%d", candidateID, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyLink checks the signature of a candidate link and that it is not expired
+func VerifyLink(secret, candidateID, expires, signature string, now time.Time) error {
+	if secret == "" {
+		return errors.New("[ERROR] LINK_SECRET is not set, candidate links are disabled")
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return fmt.Errorf("[ERROR] Invalid link expiry - %s", expires)
+	}
+
+	expected := SignLink(secret, candidateID, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errors.New("[ERROR] Invalid link signature")
+	}
+	if now.Unix() > expiresAt {
+		return LinkExpiredError{}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyLink(t *testing.T) {
+	now := time.Date(2020, 1, 6, 12, 0, 0, 0, time.UTC)
+	expires := now.Add(PairingLinkDuration).Unix()
+	expiresText := strconv.FormatInt(expires, 10)
+	signature := SignLink("secret", "abc123", expires)
+
+	assert.Nil(t, VerifyLink("secret", "abc123", expiresText, signature, now))
+
+	// Another candidate, a later expiry or another secret do not match the signature
+	assert.NotNil(t, VerifyLink("secret", "xyz789", expiresText, signature, now))
+	assert.NotNil(t, VerifyLink("secret", "abc123", strconv.FormatInt(expires+1, 10), signature, now))
+	assert.NotNil(t, VerifyLink("other", "abc123", expiresText, signature, now))
+	assert.NotNil(t, VerifyLink("", "abc123", expiresText, SignLink("", "abc123", expires), now))
+
+	err := VerifyLink("secret", "abc123", expiresText, signature, now.Add(PairingLinkDuration+time.Second))
+	assert.IsType(t, LinkExpiredError{}, err)
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCandidateStates(t *testing.T) {
+	sent := time.Date(2020, 1, 6, 12, 0, 0, 0, time.UTC)
+	candidate := Candidate{CreatedAt: sent}
+
+	// Candidates sent before deadlines were tracked have the default time
+	assert.Equal(t, sent.AddDate(0, 0, ChallengeDays), candidate.DueDate())
+	assert.Equal(t, CandidateSent, candidate.State(sent.AddDate(0, 0, 1)))
+	assert.Equal(t, CandidateOverdue, candidate.State(sent.AddDate(0, 0, ChallengeDays+1)))
+
+	candidate = candidate.Extend(3)
+	assert.Equal(t, sent.AddDate(0, 0, ChallengeDays+3), candidate.Deadline)
+	assert.Equal(t, CandidateSent, candidate.State(sent.AddDate(0, 0, ChallengeDays+1)))
+
+	candidate.SubmittedAt = sent.AddDate(0, 0, 5)
+	assert.Equal(t, CandidateSubmitted, candidate.State(sent.AddDate(0, 0, 30)))
+	assert.True(t, candidate.IsReviewPending())
+
+	candidate.WithdrawnAt = sent.AddDate(0, 0, 6)
+	assert.Equal(t, CandidateWithdrawn, candidate.State(sent.AddDate(0, 0, 30)))
+	assert.False(t, candidate.InFlight())
+	assert.False(t, candidate.IsReviewPending())
+}
+
+func TestCandidateWithoutReviewer(t *testing.T) {
+	candidate := Candidate{ReviewerIDs: []string{"U1", "U2", "U3"}}
+
+	candidate = candidate.WithoutReviewer("U2")
+	assert.Equal(t, []string{"U1", "U3"}, candidate.ReviewerIDs)
+	assert.False(t, candidate.IsReviewer("U2"))
+
+	candidate = candidate.WithoutReviewer("U4")
+	assert.Equal(t, []string{"U1", "U3"}, candidate.ReviewerIDs)
+}
@@ -13,6 +13,8 @@ import (
 
 type SlotID = string
 
+// Slot is a weekly recurring interview window. StartTime and EndTime are wall clock times ("9:00")
+// in the slot's TimeZone, so the same slot maps to different local times for each viewer.
 type Slot struct {
 	ID        string `bson:"ID"`
 	Ordinal   int    `bson:"Ordinal"`
@@ -20,6 +22,56 @@ type Slot struct {
 	Day       string `bson:"Day"`
 	StartTime string `bson:"StartTime"`
 	EndTime   string `bson:"EndTime"`
+	TimeZone  string `bson:"TimeZone"`
+	// Duration of an interview in minutes, 0 if it takes the whole slot
+	Duration int `bson:"Duration"`
+}
+
+func (s Slot) Location() *time.Location {
+	return LoadLocation(s.TimeZone)
+}
+
+func (s Slot) Weekday() (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if day.String() == s.Day {
+			return day, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("[ERROR] Invalid day for slot %s - %s", s.ID, s.Day)
+}
+
+// Interval returns the start and end of the slot in the week starting on the Monday weekStart.
+// Only the calendar date of weekStart is used, the times are resolved in the slot's time zone.
+func (s Slot) Interval(weekStart time.Time) (time.Time, time.Time, error) {
+	weekday, err := s.Weekday()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	startHour, startMin, err := parseClock(s.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	endHour, endMin, err := parseClock(s.EndTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	// Monday is the first day of the week, Sunday the last
+	offset := (int(weekday) + 6) % 7
+	year, month, day := weekStart.Date()
+	loc := s.Location()
+	start := time.Date(year, month, day+offset, startHour, startMin, 0, 0, loc)
+	end := time.Date(year, month, day+offset, endHour, endMin, 0, 0, loc)
+	return start, end, nil
+}
+
+func parseClock(clock string) (int, int, error) {
+	var hour, min int
+	_, err := fmt.Sscanf(clock, "%d:%d", &hour, &min)
+	if err != nil || hour < 0 || hour > 23 || min < 0 || min > 59 {
+		return 0, 0, fmt.Errorf("[ERROR] Invalid time of day - %s", clock)
+	}
+	return hour, min, nil
 }
 
 type Challenge struct {
@@ -29,7 +81,9 @@ type Challenge struct {
 	TemplateRepo      string           `bson:"TemplateRepo"`
 	RepoNameFormat    string           `bson:"RepoNameFormat"`
 	CreatedByTeamID   string           `bson:"CreatedByTeamID"`
+	TimeZone          string           `bson:"TimeZone"`
 	Slots             map[SlotID]*Slot `bson:"Slots"`
+	CalendarToken     string           `bson:"CalendarToken"`
 }
 
 func NewChallenge(input map[string]string) Challenge {
@@ -43,6 +97,7 @@ func NewChallenge(input map[string]string) Challenge {
 		TemplateRepo:      input["template_repo"],
 		RepoNameFormat:    input["repo_name_format"],
 		CreatedByTeamID:   input["team_id"],
+		TimeZone:          ValidTimeZone(input["time_zone"]),
 	}
 }
 
@@ -59,7 +114,9 @@ func EditChallenge(env config.Environment, input map[string]string, challengeID
 		TemplateRepo:      input["template_repo"],
 		RepoNameFormat:    input["repo_name_format"],
 		CreatedByTeamID:   input["team_id"],
+		TimeZone:          ValidTimeZone(input["time_zone"]),
 		Slots:             challenge.Slots,
+		CalendarToken:     challenge.CalendarToken,
 	}, nil
 }
 
@@ -85,6 +142,35 @@ func getChallengeByID(env config.Environment, id string) (Challenge, error) {
 	return challenge, err
 }
 
+func getChallengeByCalendarToken(env config.Environment, token string) (Challenge, error) {
+	challenge := Challenge{}
+	if token == "" {
+		return challenge, errors.New("[ERROR] No calendar token")
+	}
+	store, err := db.NewStore(env, db.SettingsCollection)
+	if err != nil {
+		return challenge, err
+	}
+
+	err = store.FindFirst("CalendarToken", token, &challenge)
+	return challenge, err
+}
+
+// EnsureChallengeCalendarToken returns the token of the challenge's bookings calendar feed, creating it if there is none yet
+func EnsureChallengeCalendarToken(env config.Environment, challengeID string) (string, error) {
+	challenge, err := getChallengeByID(env, challengeID)
+	if err != nil {
+		return "", err
+	}
+	if challenge.CalendarToken != "" {
+		return challenge.CalendarToken, nil
+	}
+
+	challenge.CalendarToken = util.SecretToken()
+	err = UpdateChallenge(env, challenge)
+	return challenge.CalendarToken, err
+}
+
 func GetAllChallenges(env config.Environment) ([]Challenge, error) {
 	store, err := db.NewStore(env, db.SettingsCollection)
 	if err != nil {
@@ -105,18 +191,36 @@ func UpdateChallenge(env config.Environment, challenge Challenge) error {
 	if err != nil {
 		return err
 	}
+	if challenge.TimeZone == "" {
+		challenge.TimeZone = DefaultTimeZone
+	}
 	if challenge.Slots == nil || len(challenge.Slots) == 0 {
-		challenge.Slots = defaultSlots()
+		challenge.Slots = defaultSlots(challenge.ID, challenge.TimeZone)
+	}
+	for _, slot := range challenge.Slots {
+		// Slots created before time zones were introduced follow the challenge's canonical time zone
+		if slot.TimeZone == "" {
+			slot.TimeZone = challenge.TimeZone
+		}
 	}
 	return store.Update(challenge.ID, challenge)
 }
 
-func defaultSlots() map[SlotID]*Slot {
+func DeleteChallenge(env config.Environment, id string) error {
+	store, err := db.NewStore(env, db.SettingsCollection)
+	if err != nil {
+		return err
+	}
+	return store.Delete(id)
+}
+
+func defaultSlots(challengeID, timeZone string) map[SlotID]*Slot {
+	suffix := slotIDSuffix(challengeID)
 	slots := make(map[SlotID]*Slot)
 	ordinal := 0
 	for i := 0; i < 5; i++ {
 		day := time.Weekday(i + 1)
-		slotID := fmt.Sprintf("%sMorning", day.String())
+		slotID := fmt.Sprintf("%sMorning%s", day.String(), suffix)
 		slots[slotID] = &Slot{
 			ID:        slotID,
 			Ordinal:   ordinal,
@@ -124,9 +228,10 @@ func defaultSlots() map[SlotID]*Slot {
 			Day:       day.String(),
 			StartTime: "9:00",
 			EndTime:   "11:00",
+			TimeZone:  timeZone,
 		}
 
-		slotID = fmt.Sprintf("%sAfternoon", day.String())
+		slotID = fmt.Sprintf("%sAfternoon%s", day.String(), suffix)
 		ordinal++
 		slots[slotID] = &Slot{
 			ID:        slotID,
@@ -135,6 +240,7 @@ func defaultSlots() map[SlotID]*Slot {
 			Day:       day.String(),
 			StartTime: "16:30",
 			EndTime:   "18:30",
+			TimeZone:  timeZone,
 		}
 		ordinal++
 	}
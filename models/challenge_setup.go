@@ -8,7 +8,7 @@ import (
 )
 
 type ChallengeSetup struct {
-	ID              string 
+	ID              string
 	Name            string
 	GithubOwner     string
 	GithubOrg       string
@@ -16,7 +16,9 @@ type ChallengeSetup struct {
 	TemplateRepo    string
 	RepoNameFormat  string
 	CreatedByTeamID string
+	TimeZone        string
 	Slots           map[SlotID]*Slot
+	CalendarToken   string
 }
 
 func GetChallengeSetupByID(env config.Environment, id string) (ChallengeSetup, error) {
@@ -37,12 +39,30 @@ func GetChallengeSetupByName(env config.Environment, name string) (ChallengeSetu
 	return getChallengeSetup(env, challenge)
 }
 
+func GetChallengeSetupByCalendarToken(env config.Environment, token string) (ChallengeSetup, error) {
+	challenge, err := getChallengeByCalendarToken(env, token)
+	if err != nil {
+		return ChallengeSetup{}, err
+	}
+
+	return getChallengeSetup(env, challenge)
+}
+
 func getChallengeSetup(env config.Environment, challenge Challenge) (ChallengeSetup, error) {
 	account, err := GetGithubAccount(env, challenge.GithubAccountName)
 	if err != nil {
 		return ChallengeSetup{}, err
 	}
 
+	if challenge.TimeZone == "" {
+		challenge.TimeZone = DefaultTimeZone
+	}
+	for _, slot := range challenge.Slots {
+		if slot.TimeZone == "" {
+			slot.TimeZone = challenge.TimeZone
+		}
+	}
+
 	return ChallengeSetup{
 		ID:              challenge.ID,
 		Name:            challenge.Name,
@@ -52,7 +72,9 @@ func getChallengeSetup(env config.Environment, challenge Challenge) (ChallengeSe
 		TemplateRepo:    challenge.TemplateRepo,
 		RepoNameFormat:  challenge.RepoNameFormat,
 		CreatedByTeamID: challenge.CreatedByTeamID,
+		TimeZone:        challenge.TimeZone,
 		Slots:           challenge.Slots,
+		CalendarToken:   challenge.CalendarToken,
 	}, nil
 }
 
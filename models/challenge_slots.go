@@ -0,0 +1,195 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+)
+
+// SlotLength is the length of the slot window
+func (s Slot) SlotLength() (time.Duration, error) {
+	startHour, startMin, err := parseClock(s.StartTime)
+	if err != nil {
+		return 0, err
+	}
+	endHour, endMin, err := parseClock(s.EndTime)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration((endHour-startHour)*60+endMin-startMin) * time.Minute, nil
+}
+
+// InterviewDuration is the length of an interview in the slot, the whole slot if not set
+func (s Slot) InterviewDuration() time.Duration {
+	if s.Duration > 0 {
+		return time.Duration(s.Duration) * time.Minute
+	}
+	length, _ := s.SlotLength()
+	return length
+}
+
+// NewSlot validates the slot input (day, slot_name, start_time, end_time, duration) and gives it an ID
+// that is unique across challenges. IDs only contain letters and digits, as they are part of action encodings.
+func NewSlot(challenge Challenge, input map[string]string) (Slot, error) {
+	slot := Slot{
+		Day:       input["day"],
+		StartTime: strings.TrimSpace(input["start_time"]),
+		EndTime:   strings.TrimSpace(input["end_time"]),
+		TimeZone:  challenge.TimeZone,
+	}
+	if _, err := slot.Weekday(); err != nil {
+		return slot, InputError{Input: "day", Message: "Pick the day of the slot."}
+	}
+	if _, _, err := parseClock(slot.StartTime); err != nil {
+		return slot, InputError{Input: "start_time", Message: "Use a 24 hour clock, e.g. 9:00."}
+	}
+	if _, _, err := parseClock(slot.EndTime); err != nil {
+		return slot, InputError{Input: "end_time", Message: "Use a 24 hour clock, e.g. 17:30."}
+	}
+
+	length, err := slot.SlotLength()
+	if err != nil {
+		return slot, err
+	}
+	if length <= 0 {
+		return slot, InputError{Input: "end_time", Message: "The slot has to end after it starts."}
+	}
+
+	if input["duration"] != "" {
+		duration, err := strconv.Atoi(input["duration"])
+		if err != nil || duration <= 0 {
+			return slot, InputError{Input: "duration", Message: "Pick the length of the interviews."}
+		}
+		if time.Duration(duration)*time.Minute > length {
+			return slot, InputError{Input: "duration", Message: fmt.Sprintf("Interviews of %d minutes do not fit in the slot.", duration)}
+		}
+		slot.Duration = duration
+	}
+
+	slot.Name = strings.TrimSpace(input["slot_name"])
+	if slot.Name == "" {
+		slot.Name = fmt.Sprintf("%s %s", slot.Day, slot.StartTime)
+	}
+
+	slot.ID = uniqueSlotID(challenge, fmt.Sprintf("%s%s", slot.Day, strings.Replace(slot.StartTime, ":", "", -1)))
+	return slot, nil
+}
+
+// slotIDSuffix ends the slot IDs of the challenge. The availability and bookings of a reviewer are keyed by the slot
+// IDs of all their challenges, so the IDs need to be unique across challenges.
+func slotIDSuffix(challengeID string) string {
+	if challengeID == "" {
+		return ""
+	}
+	h := fnv.New32a()
+	h.Write([]byte(challengeID))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// uniqueSlotID is the base ID with the suffix of the challenge, numbered if the challenge has it already
+func uniqueSlotID(challenge Challenge, baseID string) SlotID {
+	suffix := slotIDSuffix(challenge.ID)
+	slotID := baseID + suffix
+	for i := 2; challenge.Slots[slotID] != nil; i++ {
+		slotID = fmt.Sprintf("%s%d%s", baseID, i, suffix)
+	}
+	return slotID
+}
+
+// AddSlot adds the slot at the end of the challenge's slots
+func AddSlot(challenge Challenge, slot Slot) Challenge {
+	if challenge.Slots == nil {
+		challenge.Slots = make(map[SlotID]*Slot)
+	}
+	slot.Ordinal = len(challenge.Slots)
+	challenge.Slots[slot.ID] = &slot
+	return renumberSlots(challenge)
+}
+
+func RemoveSlot(challenge Challenge, slotID SlotID) (Challenge, error) {
+	if challenge.Slots[slotID] == nil {
+		return challenge, fmt.Errorf("[ERROR] No such slot - %s", slotID)
+	}
+	// Challenges without slots get the default slots
+	if len(challenge.Slots) == 1 {
+		return challenge, errors.New("[ERROR] Cannot remove the last slot of a challenge")
+	}
+	delete(challenge.Slots, slotID)
+	return renumberSlots(challenge), nil
+}
+
+// EditSlot replaces the slot with the validated input, keeping its ID and its place in the order
+func EditSlot(challenge Challenge, slotID SlotID, input map[string]string) (Challenge, error) {
+	existing := challenge.Slots[slotID]
+	if existing == nil {
+		return challenge, fmt.Errorf("[ERROR] No such slot - %s", slotID)
+	}
+	slot, err := NewSlot(challenge, input)
+	if err != nil {
+		return challenge, err
+	}
+	slot.ID = existing.ID
+	slot.Ordinal = existing.Ordinal
+	challenge.Slots[slotID] = &slot
+	return challenge, nil
+}
+
+// SameTime tells whether the slots are on the same day and times
+func (s Slot) SameTime(other Slot) bool {
+	return s.Day == other.Day && s.StartTime == other.StartTime && s.EndTime == other.EndTime
+}
+
+// MoveSlot moves the slot up (negative offset) or down in the order of slots
+func MoveSlot(challenge Challenge, slotID SlotID, offset int) (Challenge, error) {
+	if challenge.Slots[slotID] == nil {
+		return challenge, fmt.Errorf("[ERROR] No such slot - %s", slotID)
+	}
+
+	challenge = renumberSlots(challenge)
+	slots := slotsInOrder(challenge.Slots)
+	for i, slot := range slots {
+		if slot.ID != slotID {
+			continue
+		}
+		j := i + offset
+		if j < 0 || j >= len(slots) {
+			return challenge, nil
+		}
+		slots[i].Ordinal, slots[j].Ordinal = slots[j].Ordinal, slots[i].Ordinal
+		break
+	}
+	return renumberSlots(challenge), nil
+}
+
+// renumberSlots makes the ordinals consecutive, keeping the order
+func renumberSlots(challenge Challenge) Challenge {
+	for i, slot := range slotsInOrder(challenge.Slots) {
+		slot.Ordinal = i
+	}
+	return challenge
+}
+
+func slotsInOrder(slotMap map[SlotID]*Slot) []*Slot {
+	slots := make([]*Slot, 0, len(slotMap))
+	for _, slot := range slotMap {
+		slots = append(slots, slot)
+	}
+	sort.SliceStable(slots, func(i, j int) bool {
+		if slots[i].Ordinal == slots[j].Ordinal {
+			return slots[i].ID < slots[j].ID
+		}
+		return slots[i].Ordinal < slots[j].Ordinal
+	})
+	return slots
+}
+
+// GetChallengeByID is used to edit the challenge settings, such as the slots
+func GetChallengeByID(env config.Environment, id string) (Challenge, error) {
+	return getChallengeByID(env, id)
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddingSlots(t *testing.T) {
+	challenge := Challenge{ID: "ios-123", TimeZone: "Europe/Berlin", Slots: defaultSlots("ios-123", "Europe/Berlin")}
+	suffix := slotIDSuffix("ios-123")
+
+	slot, err := NewSlot(challenge, map[string]string{
+		"day":        "Saturday",
+		"start_time": "10:00",
+		"end_time":   "12:30",
+		"duration":   "60",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "Saturday1000"+suffix, slot.ID)
+	assert.Equal(t, "Saturday 10:00", slot.Name)
+
+	challenge = AddSlot(challenge, slot)
+	assert.Equal(t, 11, len(challenge.Slots))
+	assert.Equal(t, 10, challenge.Slots["Saturday1000"+suffix].Ordinal)
+
+	duplicate, err := NewSlot(challenge, map[string]string{"day": "Saturday", "start_time": "10:00", "end_time": "11:00"})
+	assert.Nil(t, err)
+	assert.Equal(t, "Saturday10002"+suffix, duplicate.ID)
+}
+
+func TestSlotIDsAreUniqueAcrossChallenges(t *testing.T) {
+	ios := defaultSlots("ios-123", "UTC")
+	android := defaultSlots("android-456", "UTC")
+	for slotID := range ios {
+		assert.Nil(t, android[slotID])
+	}
+	assert.Regexp(t, "^MondayMorning[0-9a-f]{8}$", ios["MondayMorning"+slotIDSuffix("ios-123")].ID)
+}
+
+func TestInvalidSlots(t *testing.T) {
+	challenge := Challenge{TimeZone: "UTC"}
+
+	tests := []struct {
+		name  string
+		input map[string]string
+		want  string
+	}{
+		{"unknown day", map[string]string{"day": "Someday", "start_time": "10:00", "end_time": "11:00"}, "day"},
+		{"invalid start", map[string]string{"day": "Monday", "start_time": "10am", "end_time": "11:00"}, "start_time"},
+		{"invalid end", map[string]string{"day": "Monday", "start_time": "10:00", "end_time": "25:00"}, "end_time"},
+		{"ends before it starts", map[string]string{"day": "Monday", "start_time": "11:00", "end_time": "10:00"}, "end_time"},
+		{"interview does not fit", map[string]string{"day": "Monday", "start_time": "10:00", "end_time": "11:00", "duration": "90"}, "duration"},
+	}
+	for _, test := range tests {
+		_, err := NewSlot(challenge, test.input)
+		inputErr, ok := err.(InputError)
+		assert.True(t, ok, test.name)
+		assert.Equal(t, test.want, inputErr.Input, test.name)
+	}
+}
+
+func TestMovingAndRemovingSlots(t *testing.T) {
+	challenge := Challenge{Slots: map[SlotID]*Slot{
+		"A": &Slot{ID: "A", Ordinal: 0},
+		"B": &Slot{ID: "B", Ordinal: 1},
+		"C": &Slot{ID: "C", Ordinal: 2},
+	}}
+
+	challenge, err := MoveSlot(challenge, "C", -1)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, challenge.Slots["C"].Ordinal)
+	assert.Equal(t, 2, challenge.Slots["B"].Ordinal)
+
+	challenge, err = MoveSlot(challenge, "A", -1)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, challenge.Slots["A"].Ordinal)
+
+	challenge, err = RemoveSlot(challenge, "A")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, challenge.Slots["C"].Ordinal)
+	assert.Equal(t, 1, challenge.Slots["B"].Ordinal)
+
+	challenge, _ = RemoveSlot(challenge, "B")
+	_, err = RemoveSlot(challenge, "C")
+	assert.NotNil(t, err)
+}
+
+func TestEditingSlots(t *testing.T) {
+	challenge := Challenge{TimeZone: "UTC", Slots: map[SlotID]*Slot{
+		"A": &Slot{ID: "A", Name: "Monday 9:00", Day: "Monday", StartTime: "9:00", EndTime: "11:00", Ordinal: 0},
+		"B": &Slot{ID: "B", Name: "Tuesday 9:00", Day: "Tuesday", StartTime: "9:00", EndTime: "11:00", Ordinal: 1},
+	}}
+
+	challenge, err := EditSlot(challenge, "A", map[string]string{
+		"slot_name":  "Early Monday",
+		"day":        "Monday",
+		"start_time": "8:00",
+		"end_time":   "10:00",
+		"duration":   "60",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(challenge.Slots))
+	edited := challenge.Slots["A"]
+	assert.Equal(t, "A", edited.ID)
+	assert.Equal(t, 0, edited.Ordinal)
+	assert.Equal(t, "Early Monday", edited.Name)
+	assert.Equal(t, "8:00", edited.StartTime)
+	assert.Equal(t, 60, edited.Duration)
+	assert.False(t, edited.SameTime(Slot{Day: "Monday", StartTime: "9:00", EndTime: "11:00"}))
+
+	_, err = EditSlot(challenge, "B", map[string]string{"day": "Tuesday", "start_time": "11:00", "end_time": "10:00"})
+	assert.NotNil(t, err)
+	assert.Equal(t, "9:00", challenge.Slots["B"].StartTime)
+
+	_, err = EditSlot(challenge, "C", map[string]string{"day": "Tuesday", "start_time": "9:00", "end_time": "10:00"})
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,13 @@
+package models
+
+import "fmt"
+
+// InputError is returned when an input is not valid, so the message can be shown next to the input to fix it
+type InputError struct {
+	Input   string
+	Message string
+}
+
+func (e InputError) Error() string {
+	return fmt.Sprintf("[ERROR] Invalid %s - %s", e.Input, e.Message)
+}
@@ -0,0 +1,259 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/db"
+	"github.com/keremk/challenge-bot/util"
+)
+
+type OfferStatus = string
+
+const (
+	OfferOpen     OfferStatus = "open"
+	OfferAccepted OfferStatus = "accepted"
+	OfferPassed   OfferStatus = "passed"
+	// The need was filled or cancelled before the reviewer answered
+	OfferExpired OfferStatus = "expired"
+)
+
+// Need is a coordinator's request for reviewers of a candidate in a week, e.g. 2 Go reviewers for Jane in week 14.
+// Reviewers are offered a slot when capacity opens, the first ones to accept are booked.
+type Need struct {
+	ID            string      `bson:"ID"`
+	ChallengeID   string      `bson:"ChallengeID"`
+	CandidateName string      `bson:"CandidateName"`
+	Technology    string      `bson:"Technology"`
+	Experience    int         `bson:"Experience"`
+	Kind          BookingKind `bson:"Kind"`
+	// WeekStart is the Monday ("2006-01-02") of the week the reviewers are needed
+	WeekStart string `bson:"WeekStart"`
+	Count     int    `bson:"Count"`
+	// CreatedBy is the Slack ID of the coordinator, who is told when reviewers accept
+	CreatedBy string    `bson:"CreatedBy"`
+	CreatedAt time.Time `bson:"CreatedAt"`
+	Cancelled bool      `bson:"Cancelled"`
+	// Booked are the slots of the reviewers that accepted, a booking that is cancelled is removed
+	Booked []NeedBooking `bson:"Booked"`
+	Offers []NeedOffer   `bson:"Offers"`
+}
+
+type NeedBooking struct {
+	ReviewerID string         `bson:"ReviewerID"`
+	Occurrence SlotOccurrence `bson:"Occurrence"`
+}
+
+// NeedOffer is a slot offered to a reviewer, with the message of the offer to update it once answered
+type NeedOffer struct {
+	ReviewerID string         `bson:"ReviewerID"`
+	Occurrence SlotOccurrence `bson:"Occurrence"`
+	Status     OfferStatus    `bson:"Status"`
+	Channel    string         `bson:"Channel"`
+	Timestamp  string         `bson:"Timestamp"`
+}
+
+func NewNeed(input map[string]string, weekStart time.Time) (Need, error) {
+	count, err := strconv.Atoi(input["count"])
+	if err != nil || count < 1 {
+		return Need{}, InputError{Input: "count", Message: "Pick how many reviewers are needed."}
+	}
+	if input["challenge_id"] == "" {
+		return Need{}, InputError{Input: "challenge_id", Message: "Pick the challenge of the candidate."}
+	}
+	kind := input["kind"]
+	if kind == "" {
+		kind = CodeReview
+	}
+	experience, err := strconv.Atoi(input["experience"])
+	if err != nil {
+		experience = 1
+	}
+
+	return Need{
+		ID:            util.RandomString(8),
+		ChallengeID:   input["challenge_id"],
+		CandidateName: strings.TrimSpace(input["candidate_name"]),
+		Technology:    input["technology"],
+		Experience:    experience,
+		Kind:          kind,
+		WeekStart:     weekStart.Format(DateFormat),
+		Count:         count,
+		CreatedBy:     input["created_by"],
+		CreatedAt:     time.Now(),
+		Booked:        make([]NeedBooking, 0),
+		Offers:        make([]NeedOffer, 0),
+	}, nil
+}
+
+func GetNeed(env config.Environment, id string) (Need, error) {
+	need := Need{}
+	store, err := db.NewStore(env, db.NeedsCollection)
+	if err != nil {
+		return need, err
+	}
+
+	err = store.FindByID(id, &need)
+	return need, err
+}
+
+func UpdateNeed(env config.Environment, need Need) error {
+	store, err := db.NewStore(env, db.NeedsCollection)
+	if err != nil {
+		return err
+	}
+	return store.Update(need.ID, need)
+}
+
+// ChangeNeed changes the stored need without losing the changes made to it at the same time, e.g. so only the
+// first reviewers to accept are booked. change can run more than once, so it should only change the need.
+func ChangeNeed(env config.Environment, id string, change func(need Need) (Need, error)) (Need, error) {
+	need := Need{}
+	store, err := db.NewStore(env, db.NeedsCollection)
+	if err != nil {
+		return need, err
+	}
+
+	err = store.Transact(id, &need, func() error {
+		changed, err := change(need)
+		if err != nil {
+			return err
+		}
+		need = changed
+		return nil
+	})
+	return need, err
+}
+
+// GetCurrentNeeds returns the needs that are not cancelled or in the past, filled ones included as their bookings
+// can still be cancelled
+func GetCurrentNeeds(env config.Environment, today time.Time) ([]Need, error) {
+	store, err := db.NewStore(env, db.NeedsCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []Need
+	result, err := store.FindAll(reflect.TypeOf(all))
+	all, ok := result.([]Need)
+	if !ok {
+		return nil, errors.New("[ERROR] Cannot convert")
+	}
+
+	current := make([]Need, 0, len(all))
+	for _, need := range all {
+		if need.IsCurrent(today) {
+			current = append(current, need)
+		}
+	}
+	return current, err
+}
+
+// IsCurrent checks if the need is not cancelled and its week is not over
+func (n Need) IsCurrent(today time.Time) bool {
+	if n.Cancelled {
+		return false
+	}
+	weekStart, err := time.Parse(DateFormat, n.WeekStart)
+	if err != nil {
+		return false
+	}
+	return today.Format(DateFormat) < weekStart.AddDate(0, 0, 7).Format(DateFormat)
+}
+
+// Remaining is the number of reviewers still needed
+func (n Need) Remaining() int {
+	if remaining := n.Count - len(n.Booked); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// OpenOffers are the offers that are not answered yet
+func (n Need) OpenOffers() []NeedOffer {
+	offers := make([]NeedOffer, 0, len(n.Offers))
+	for _, offer := range n.Offers {
+		if offer.Status == OfferOpen {
+			offers = append(offers, offer)
+		}
+	}
+	return offers
+}
+
+// WasOffered checks if the reviewer has an offer, so reviewers are not asked again once they answered. Offers that
+// expired because the need was filled can be made again.
+func (n Need) WasOffered(reviewerID string) bool {
+	offer, ok := n.Offer(reviewerID)
+	return ok && offer.Status != OfferExpired
+}
+
+// AddOffer records the offer, replacing an expired offer to the same reviewer
+func (n Need) AddOffer(offer NeedOffer) Need {
+	offers := make([]NeedOffer, 0, len(n.Offers)+1)
+	for _, existing := range n.Offers {
+		if existing.ReviewerID != offer.ReviewerID {
+			offers = append(offers, existing)
+		}
+	}
+	n.Offers = append(offers, offer)
+	return n
+}
+
+func (n Need) Offer(reviewerID string) (NeedOffer, bool) {
+	for _, offer := range n.Offers {
+		if offer.ReviewerID == reviewerID {
+			return offer, true
+		}
+	}
+	return NeedOffer{}, false
+}
+
+// SetOfferStatus answers the offer of the reviewer
+func (n Need) SetOfferStatus(reviewerID string, status OfferStatus) Need {
+	offers := make([]NeedOffer, 0, len(n.Offers))
+	for _, offer := range n.Offers {
+		if offer.ReviewerID == reviewerID {
+			offer.Status = status
+		}
+		offers = append(offers, offer)
+	}
+	n.Offers = offers
+	return n
+}
+
+// ExpireOffers closes the offers that are not answered yet, once the need is filled or cancelled
+func (n Need) ExpireOffers() Need {
+	for _, offer := range n.OpenOffers() {
+		n = n.SetOfferStatus(offer.ReviewerID, OfferExpired)
+	}
+	return n
+}
+
+// Accept books the reviewer for the offered slot
+func (n Need) Accept(offer NeedOffer) Need {
+	n = n.SetOfferStatus(offer.ReviewerID, OfferAccepted)
+	n.Booked = append(n.Booked, NeedBooking{
+		ReviewerID: offer.ReviewerID,
+		Occurrence: offer.Occurrence,
+	})
+	return n
+}
+
+// ReleaseBooking removes a cancelled booking from the need, so the slot is offered again
+func (n Need) ReleaseBooking(reviewerID string, occurrence SlotOccurrence) (Need, bool) {
+	booked := make([]NeedBooking, 0, len(n.Booked))
+	released := false
+	for _, booking := range n.Booked {
+		if booking.ReviewerID == reviewerID && booking.Occurrence == occurrence {
+			released = true
+			continue
+		}
+		booked = append(booked, booking)
+	}
+	n.Booked = booked
+	return n, released
+}
@@ -3,9 +3,7 @@ package models
 import (
 	"errors"
 	"fmt"
-	"log"
 	"reflect"
-	"strconv"
 
 	"github.com/keremk/challenge-bot/config"
 	"github.com/keremk/challenge-bot/db"
@@ -13,61 +11,108 @@ import (
 )
 
 type Reviewer struct {
-	ID              string              `bson:"ID"`
-	Name            string              `bson:"Name"`
-	GithubAlias     string              `bson:"GithubAlias"`
-	SlackID         string              `bson:"SlackID"`
-	TechnologyList  string              `bson:"TechnologyList"`
-	ChallengeName   string              `bson:"ChallengeName"`
-	ChallengeID     string              `bson:"ChallengeID"`
-	Experience      int                 `bson:"Experience"`
-	BookingsPerWeek int                 `bson:"BookingsPerWeek"`
-	Availability    map[string][]string `bson:"Availability"`
-	Bookings        map[string][]string `bson:"Bookings"`
+	ID          string `bson:"ID"`
+	Name        string `bson:"Name"`
+	GithubAlias string `bson:"GithubAlias"`
+	SlackID     string `bson:"SlackID"`
+	TimeZone    string `bson:"TimeZone"`
+	// Challenges are the challenges the reviewer reviews, with their skills and capacity for each of them
+	Challenges []ChallengeMembership `bson:"Challenges"`
+	// The skills and capacity for one of the challenges, see ForChallenge. Loaded reviewers have the ones of their
+	// first challenge. Reviewers that only had these fields are migrated to a membership when loaded.
+	ChallengeID      string `bson:"ChallengeID"`
+	TechnologyList   string `bson:"TechnologyList"`
+	Experience       int    `bson:"Experience"`
+	BookingsPerWeek  int    `bson:"BookingsPerWeek"`
+	BookingsPerMonth int    `bson:"BookingsPerMonth"`
+	CooldownWeeks    int    `bson:"CooldownWeeks"`
+	// Tags of the challenge membership, see Taxonomy.TagsOf for reviewers without tags
+	Tags []string `bson:"Tags"`
+	// ChallengeName is not used anymore, reviewers refer to their challenges by ID
+	ChallengeName       string   `bson:"ChallengeName"`
+	GeneralAvailability []SlotID `bson:"GeneralAvailability"`
+	// Availability overrides the general availability for a slot occurrence, keyed by SlotOccurrence.Key()
+	Availability map[string]bool    `bson:"SlotAvailability" firestore:"SlotAvailability"`
+	Bookings     map[string]Booking `bson:"SlotBookings" firestore:"SlotBookings"`
+	// Recurring availability on top of the general availability, and dates the reviewer is away
+	AvailabilityRules []AvailabilityRule `bson:"AvailabilityRules"`
+	OutOfOffice       []DateRange        `bson:"OutOfOffice"`
+	// PendingImport holds the slot occurrences an imported calendar makes unavailable, until the reviewer saves them
+	PendingImport []SlotOccurrence `bson:"PendingImport"`
+	// CalendarToken is the secret in the URL of the reviewer's bookings calendar feed
+	CalendarToken string `bson:"CalendarToken"`
+	// RemindersOff opts the reviewer out of booking reminders and the weekly digest
+	RemindersOff bool `bson:"RemindersOff"`
+	// LastDigestWeek is the Monday ("2006-01-02") of the week the last weekly digest was sent
+	LastDigestWeek string `bson:"LastDigestWeek"`
+	// Paused reviewers are not offered for bookings, up to and including PausedUntil ("2006-01-02") if it is set
+	Paused      bool   `bson:"Paused"`
+	PausedUntil string `bson:"PausedUntil"`
+	// Week keyed ("week-year") schedule, only present for reviewers that are not migrated yet
+	LegacyAvailability map[string][]string `bson:"Availability,omitempty" firestore:"Availability,omitempty"`
+	LegacyBookings     map[string][]string `bson:"Bookings,omitempty" firestore:"Bookings,omitempty"`
 }
 
 func NewReviewer(name string, input map[string]string) Reviewer {
 	id := fmt.Sprintf("%s-%s", name, util.RandomString(8))
 
 	reviewer := Reviewer{
-		ID:           id,
-		SlackID:      input["reviewer_id"],
-		Name:         name,
-		Availability: make(map[string][]string),
-		Bookings:     make(map[string][]string),
+		ID:                  id,
+		SlackID:             input["reviewer_id"],
+		Name:                name,
+		GeneralAvailability: make([]SlotID, 0, 10),
+		Availability:        make(map[string]bool),
+		Bookings:            make(map[string]Booking),
 	}
 	return reviewerFromInput(reviewer, input)
 }
 
 func reviewerFromInput(reviewer Reviewer, input map[string]string) Reviewer {
 	reviewer.GithubAlias = input["github_alias"]
-	reviewer.TechnologyList = input["technology_list"]
-	reviewer.ChallengeID = input["challenge_id"]
+	if timeZone, ok := input["time_zone"]; ok {
+		reviewer.TimeZone = ValidTimeZone(timeZone)
+	}
+	return reviewer.JoinChallenge(membershipFromInput(input))
+}
 
-	experience, err := strconv.Atoi(input["experience"])
+func GetReviewerBySlackID(env config.Environment, slackID string) (Reviewer, error) {
+	reviewer := Reviewer{}
+	store, err := db.NewStore(env, db.ReviewersCollection)
 	if err != nil {
-		log.Println("[ERROR] Experience level not properly encoded, assuming lowest", err)
-		experience = 0
+		return reviewer, err
 	}
-	reviewer.Experience = experience
 
-	bookingsPerWeek, err := strconv.Atoi(input["bookings_week"])
+	err = store.FindFirst("SlackID", slackID, &reviewer)
 	if err != nil {
-		log.Println("[ERROR] Bookings per week not properly encoded, assuming 1", err)
-		bookingsPerWeek = 1
+		return reviewer, err
 	}
-	reviewer.BookingsPerWeek = bookingsPerWeek
-	return reviewer
+	return migrateReviewer(env, reviewer), nil
 }
 
-func GetReviewerBySlackID(env config.Environment, slackID string) (Reviewer, error) {
+func GetReviewerByCalendarToken(env config.Environment, token string) (Reviewer, error) {
 	reviewer := Reviewer{}
+	if token == "" {
+		return reviewer, errors.New("[ERROR] No calendar token")
+	}
 	store, err := db.NewStore(env, db.ReviewersCollection)
 	if err != nil {
 		return reviewer, err
 	}
 
-	err = store.FindFirst("SlackID", slackID, &reviewer)
+	err = store.FindFirst("CalendarToken", token, &reviewer)
+	if err != nil {
+		return reviewer, err
+	}
+	return migrateReviewer(env, reviewer), nil
+}
+
+// EnsureReviewerCalendarToken creates the calendar feed token of the reviewer if there is none yet
+func EnsureReviewerCalendarToken(env config.Environment, reviewer Reviewer) (Reviewer, error) {
+	if reviewer.CalendarToken != "" {
+		return reviewer, nil
+	}
+	reviewer.CalendarToken = util.SecretToken()
+	err := UpdateReviewer(env, reviewer)
 	return reviewer, err
 }
 
@@ -83,6 +128,9 @@ func GetAllReviewers(env config.Environment) ([]Reviewer, error) {
 	if !ok {
 		return nil, errors.New("[ERROR] Cannot convert")
 	}
+	for i, reviewer := range all {
+		all[i] = migrateReviewer(env, reviewer)
+	}
 	return all, err
 }
 
@@ -93,12 +141,22 @@ func GetAllReviewersForChallenge(env config.Environment, challengeID string) ([]
 	}
 
 	var all []Reviewer
-	result, err := store.FindAllWithKeyValue(reflect.TypeOf(all), "ChallengeID", challengeID)
+	result, err := store.FindAll(reflect.TypeOf(all))
 	all, ok := result.([]Reviewer)
 	if !ok {
 		return nil, errors.New("[ERROR] Cannot convert")
 	}
-	return all, err
+
+	// Reviewers can review several challenges, the challenge fields are set to the ones of this challenge
+	members := make([]Reviewer, 0, len(all))
+	for _, reviewer := range all {
+		reviewer, memberErr := migrateReviewer(env, reviewer).ForChallenge(challengeID)
+		if memberErr != nil {
+			continue
+		}
+		members = append(members, reviewer)
+	}
+	return members, err
 }
 
 func EditReviewer(env config.Environment, slackID string, input map[string]string) (Reviewer, error) {
@@ -119,3 +177,36 @@ func UpdateReviewer(env config.Environment, reviewer Reviewer) error {
 	}
 	return store.Update(reviewer.ID, reviewer)
 }
+
+// ChangeReviewer changes the stored reviewer without losing the changes made to them at the same time, e.g. so a
+// slot is not booked twice. change can run more than once, so it should only change the reviewer.
+func ChangeReviewer(env config.Environment, id string, change func(reviewer Reviewer) (Reviewer, error)) (Reviewer, error) {
+	reviewer := Reviewer{}
+	store, err := db.NewStore(env, db.ReviewersCollection)
+	if err != nil {
+		return reviewer, err
+	}
+
+	err = store.Transact(id, &reviewer, func() error {
+		changed, err := change(reviewer)
+		if err != nil {
+			return err
+		}
+		reviewer = changed
+		return nil
+	})
+	return reviewer, err
+}
+
+func DeleteReviewer(env config.Environment, reviewer Reviewer) error {
+	store, err := db.NewStore(env, db.ReviewersCollection)
+	if err != nil {
+		return err
+	}
+	return store.Delete(reviewer.ID)
+}
+
+// IsPausedOn checks if the reviewer is paused on the date ("2006-01-02")
+func (r Reviewer) IsPausedOn(date string) bool {
+	return r.Paused && (r.PausedUntil == "" || date <= r.PausedUntil)
+}
@@ -0,0 +1,157 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+// ChallengeMembership is a challenge the reviewer reviews, with their skills and capacity for that challenge
+type ChallengeMembership struct {
+	ChallengeID     string `bson:"ChallengeID"`
+	TechnologyList  string `bson:"TechnologyList"`
+	Experience      int    `bson:"Experience"`
+	BookingsPerWeek int    `bson:"BookingsPerWeek"`
+	// BookingsPerMonth caps the bookings in a calendar month, 0 for no cap
+	BookingsPerMonth int `bson:"BookingsPerMonth"`
+	// CooldownWeeks is the number of weeks the reviewer is not booked after a fully booked week
+	CooldownWeeks int `bson:"CooldownWeeks"`
+	// Tags are the technologies picked from the vocabulary, see Taxonomy. Empty until the reviewer picks them.
+	Tags []string `bson:"Tags"`
+}
+
+func membershipFromInput(input map[string]string) ChallengeMembership {
+	membership := ChallengeMembership{
+		ChallengeID:    input["challenge_id"],
+		TechnologyList: input["technology_list"],
+	}
+
+	experience, err := strconv.Atoi(input["experience"])
+	if err != nil {
+		log.Println("[ERROR] Experience level not properly encoded, assuming lowest", err)
+		experience = 0
+	}
+	membership.Experience = experience
+
+	bookingsPerWeek, err := strconv.Atoi(input["bookings_week"])
+	if err != nil {
+		log.Println("[ERROR] Bookings per week not properly encoded, assuming 1", err)
+		bookingsPerWeek = 1
+	}
+	membership.BookingsPerWeek = bookingsPerWeek
+
+	// Monthly caps and cool-down are optional
+	membership.BookingsPerMonth, _ = strconv.Atoi(input["bookings_month"])
+	membership.CooldownWeeks, _ = strconv.Atoi(input["cooldown_weeks"])
+	return membership
+}
+
+// ChallengeIDs lists the challenges of the reviewer, the first one is the one they joined first
+func (r Reviewer) ChallengeIDs() []string {
+	ids := make([]string, 0, len(r.Challenges))
+	for _, membership := range r.Challenges {
+		ids = append(ids, membership.ChallengeID)
+	}
+	return ids
+}
+
+func (r Reviewer) IsMember(challengeID string) bool {
+	_, ok := r.membership(challengeID)
+	return ok
+}
+
+func (r Reviewer) membership(challengeID string) (ChallengeMembership, bool) {
+	for _, membership := range r.Challenges {
+		if membership.ChallengeID == challengeID {
+			return membership, true
+		}
+	}
+	return ChallengeMembership{}, false
+}
+
+// ForChallenge sets the challenge fields of the reviewer (ChallengeID, TechnologyList, Tags, Experience and the capacity)
+// to the ones of their membership in the challenge
+func (r Reviewer) ForChallenge(challengeID string) (Reviewer, error) {
+	membership, ok := r.membership(challengeID)
+	if !ok {
+		return r, fmt.Errorf("[ERROR] Reviewer %s is not reviewing the challenge %s", r.Name, challengeID)
+	}
+
+	r.ChallengeID = membership.ChallengeID
+	r.TechnologyList = membership.TechnologyList
+	r.Tags = membership.Tags
+	r.Experience = membership.Experience
+	r.BookingsPerWeek = membership.BookingsPerWeek
+	r.BookingsPerMonth = membership.BookingsPerMonth
+	r.CooldownWeeks = membership.CooldownWeeks
+	return r, nil
+}
+
+// JoinChallenge adds the challenge to the reviewer, or updates the skills and capacity if they already review it.
+// The picked tags are kept as long as the technology list is not changed.
+func (r Reviewer) JoinChallenge(membership ChallengeMembership) Reviewer {
+	challenges := make([]ChallengeMembership, 0, len(r.Challenges)+1)
+	joined := false
+	for _, existing := range r.Challenges {
+		if existing.ChallengeID == membership.ChallengeID {
+			if membership.Tags == nil && membership.TechnologyList == existing.TechnologyList {
+				membership.Tags = existing.Tags
+			}
+			existing = membership
+			joined = true
+		}
+		challenges = append(challenges, existing)
+	}
+	if !joined {
+		challenges = append(challenges, membership)
+	}
+	r.Challenges = challenges
+
+	reviewer, _ := r.ForChallenge(membership.ChallengeID)
+	return reviewer
+}
+
+// LeaveChallenge removes the challenge from the reviewer, reviewers keep at least one challenge
+func (r Reviewer) LeaveChallenge(challengeID string) (Reviewer, error) {
+	if !r.IsMember(challengeID) {
+		return r, fmt.Errorf("[ERROR] Reviewer %s is not reviewing the challenge %s", r.Name, challengeID)
+	}
+	if len(r.Challenges) == 1 {
+		return r, errors.New("[ERROR] Cannot leave the only challenge of the reviewer")
+	}
+
+	challenges := make([]ChallengeMembership, 0, len(r.Challenges)-1)
+	for _, membership := range r.Challenges {
+		if membership.ChallengeID != challengeID {
+			challenges = append(challenges, membership)
+		}
+	}
+	r.Challenges = challenges
+	return r.ForChallenge(challenges[0].ChallengeID)
+}
+
+// DropChallenge removes a deleted challenge from the reviewer. Unlike LeaveChallenge it can be their only challenge,
+// the reviewer is then kept without any.
+func (r Reviewer) DropChallenge(challengeID string) Reviewer {
+	if !r.IsMember(challengeID) || len(r.Challenges) > 1 {
+		reviewer, _ := r.LeaveChallenge(challengeID)
+		return reviewer
+	}
+
+	r.Challenges = []ChallengeMembership{}
+	r.ChallengeID = ""
+	r.TechnologyList = ""
+	r.Tags = nil
+	r.Experience = 0
+	r.BookingsPerWeek = 0
+	r.BookingsPerMonth = 0
+	r.CooldownWeeks = 0
+	return r
+}
+
+// IsForChallenge checks if the booking is for the challenge. Bookings made before reviewers could review
+// several challenges have no challenge and count for all of them.
+func (b Booking) IsForChallenge(challengeID string) bool {
+	return challengeID == "" || b.ChallengeID == "" || b.ChallengeID == challengeID
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoiningAndLeavingChallenges(t *testing.T) {
+	reviewer := NewReviewer("jane", map[string]string{
+		"challenge_id":    "ios-123",
+		"technology_list": "swift",
+		"experience":      "2",
+		"bookings_week":   "2",
+	})
+	assert.Equal(t, []string{"ios-123"}, reviewer.ChallengeIDs())
+
+	reviewer = reviewer.JoinChallenge(ChallengeMembership{ChallengeID: "backend-456", TechnologyList: "go", BookingsPerWeek: 1})
+	assert.Equal(t, []string{"ios-123", "backend-456"}, reviewer.ChallengeIDs())
+	assert.Equal(t, "go", reviewer.TechnologyList)
+	assert.Equal(t, 1, reviewer.BookingsPerWeek)
+
+	// Joining again updates the skills
+	reviewer = reviewer.JoinChallenge(ChallengeMembership{ChallengeID: "ios-123", TechnologyList: "swift, objc", Experience: 2, BookingsPerWeek: 3})
+	assert.Equal(t, 2, len(reviewer.Challenges))
+	ios, err := reviewer.ForChallenge("ios-123")
+	assert.Nil(t, err)
+	assert.Equal(t, "swift, objc", ios.TechnologyList)
+	assert.Equal(t, 3, ios.BookingsPerWeek)
+
+	_, err = reviewer.ForChallenge("android-789")
+	assert.NotNil(t, err)
+
+	reviewer, err = reviewer.LeaveChallenge("ios-123")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"backend-456"}, reviewer.ChallengeIDs())
+	assert.Equal(t, "backend-456", reviewer.ChallengeID)
+
+	_, err = reviewer.LeaveChallenge("backend-456")
+	assert.NotNil(t, err)
+}
+
+func TestDroppingDeletedChallenges(t *testing.T) {
+	reviewer := NewReviewer("jane", map[string]string{
+		"challenge_id":    "ios-123",
+		"technology_list": "swift",
+		"bookings_week":   "2",
+	})
+	reviewer = reviewer.JoinChallenge(ChallengeMembership{ChallengeID: "backend-456", TechnologyList: "go", BookingsPerWeek: 1})
+
+	reviewer = reviewer.DropChallenge("ios-123")
+	assert.Equal(t, []string{"backend-456"}, reviewer.ChallengeIDs())
+	assert.Equal(t, "backend-456", reviewer.ChallengeID)
+
+	// The only challenge can be dropped, and it is not migrated back to a membership
+	reviewer = reviewer.DropChallenge("backend-456")
+	assert.Equal(t, []string{}, reviewer.ChallengeIDs())
+	assert.Equal(t, "", migrateChallengeMemberships(reviewer).ChallengeID)
+	assert.Equal(t, 0, len(migrateChallengeMemberships(reviewer).Challenges))
+}
+
+func TestMigratingToChallengeMemberships(t *testing.T) {
+	reviewer := Reviewer{
+		ChallengeID:     "ios-123",
+		TechnologyList:  "swift",
+		Experience:      1,
+		BookingsPerWeek: 2,
+		Bookings: map[string]Booking{
+			"2020-01-06_MondayMorning": Booking{Occurrence: SlotOccurrence{Date: "2020-01-06", SlotID: "MondayMorning"}},
+		},
+	}
+
+	migrated := migrateChallengeMemberships(reviewer)
+
+	assert.Equal(t, []ChallengeMembership{
+		ChallengeMembership{ChallengeID: "ios-123", TechnologyList: "swift", Experience: 1, BookingsPerWeek: 2},
+	}, migrated.Challenges)
+	assert.Equal(t, "ios-123", migrated.Bookings["2020-01-06_MondayMorning"].ChallengeID)
+}
@@ -0,0 +1,208 @@
+package models
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+)
+
+const legacyGeneralKey = "General"
+
+// migrateReviewer brings reviewers stored by earlier versions up to date, and saves them so they are migrated only once
+func migrateReviewer(env config.Environment, reviewer Reviewer) Reviewer {
+	outdated := isOutdated(reviewer)
+	reviewer = migrateChallengeMemberships(migrateLegacySchedule(env, reviewer))
+	if outdated {
+		err := UpdateReviewer(env, reviewer)
+		if err != nil {
+			log.Println("[ERROR] Cannot save the migrated reviewer - ", reviewer.SlackID, err)
+		}
+	}
+	return reviewer
+}
+
+// isOutdated checks if the reviewer was stored with the week keyed schedule or without challenge memberships
+func isOutdated(reviewer Reviewer) bool {
+	return len(reviewer.LegacyAvailability) > 0 || len(reviewer.LegacyBookings) > 0 ||
+		(len(reviewer.Challenges) == 0 && reviewer.ChallengeID != "")
+}
+
+// migrateChallengeMemberships turns the single challenge of the reviewer into a membership, and sets the challenge of
+// their bookings. The loaded reviewer has the challenge fields of the first challenge.
+func migrateChallengeMemberships(reviewer Reviewer) Reviewer {
+	if len(reviewer.Challenges) == 0 {
+		if reviewer.ChallengeID == "" {
+			return reviewer
+		}
+		reviewer.Challenges = []ChallengeMembership{
+			ChallengeMembership{
+				ChallengeID:      reviewer.ChallengeID,
+				TechnologyList:   reviewer.TechnologyList,
+				Experience:       reviewer.Experience,
+				BookingsPerWeek:  reviewer.BookingsPerWeek,
+				BookingsPerMonth: reviewer.BookingsPerMonth,
+				CooldownWeeks:    reviewer.CooldownWeeks,
+			},
+		}
+		for key, booking := range reviewer.Bookings {
+			if booking.ChallengeID == "" {
+				booking.ChallengeID = reviewer.ChallengeID
+				reviewer.Bookings[key] = booking
+			}
+		}
+	}
+
+	reviewer, _ = reviewer.ForChallenge(reviewer.Challenges[0].ChallengeID)
+	return reviewer
+}
+
+// migrateLegacySchedule converts the week keyed ("week-year") availability and bookings to slot occurrences
+func migrateLegacySchedule(env config.Environment, reviewer Reviewer) Reviewer {
+	if len(reviewer.LegacyAvailability) == 0 && len(reviewer.LegacyBookings) == 0 {
+		return initSchedule(reviewer)
+	}
+
+	var slots map[SlotID]*Slot
+	if reviewer.ChallengeID != "" {
+		challenge, err := getChallengeByID(env, reviewer.ChallengeID)
+		if err != nil {
+			log.Println("[ERROR] Cannot find the challenge to migrate the reviewer schedule, using slot names - ", reviewer.ChallengeID)
+		}
+		slots = challenge.Slots
+	}
+
+	return convertLegacySchedule(reviewer, slots)
+}
+
+func convertLegacySchedule(reviewer Reviewer, slots map[SlotID]*Slot) Reviewer {
+	reviewer = initSchedule(reviewer)
+
+	general := reviewer.LegacyAvailability[legacyGeneralKey]
+	for _, slotID := range general {
+		reviewer.GeneralAvailability = appendSlotID(reviewer.GeneralAvailability, slotID)
+	}
+
+	for weekKey, weekSlots := range reviewer.LegacyAvailability {
+		if weekKey == legacyGeneralKey {
+			continue
+		}
+		weekStart, err := legacyWeekStart(weekKey)
+		if err != nil {
+			log.Println("[ERROR] Dropping availability with invalid week - ", weekKey)
+			continue
+		}
+
+		// A week entry used to replace the general availability for the whole week,
+		// so only the differences are kept as overrides.
+		for _, slotID := range weekSlots {
+			if !containsSlotID(general, slotID) {
+				setLegacyAvailability(reviewer, slots, slotID, weekStart, true)
+			}
+		}
+		for _, slotID := range general {
+			if !containsSlotID(weekSlots, slotID) {
+				setLegacyAvailability(reviewer, slots, slotID, weekStart, false)
+			}
+		}
+	}
+
+	for weekKey, weekSlots := range reviewer.LegacyBookings {
+		weekStart, err := legacyWeekStart(weekKey)
+		if err != nil {
+			log.Println("[ERROR] Dropping bookings with invalid week - ", weekKey)
+			continue
+		}
+		for _, slotID := range weekSlots {
+			occurrence, err := legacySlot(slots, slotID).OccurrenceInWeek(weekStart)
+			if err != nil {
+				log.Println("[ERROR] Dropping booking with unknown slot - ", slotID)
+				continue
+			}
+			reviewer.Bookings[occurrence.Key()] = NewBooking(reviewer.SlackID, occurrence, map[string]string{})
+		}
+	}
+
+	reviewer.LegacyAvailability = nil
+	reviewer.LegacyBookings = nil
+	return reviewer
+}
+
+func initSchedule(reviewer Reviewer) Reviewer {
+	if reviewer.GeneralAvailability == nil {
+		reviewer.GeneralAvailability = make([]SlotID, 0, 10)
+	}
+	if reviewer.Availability == nil {
+		reviewer.Availability = make(map[string]bool)
+	}
+	if reviewer.Bookings == nil {
+		reviewer.Bookings = make(map[string]Booking)
+	}
+	return reviewer
+}
+
+func setLegacyAvailability(reviewer Reviewer, slots map[SlotID]*Slot, slotID SlotID, weekStart time.Time, available bool) {
+	occurrence, err := legacySlot(slots, slotID).OccurrenceInWeek(weekStart)
+	if err != nil {
+		log.Println("[ERROR] Dropping availability with unknown slot - ", slotID)
+		return
+	}
+	reviewer.Availability[occurrence.Key()] = available
+}
+
+// legacySlot looks up the slot definition, falling back to the day prefix of the default slot IDs (e.g. "MondayMorning")
+func legacySlot(slots map[SlotID]*Slot, slotID SlotID) Slot {
+	if slot, ok := slots[slotID]; ok && slot != nil {
+		return *slot
+	}
+
+	slot := Slot{ID: slotID, StartTime: "0:00", EndTime: "0:00"}
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.HasPrefix(slotID, day.String()) {
+			slot.Day = day.String()
+		}
+	}
+	return slot
+}
+
+// legacyWeekStart decodes the "week-year" keys, where week is the ISO-8601 week number
+func legacyWeekStart(weekKey string) (time.Time, error) {
+	s := strings.Split(weekKey, "-")
+	if len(s) != 2 {
+		return time.Time{}, strconv.ErrSyntax
+	}
+	week, err := strconv.Atoi(s[0])
+	if err != nil {
+		return time.Time{}, err
+	}
+	year, err := strconv.Atoi(s[1])
+	if err != nil {
+		return time.Time{}, err
+	}
+	if week < 1 || week > 53 {
+		return time.Time{}, strconv.ErrRange
+	}
+
+	// January 4th is always in the first ISO week
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	monday := jan4.AddDate(0, 0, -((int(jan4.Weekday()) + 6) % 7))
+	return monday.AddDate(0, 0, 7*(week-1)), nil
+}
+
+func containsSlotID(slotIDs []SlotID, slotID SlotID) bool {
+	for _, id := range slotIDs {
+		if id == slotID {
+			return true
+		}
+	}
+	return false
+}
+
+func appendSlotID(slotIDs []SlotID, slotID SlotID) []SlotID {
+	if containsSlotID(slotIDs, slotID) {
+		return slotIDs
+	}
+	return append(slotIDs, slotID)
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertingLegacySchedule(t *testing.T) {
+	reviewer := Reviewer{
+		SlackID: "U123",
+		LegacyAvailability: map[string][]string{
+			"General": []string{"MondayMorning", "TuesdayAfternoon"},
+			"1-2020":  []string{"MondayMorning", "FridayMorning"},
+		},
+		LegacyBookings: map[string][]string{
+			"53-2020": []string{"TuesdayAfternoon"},
+		},
+	}
+
+	migrated := convertLegacySchedule(reviewer, defaultSlots("", "Europe/Berlin"))
+
+	assert.Equal(t, []SlotID{"MondayMorning", "TuesdayAfternoon"}, migrated.GeneralAvailability)
+	assert.Equal(t, map[string]bool{
+		"2020-01-03_FridayMorning":    true,
+		"2019-12-31_TuesdayAfternoon": false,
+	}, migrated.Availability)
+	assert.Equal(t, 1, len(migrated.Bookings))
+	booking := migrated.Bookings["2020-12-29_TuesdayAfternoon"]
+	assert.Equal(t, "U123", booking.ReviewerID)
+	assert.Equal(t, SlotOccurrence{Date: "2020-12-29", SlotID: "TuesdayAfternoon"}, booking.Occurrence)
+	assert.NotEqual(t, "", booking.ID)
+	assert.Nil(t, migrated.LegacyAvailability)
+	assert.Nil(t, migrated.LegacyBookings)
+}
+
+func TestOutdatedReviewers(t *testing.T) {
+	assert.True(t, isOutdated(Reviewer{LegacyAvailability: map[string][]string{"General": []string{"MondayMorning"}}}))
+	assert.True(t, isOutdated(Reviewer{LegacyBookings: map[string][]string{"1-2020": []string{"MondayMorning"}}}))
+	assert.True(t, isOutdated(Reviewer{ChallengeID: "ios-123"}))
+
+	migrated := migrateChallengeMemberships(convertLegacySchedule(Reviewer{
+		ChallengeID:        "ios-123",
+		LegacyAvailability: map[string][]string{"General": []string{"MondayMorning"}},
+	}, nil))
+	assert.False(t, isOutdated(migrated))
+	assert.False(t, isOutdated(Reviewer{}))
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/db"
+)
+
+// The sent reminders are kept until their bookings and digest weeks are over
+const sentReminderKeep = 8 * 24 * time.Hour
+
+// SentReminder marks a reminder or digest as sent, so it goes out once even if the scheduler runs it again or
+// several instances run it. A Firestore TTL policy on ExpiresAt deletes the old ones.
+type SentReminder struct {
+	ID        string    `bson:"ID"`
+	SentAt    time.Time `bson:"SentAt"`
+	ExpiresAt time.Time `bson:"ExpiresAt"`
+}
+
+// ClaimReminder marks the reminder as sent, and returns false if it was already sent
+func ClaimReminder(env config.Environment, key string, now time.Time) (bool, error) {
+	store, err := db.NewStore(env, db.RemindersCollection)
+	if err != nil {
+		return false, err
+	}
+	return claimReminder(store, key, now)
+}
+
+// ReleaseReminder removes the mark of a reminder that could not be sent, to send it the next time
+func ReleaseReminder(env config.Environment, key string) error {
+	store, err := db.NewStore(env, db.RemindersCollection)
+	if err != nil {
+		return err
+	}
+	return store.Delete(key)
+}
+
+// reminderStore is the part of the store that marks the reminders
+type reminderStore interface {
+	Create(key string, obj interface{}) error
+}
+
+func claimReminder(store reminderStore, key string, now time.Time) (bool, error) {
+	sent := SentReminder{
+		ID:        key,
+		SentAt:    now,
+		ExpiresAt: now.Add(sentReminderKeep),
+	}
+	err := store.Create(key, sent)
+	if err == db.ErrExists {
+		return false, nil
+	}
+	return err == nil, err
+}
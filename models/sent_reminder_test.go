@@ -0,0 +1,36 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keremk/challenge-bot/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryReminderStore map[string]SentReminder
+
+func (m memoryReminderStore) Create(key string, obj interface{}) error {
+	if _, ok := m[key]; ok {
+		return db.ErrExists
+	}
+	m[key] = obj.(SentReminder)
+	return nil
+}
+
+func TestClaimReminder(t *testing.T) {
+	store := make(memoryReminderStore)
+	now := time.Date(2020, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	claimed, err := claimReminder(store, "abc123-day", now)
+	assert.Nil(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, now.Add(sentReminderKeep), store["abc123-day"].ExpiresAt)
+
+	claimed, err = claimReminder(store, "abc123-day", now.Add(5*time.Minute))
+	assert.Nil(t, err)
+	assert.False(t, claimed)
+
+	claimed, _ = claimReminder(store, "abc123-hour", now.Add(5*time.Minute))
+	assert.True(t, claimed)
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/db"
+)
+
+// Slack retries an event 3 times within about 5 minutes when the response is late or fails, the handled events
+// are kept a while longer to skip the retries
+const eventDedupeWindow = 10 * time.Minute
+
+// SlackEvent is an event of the Events API that is handled. It is stored, so the retries are skipped whichever
+// instance of the app gets them. A Firestore TTL policy on ExpiresAt deletes the old ones.
+type SlackEvent struct {
+	ID        string    `bson:"ID"`
+	HandledAt time.Time `bson:"HandledAt"`
+	ExpiresAt time.Time `bson:"ExpiresAt"`
+}
+
+// eventStore is the part of the store that records the events
+type eventStore interface {
+	Create(key string, obj interface{}) error
+	FindByID(id string, obj interface{}) error
+	Update(key string, obj interface{}) error
+}
+
+// RecordSlackEvent records the event as handled, and checks if it was handled before
+func RecordSlackEvent(env config.Environment, eventID string, now time.Time) (bool, error) {
+	store, err := db.NewStore(env, db.EventsCollection)
+	if err != nil {
+		return false, err
+	}
+	return recordEvent(store, eventID, now)
+}
+
+func recordEvent(store eventStore, eventID string, now time.Time) (bool, error) {
+	if eventID == "" {
+		return false, nil
+	}
+	event := SlackEvent{
+		ID:        eventID,
+		HandledAt: now,
+		ExpiresAt: now.Add(eventDedupeWindow),
+	}
+	err := store.Create(eventID, event)
+	if err != db.ErrExists {
+		return false, err
+	}
+
+	var handled SlackEvent
+	err = store.FindByID(eventID, &handled)
+	if err != nil {
+		return false, err
+	}
+	// The TTL policy deletes the expired events some time after they expire
+	if now.Before(handled.ExpiresAt) {
+		return true, nil
+	}
+	return false, store.Update(eventID, event)
+}
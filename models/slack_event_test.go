@@ -0,0 +1,54 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keremk/challenge-bot/db"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryEventStore map[string]SlackEvent
+
+func (m memoryEventStore) Create(key string, obj interface{}) error {
+	if _, ok := m[key]; ok {
+		return db.ErrExists
+	}
+	m[key] = obj.(SlackEvent)
+	return nil
+}
+
+func (m memoryEventStore) FindByID(id string, obj interface{}) error {
+	*obj.(*SlackEvent) = m[id]
+	return nil
+}
+
+func (m memoryEventStore) Update(key string, obj interface{}) error {
+	m[key] = obj.(SlackEvent)
+	return nil
+}
+
+func TestRecordEvent(t *testing.T) {
+	store := make(memoryEventStore)
+	now := time.Date(2020, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	handled, err := recordEvent(store, "Ev1", now)
+	assert.Nil(t, err)
+	assert.False(t, handled)
+
+	handled, _ = recordEvent(store, "Ev1", now.Add(5*time.Minute))
+	assert.True(t, handled)
+
+	handled, _ = recordEvent(store, "Ev2", now.Add(5*time.Minute))
+	assert.False(t, handled)
+
+	// Not deleted yet by the TTL policy, but expired
+	handled, _ = recordEvent(store, "Ev1", now.Add(eventDedupeWindow+time.Second))
+	assert.False(t, handled)
+	assert.Equal(t, now.Add(2*eventDedupeWindow+time.Second), store["Ev1"].ExpiresAt)
+
+	handled, _ = recordEvent(store, "", now)
+	assert.False(t, handled)
+	handled, _ = recordEvent(store, "", now)
+	assert.False(t, handled)
+}
@@ -0,0 +1,196 @@
+package models
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/util"
+)
+
+// slotRenames maps the old slot IDs to the new ones, by challenge ID
+type slotRenames map[string]map[SlotID]SlotID
+
+// MigrateSlotIDs renames the slots that were created before the slot IDs were unique across challenges, along with
+// the schedules of the reviewers, the pairings of the candidates and the current needs that refer to them
+func MigrateSlotIDs(env config.Environment) error {
+	// Loading the reviewers converts their week keyed schedules, which use the slot IDs before they are renamed
+	reviewers, err := GetAllReviewers(env)
+	if err != nil {
+		return err
+	}
+	challenges, err := GetAllChallenges(env)
+	if err != nil {
+		return err
+	}
+
+	renames := make(slotRenames)
+	for _, challenge := range challenges {
+		challenge, renamed := renameSlots(challenge)
+		if len(renamed) == 0 {
+			continue
+		}
+		renames[challenge.ID] = renamed
+		log.Printf("[INFO] Renaming %d slots of the challenge %s", len(renamed), challenge.Name)
+		err = UpdateChallenge(env, challenge)
+		if err != nil {
+			return err
+		}
+	}
+	if len(renames) == 0 {
+		return nil
+	}
+
+	for _, reviewer := range reviewers {
+		reviewer, changed := renameReviewerSlots(reviewer, renames)
+		if !changed {
+			continue
+		}
+		err = UpdateReviewer(env, reviewer)
+		if err != nil {
+			return err
+		}
+	}
+
+	candidates, err := GetAllCandidates(env)
+	if err != nil {
+		return err
+	}
+	for _, candidate := range candidates {
+		newID, ok := renames[candidate.ChallengeID][candidate.Pairing.SlotID]
+		if !ok {
+			continue
+		}
+		candidate.Pairing.SlotID = newID
+		err = UpdateCandidate(env, candidate)
+		if err != nil {
+			return err
+		}
+	}
+
+	needs, err := GetCurrentNeeds(env, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, need := range needs {
+		renamed := renames[need.ChallengeID]
+		if len(renamed) == 0 {
+			continue
+		}
+		for i, booked := range need.Booked {
+			if newID, ok := renamed[booked.Occurrence.SlotID]; ok {
+				need.Booked[i].Occurrence.SlotID = newID
+			}
+		}
+		for i, offer := range need.Offers {
+			if newID, ok := renamed[offer.Occurrence.SlotID]; ok {
+				need.Offers[i].Occurrence.SlotID = newID
+			}
+		}
+		err = UpdateNeed(env, need)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renameSlots gives the slots without the suffix of the challenge a new ID
+func renameSlots(challenge Challenge) (Challenge, map[SlotID]SlotID) {
+	suffix := slotIDSuffix(challenge.ID)
+	renamed := make(map[SlotID]SlotID)
+	slots := make(map[SlotID]*Slot, len(challenge.Slots))
+	for slotID, slot := range challenge.Slots {
+		if !strings.HasSuffix(slotID, suffix) {
+			renamed[slotID] = ""
+			continue
+		}
+		slots[slotID] = slot
+	}
+
+	for _, slot := range slotsInOrder(challenge.Slots) {
+		if _, ok := renamed[slot.ID]; !ok {
+			continue
+		}
+		newID := uniqueSlotID(Challenge{ID: challenge.ID, Slots: slots}, slot.ID)
+		renamed[slot.ID] = newID
+		slot.ID = newID
+		slots[newID] = slot
+	}
+	challenge.Slots = slots
+	return challenge, renamed
+}
+
+// renameReviewerSlots renames the slots in the schedule of the reviewer. The availability of a slot that several
+// challenges of the reviewer had is kept for each of them.
+func renameReviewerSlots(reviewer Reviewer, renames slotRenames) (Reviewer, bool) {
+	changed := false
+	newIDs := func(slotID SlotID) []SlotID {
+		ids := make([]SlotID, 0, 1)
+		for _, challengeID := range reviewer.ChallengeIDs() {
+			if newID, ok := renames[challengeID][slotID]; ok {
+				ids = append(ids, newID)
+			}
+		}
+		if len(ids) == 0 {
+			return []SlotID{slotID}
+		}
+		changed = true
+		return ids
+	}
+
+	general := make([]SlotID, 0, len(reviewer.GeneralAvailability))
+	for _, slotID := range reviewer.GeneralAvailability {
+		for _, newID := range newIDs(slotID) {
+			general = appendSlotID(general, newID)
+		}
+	}
+	reviewer.GeneralAvailability = general
+
+	availability := make(map[string]bool, len(reviewer.Availability))
+	for key, available := range reviewer.Availability {
+		occurrence, err := ParseSlotOccurrence(key)
+		if err != nil {
+			availability[key] = available
+			continue
+		}
+		for _, newID := range newIDs(occurrence.SlotID) {
+			availability[SlotOccurrence{Date: occurrence.Date, SlotID: newID}.Key()] = available
+		}
+	}
+	reviewer.Availability = availability
+
+	// Bookings are for one challenge
+	bookings := make(map[string]Booking, len(reviewer.Bookings))
+	for key, booking := range reviewer.Bookings {
+		if newID, ok := renames[booking.ChallengeID][booking.Occurrence.SlotID]; ok {
+			booking.Occurrence.SlotID = newID
+			key = booking.Occurrence.Key()
+			changed = true
+		}
+		bookings[key] = booking
+	}
+	reviewer.Bookings = bookings
+
+	rules := make([]AvailabilityRule, 0, len(reviewer.AvailabilityRules))
+	for _, rule := range reviewer.AvailabilityRules {
+		for i, newID := range newIDs(rule.SlotID) {
+			if i > 0 {
+				rule.ID = util.RandomString(8)
+			}
+			rule.SlotID = newID
+			rules = append(rules, rule)
+		}
+	}
+	reviewer.AvailabilityRules = rules
+
+	pending := make([]SlotOccurrence, 0, len(reviewer.PendingImport))
+	for _, occurrence := range reviewer.PendingImport {
+		for _, newID := range newIDs(occurrence.SlotID) {
+			pending = append(pending, SlotOccurrence{Date: occurrence.Date, SlotID: newID})
+		}
+	}
+	reviewer.PendingImport = pending
+	return reviewer, changed
+}
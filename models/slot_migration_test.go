@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenamingSlots(t *testing.T) {
+	suffix := slotIDSuffix("ios-123")
+	challenge := Challenge{ID: "ios-123", Slots: map[SlotID]*Slot{
+		"MondayMorning":           &Slot{ID: "MondayMorning", Ordinal: 0, Day: "Monday"},
+		"Monday0900" + suffix:     &Slot{ID: "Monday0900" + suffix, Ordinal: 1, Day: "Monday"},
+		"Monday0900":              &Slot{ID: "Monday0900", Ordinal: 2, Day: "Monday"},
+		"TuesdayMorning" + suffix: &Slot{ID: "TuesdayMorning" + suffix, Ordinal: 3, Day: "Tuesday"},
+	}}
+
+	challenge, renamed := renameSlots(challenge)
+	assert.Equal(t, map[SlotID]SlotID{
+		"MondayMorning": "MondayMorning" + suffix,
+		"Monday0900":    "Monday09002" + suffix,
+	}, renamed)
+	assert.Equal(t, 4, len(challenge.Slots))
+	assert.Equal(t, "Monday09002"+suffix, challenge.Slots["Monday09002"+suffix].ID)
+	assert.Equal(t, 2, challenge.Slots["Monday09002"+suffix].Ordinal)
+
+	_, renamed = renameSlots(challenge)
+	assert.Equal(t, 0, len(renamed))
+}
+
+func TestRenamingReviewerSlots(t *testing.T) {
+	renames := slotRenames{
+		"ios-123":     map[SlotID]SlotID{"MondayMorning": "MondayMorningaaaa"},
+		"android-456": map[SlotID]SlotID{"MondayMorning": "MondayMorningbbbb"},
+	}
+	booking := Booking{ChallengeID: "android-456", Occurrence: SlotOccurrence{Date: "2020-01-06", SlotID: "MondayMorning"}}
+	reviewer := Reviewer{
+		Challenges: []ChallengeMembership{
+			ChallengeMembership{ChallengeID: "ios-123"},
+			ChallengeMembership{ChallengeID: "android-456"},
+		},
+		GeneralAvailability: []SlotID{"MondayMorning", "FridayMorning"},
+		Availability:        map[string]bool{"2020-01-13_MondayMorning": false},
+		Bookings:            map[string]Booking{booking.Occurrence.Key(): booking},
+		AvailabilityRules:   []AvailabilityRule{AvailabilityRule{ID: "rule1", SlotID: "MondayMorning", Interval: 2}},
+	}
+
+	reviewer, changed := renameReviewerSlots(reviewer, renames)
+	assert.True(t, changed)
+	assert.Equal(t, []SlotID{"MondayMorningaaaa", "MondayMorningbbbb", "FridayMorning"}, reviewer.GeneralAvailability)
+	assert.Equal(t, map[string]bool{
+		"2020-01-13_MondayMorningaaaa": false,
+		"2020-01-13_MondayMorningbbbb": false,
+	}, reviewer.Availability)
+	assert.Equal(t, 1, len(reviewer.Bookings))
+	assert.Equal(t, "MondayMorningbbbb", reviewer.Bookings["2020-01-06_MondayMorningbbbb"].Occurrence.SlotID)
+	assert.Equal(t, 2, len(reviewer.AvailabilityRules))
+	assert.Equal(t, "rule1", reviewer.AvailabilityRules[0].ID)
+	assert.NotEqual(t, "rule1", reviewer.AvailabilityRules[1].ID)
+
+	_, changed = renameReviewerSlots(reviewer, renames)
+	assert.False(t, changed)
+}
@@ -0,0 +1,270 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/db"
+)
+
+// Scores of a reviewer tag for a searched technology
+const (
+	exactTagMatch    = 1.0
+	narrowerTagMatch = 0.75
+	broaderTagMatch  = 0.5
+)
+
+// Tag is a technology of the managed vocabulary. Parent is the ID of the broader technology, e.g. kotlin has the
+// parent android.
+type Tag struct {
+	ID      string   `bson:"ID"`
+	Name    string   `bson:"Name"`
+	Aliases []string `bson:"Aliases"`
+	Parent  string   `bson:"Parent"`
+}
+
+// Taxonomy is the tag vocabulary keyed by tag ID
+type Taxonomy map[string]Tag
+
+// TagID is the case insensitive ID of a tag name, e.g. "Ruby on Rails" is "ruby_on_rails". The ID is the Firestore
+// document ID of the tag, so a "/" separates words too, e.g. "CI/CD" is "ci_cd", and "." or "__name__" are not IDs.
+func TagID(name string) string {
+	name = strings.NewReplacer(",", " ", "/", " ").Replace(name)
+	id := strings.Trim(strings.ToLower(strings.Join(strings.Fields(name), "_")), "_")
+	if id == "." || id == ".." {
+		return ""
+	}
+	return id
+}
+
+func NewTag(input map[string]string) (Tag, error) {
+	name := strings.TrimSpace(input["tag_name"])
+	if TagID(name) == "" {
+		return Tag{}, errors.New("[ERROR] Tag needs a name")
+	}
+
+	aliases := make([]string, 0)
+	for _, alias := range strings.Split(input["aliases"], ",") {
+		if id := TagID(alias); id != "" {
+			aliases = append(aliases, id)
+		}
+	}
+	return Tag{
+		ID:      TagID(name),
+		Name:    name,
+		Aliases: aliases,
+		Parent:  TagID(input["parent"]),
+	}, nil
+}
+
+func GetTaxonomy(env config.Environment) (Taxonomy, error) {
+	store, err := db.NewStore(env, db.TagsCollection)
+	if err != nil {
+		return Taxonomy{}, err
+	}
+
+	var all []Tag
+	result, err := store.FindAll(reflect.TypeOf(all))
+	all, ok := result.([]Tag)
+	if !ok {
+		return Taxonomy{}, errors.New("[ERROR] Cannot convert")
+	}
+
+	taxonomy := make(Taxonomy, len(all))
+	for _, tag := range all {
+		taxonomy[tag.ID] = tag
+	}
+	return taxonomy, err
+}
+
+func UpdateTag(env config.Environment, tag Tag) error {
+	store, err := db.NewStore(env, db.TagsCollection)
+	if err != nil {
+		return err
+	}
+	return store.Update(tag.ID, tag)
+}
+
+// Check validates a new or edited tag against the vocabulary: the parent exists without making a cycle and the
+// aliases are not used by other tags
+func (t Taxonomy) Check(tag Tag) error {
+	if tag.Parent != "" {
+		if _, ok := t[tag.Parent]; !ok {
+			return fmt.Errorf("[ERROR] Unknown parent tag - %s", tag.Parent)
+		}
+		if tag.Parent == tag.ID || t.IsAncestor(tag.ID, tag.Parent) {
+			return fmt.Errorf("[ERROR] Tag %s cannot be its own ancestor", tag.Name)
+		}
+	}
+
+	for _, alias := range tag.Aliases {
+		if id, ok := t.Resolve(alias); ok && id != tag.ID {
+			return fmt.Errorf("[ERROR] Alias %s is already used by the tag %s", alias, t[id].Name)
+		}
+	}
+	return nil
+}
+
+// Resolve finds the tag of a name or alias, case insensitive
+func (t Taxonomy) Resolve(name string) (string, bool) {
+	id := TagID(name)
+	if _, ok := t[id]; ok {
+		return id, true
+	}
+	for _, tag := range t {
+		for _, alias := range tag.Aliases {
+			if alias == id {
+				return tag.ID, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Ancestors lists the broader tags of the tag, the parent first
+func (t Taxonomy) Ancestors(id string) []string {
+	ancestors := make([]string, 0)
+	visited := map[string]bool{id: true}
+	for parent := t[id].Parent; parent != "" && !visited[parent]; parent = t[parent].Parent {
+		visited[parent] = true
+		ancestors = append(ancestors, parent)
+	}
+	return ancestors
+}
+
+func (t Taxonomy) IsAncestor(ancestor, id string) bool {
+	for _, parent := range t.Ancestors(id) {
+		if parent == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseTags finds the tags in a comma separated technology list. Entries that are not in the vocabulary are tried
+// word by word, the words that are still unknown are returned separately.
+func (t Taxonomy) ParseTags(technologyList string) ([]string, []string) {
+	tags := make([]string, 0)
+	unknown := make([]string, 0)
+	for _, entry := range strings.Split(technologyList, ",") {
+		if id, ok := t.Resolve(entry); ok {
+			tags = appendTag(tags, id)
+			continue
+		}
+		for _, word := range strings.Fields(entry) {
+			if id, ok := t.Resolve(word); ok {
+				tags = appendTag(tags, id)
+			} else {
+				unknown = appendTag(unknown, TagID(word))
+			}
+		}
+	}
+	return tags, unknown
+}
+
+// TagsOf returns the tags of the reviewer for their current challenge. Reviewers that did not pick tags yet have the
+// tags of their technology list, with the unknown technologies as they are so they still match exactly.
+func (t Taxonomy) TagsOf(reviewer Reviewer) []string {
+	if len(reviewer.Tags) > 0 {
+		return reviewer.Tags
+	}
+	tags, unknown := t.ParseTags(reviewer.TechnologyList)
+	return append(tags, unknown...)
+}
+
+// MatchScore is 1 if one of the tags is the technology, less if a tag is narrower (kotlin for android) or broader
+// (android for kotlin) and 0 if none of them is related
+func (t Taxonomy) MatchScore(tags []string, technology string) float64 {
+	if strings.TrimSpace(technology) == "" {
+		return exactTagMatch
+	}
+	wanted, ok := t.Resolve(technology)
+	if !ok {
+		wanted = TagID(technology)
+	}
+
+	score := 0.0
+	for _, tag := range tags {
+		switch {
+		case tag == wanted:
+			return exactTagMatch
+		case t.IsAncestor(wanted, tag):
+			score = maxScore(score, narrowerTagMatch)
+		case t.IsAncestor(tag, wanted):
+			score = maxScore(score, broaderTagMatch)
+		}
+	}
+	return score
+}
+
+// Names are the display names of the tags, IDs that are not in the vocabulary are kept as they are
+func (t Taxonomy) Names(ids []string) []string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if tag, ok := t[id]; ok {
+			names = append(names, tag.Name)
+		} else {
+			names = append(names, id)
+		}
+	}
+	return names
+}
+
+// Ordered lists the tags by name, with the narrower tags right after their parent
+func (t Taxonomy) Ordered() []Tag {
+	paths := make(map[string]string, len(t))
+	for id, tag := range t {
+		path := strings.ToLower(tag.Name)
+		for _, ancestor := range t.Ancestors(id) {
+			path = strings.ToLower(t[ancestor].Name) + "/" + path
+		}
+		paths[id] = path
+	}
+
+	tags := make([]Tag, 0, len(t))
+	for _, tag := range t {
+		tags = append(tags, tag)
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return paths[tags[i].ID] < paths[tags[j].ID] })
+	return tags
+}
+
+// Root is the broadest ancestor of the tag, or the tag itself
+func (t Taxonomy) Root(id string) string {
+	ancestors := t.Ancestors(id)
+	if len(ancestors) == 0 {
+		return id
+	}
+	return ancestors[len(ancestors)-1]
+}
+
+// SetTags sets the tags of the reviewer for the challenge, the technology list becomes the names of the tags
+func (r Reviewer) SetTags(challengeID string, tags []string, taxonomy Taxonomy) (Reviewer, error) {
+	membership, ok := r.membership(challengeID)
+	if !ok {
+		return r, fmt.Errorf("[ERROR] Reviewer %s is not reviewing the challenge %s", r.Name, challengeID)
+	}
+	membership.Tags = tags
+	membership.TechnologyList = strings.Join(taxonomy.Names(tags), ", ")
+	return r.JoinChallenge(membership), nil
+}
+
+func appendTag(tags []string, id string) []string {
+	for _, tag := range tags {
+		if tag == id {
+			return tags
+		}
+	}
+	return append(tags, id)
+}
+
+func maxScore(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
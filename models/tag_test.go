@@ -0,0 +1,93 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTaxonomy() Taxonomy {
+	return Taxonomy{
+		"android":    Tag{ID: "android", Name: "Android"},
+		"kotlin":     Tag{ID: "kotlin", Name: "Kotlin", Aliases: []string{"kt"}, Parent: "android"},
+		"java":       Tag{ID: "java", Name: "Java"},
+		"javascript": Tag{ID: "javascript", Name: "JavaScript", Aliases: []string{"js"}},
+		"go":         Tag{ID: "go", Name: "Go", Aliases: []string{"golang"}},
+	}
+}
+
+func TestResolveTags(t *testing.T) {
+	taxonomy := testTaxonomy()
+
+	id, ok := taxonomy.Resolve("Golang")
+	assert.True(t, ok)
+	assert.Equal(t, "go", id)
+
+	id, ok = taxonomy.Resolve(" KT ")
+	assert.True(t, ok)
+	assert.Equal(t, "kotlin", id)
+
+	_, ok = taxonomy.Resolve("Rust")
+	assert.False(t, ok)
+
+	tags, unknown := taxonomy.ParseTags("Go, JS rust")
+	assert.Equal(t, []string{"go", "javascript"}, tags)
+	assert.Equal(t, []string{"rust"}, unknown)
+}
+
+func TestMatchScore(t *testing.T) {
+	taxonomy := testTaxonomy()
+
+	assert.Equal(t, 1.0, taxonomy.MatchScore([]string{"go"}, "Go"))
+	assert.Equal(t, 0.0, taxonomy.MatchScore([]string{"javascript"}, "Java"))
+	assert.Equal(t, 0.75, taxonomy.MatchScore([]string{"kotlin"}, "android"))
+	assert.Equal(t, 0.5, taxonomy.MatchScore([]string{"android"}, "kotlin"))
+	assert.Equal(t, 1.0, taxonomy.MatchScore([]string{"java"}, ""))
+
+	// Technologies that are not in the vocabulary still match exactly, case insensitive
+	reviewer := Reviewer{TechnologyList: "Rust, Kotlin"}
+	assert.Equal(t, []string{"kotlin", "rust"}, taxonomy.TagsOf(reviewer))
+	assert.Equal(t, 1.0, taxonomy.MatchScore(taxonomy.TagsOf(reviewer), "rust"))
+	assert.Equal(t, 0.0, taxonomy.MatchScore(taxonomy.TagsOf(reviewer), "rus"))
+}
+
+func TestCheckTag(t *testing.T) {
+	taxonomy := testTaxonomy()
+
+	assert.Nil(t, taxonomy.Check(Tag{ID: "jetpack", Name: "Jetpack", Parent: "kotlin"}))
+	assert.NotNil(t, taxonomy.Check(Tag{ID: "swift", Name: "Swift", Parent: "ios"}))
+	assert.NotNil(t, taxonomy.Check(Tag{ID: "android", Name: "Android", Parent: "kotlin"}))
+	assert.NotNil(t, taxonomy.Check(Tag{ID: "typescript", Name: "TypeScript", Aliases: []string{"js"}}))
+}
+
+func TestSetTagsKeepsThemUntilTheListChanges(t *testing.T) {
+	taxonomy := testTaxonomy()
+	reviewer := Reviewer{}.JoinChallenge(ChallengeMembership{ChallengeID: "android-1", TechnologyList: "kotlin"})
+
+	reviewer, err := reviewer.SetTags("android-1", []string{"kotlin", "java"}, taxonomy)
+	assert.Nil(t, err)
+	assert.Equal(t, "Kotlin, Java", reviewer.TechnologyList)
+
+	reviewer = reviewer.JoinChallenge(ChallengeMembership{ChallengeID: "android-1", TechnologyList: "Kotlin, Java", BookingsPerWeek: 2})
+	assert.Equal(t, []string{"kotlin", "java"}, reviewer.Tags)
+
+	reviewer = reviewer.JoinChallenge(ChallengeMembership{ChallengeID: "android-1", TechnologyList: "Go"})
+	assert.Nil(t, reviewer.Tags)
+}
+
+func TestTagIDs(t *testing.T) {
+	assert.Equal(t, "ruby_on_rails", TagID(" Ruby on  Rails "))
+	assert.Equal(t, "ci_cd", TagID("CI/CD"))
+	assert.Equal(t, "c++", TagID("C++"))
+	assert.Equal(t, "objective-c", TagID("Objective-C"))
+	assert.Equal(t, "init", TagID("__init__"))
+	assert.Equal(t, "", TagID(".."))
+	assert.Equal(t, "", TagID(" / "))
+
+	_, err := NewTag(map[string]string{"tag_name": "/"})
+	assert.NotNil(t, err)
+	tag, err := NewTag(map[string]string{"tag_name": "CI/CD", "aliases": "continuous delivery, cd/ci"})
+	assert.Nil(t, err)
+	assert.Equal(t, "ci_cd", tag.ID)
+	assert.Equal(t, []string{"continuous_delivery", "cd_ci"}, tag.Aliases)
+}
@@ -10,6 +10,8 @@ type SlackTeam struct {
 	Name      string `bson:"Name"`
 	BotToken  string `bson:"BotToken"`
 	BotUserID string `bson:"BotUserID"`
+	// Inactive teams uninstalled the app or revoked its token, until they install it again
+	Inactive bool `bson:"Inactive"`
 }
 
 func GetSlackTeam(env config.Environment, id string) (SlackTeam, error) {
@@ -33,6 +35,17 @@ func UpdateSlackTeam(env config.Environment, team SlackTeam) error {
 	return store.Update(teamID, team)
 }
 
+// DeactivateSlackTeam purges the bot token of the team that uninstalled the app or revoked its token
+func DeactivateSlackTeam(env config.Environment, id string) error {
+	team, err := GetSlackTeam(env, id)
+	if err != nil {
+		return err
+	}
+	team.BotToken = ""
+	team.Inactive = true
+	return UpdateSlackTeam(env, team)
+}
+
 // This is for testing reasons
 // In our test Slack app (ChallengeTest) we always use the hardcoded "ADMIN" as team ID to
 // ensure we are not messing up with the production DB.
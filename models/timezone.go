@@ -0,0 +1,35 @@
+package models
+
+import (
+	"log"
+	"time"
+)
+
+const DefaultTimeZone = "UTC"
+
+// LoadLocation resolves an IANA time zone name (as used in Slack profiles, e.g. "Europe/Berlin").
+// Empty or unknown names fall back to UTC, so rendering never fails because of a bad zone.
+func LoadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("[ERROR] Unknown time zone %s, assuming UTC - %s", name, err)
+		return time.UTC
+	}
+	return loc
+}
+
+// ValidTimeZone returns the name if it is a known IANA time zone, otherwise the default time zone.
+func ValidTimeZone(name string) string {
+	if name == "" {
+		return DefaultTimeZone
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		log.Printf("[ERROR] Unknown time zone %s, assuming %s", name, DefaultTimeZone)
+		return DefaultTimeZone
+	}
+	return name
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlotInterval(t *testing.T) {
+	slot := Slot{ID: "Wednesday1000", Day: "Wednesday", StartTime: "10:00", EndTime: "11:30", TimeZone: "Europe/Berlin"}
+	weekStart := time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	start, end, err := slot.Interval(weekStart)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2019, 7, 3, 8, 0, 0, 0, time.UTC), start.UTC())
+	assert.Equal(t, time.Date(2019, 7, 3, 9, 30, 0, 0, time.UTC), end.UTC())
+
+	// Sunday is the last day of the week
+	slot.Day = "Sunday"
+	start, _, err = slot.Interval(weekStart)
+	assert.Nil(t, err)
+	assert.Equal(t, 7, start.Day())
+
+	slot.Day = "Someday"
+	_, _, err = slot.Interval(weekStart)
+	assert.NotNil(t, err)
+
+	slot.Day = "Monday"
+	slot.StartTime = "ten"
+	_, _, err = slot.Interval(weekStart)
+	assert.NotNil(t, err)
+}
+
+func TestSlotIntervalAcrossDaylightSaving(t *testing.T) {
+	slot := Slot{ID: "Monday0900", Day: "Monday", StartTime: "9:00", EndTime: "10:00", TimeZone: "America/New_York"}
+
+	winter, _, err := slot.Interval(time.Date(2019, 3, 4, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	summer, _, err := slot.Interval(time.Date(2019, 3, 11, 0, 0, 0, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, 14, winter.UTC().Hour())
+	assert.Equal(t, 13, summer.UTC().Hour())
+}
+
+func TestValidTimeZone(t *testing.T) {
+	assert.Equal(t, "Europe/Berlin", ValidTimeZone("Europe/Berlin"))
+	assert.Equal(t, DefaultTimeZone, ValidTimeZone(""))
+	assert.Equal(t, DefaultTimeZone, ValidTimeZone("Mars/Olympus_Mons"))
+}
@@ -28,3 +28,13 @@ func UpdateSlackUser(env config.Environment, user SlackUser) error {
 	}
 	return store.Update(user.ID, user)
 }
+
+// RevokeSlackUserToken purges the token of the user that revoked it
+func RevokeSlackUserToken(env config.Environment, id string) error {
+	user, err := GetSlackUser(env, id)
+	if err != nil {
+		return err
+	}
+	user.Token = ""
+	return UpdateSlackUser(env, user)
+}
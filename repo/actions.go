@@ -28,6 +28,7 @@ type repoOps interface {
 	createRepository(repoName string, organization string) (string, error)
 	pushStarterRepo(templateRepoURL string, remoteRepoURL string) error
 	addCollaborator(githubName string, accountName string, repoName string) error
+	removeCollaborator(githubName string, accountName string, repoName string) error
 	createIssue(issue Issue, accountName string, repoName string) error
 	checkUser(githubAlias string) bool
 }
@@ -125,6 +126,71 @@ Resume Link: %s
 	return nil
 }
 
+// InvitePairing creates an issue in the challenge repository of the candidate, with the link to pick a live
+// pairing session with the reviewers
+func (ctx ActionContext) InvitePairing(candidate models.Candidate, challenge models.ChallengeSetup, pairingURL string) error {
+	title := "Live pairing session"
+	descriptionFormat := `
+Hi %s,
+
+Thanks for working on the coding challenge! As a next step we would like to pair with you on your solution.
+
+Please pick a time that suits you for the live pairing session: %s
+
+The link is personal and valid for %d days.
+`
+
+	description := fmt.Sprintf(descriptionFormat, candidate.Name, pairingURL, int(models.PairingLinkDuration.Hours()/24))
+	issue := Issue{
+		Title:       title,
+		Discipline:  challenge.Name,
+		Description: description,
+	}
+	repoName := challengeRepoName(challenge.RepoNameFormat, challenge.Name, candidate.GithubAlias)
+
+	err := ctx.ops.createIssue(issue, challenge.OrgOrOwner(), repoName)
+	if err != nil {
+		log.Println("[ERROR] Could not create the pairing issue at ", repoName)
+		return err
+	}
+	return nil
+}
+
+// ExtendDeadline tells the candidate about their new deadline, through an issue in their challenge repository
+func (ctx ActionContext) ExtendDeadline(candidate models.Candidate, challenge models.ChallengeSetup) error {
+	title := "Deadline extended"
+	descriptionFormat := `
+Hi %s,
+
+The deadline of the coding challenge is extended to %s. Please open a pull request with your solution by then.
+`
+
+	description := fmt.Sprintf(descriptionFormat, candidate.Name, candidate.DueDate().Format("Monday, 2 January 2006"))
+	issue := Issue{
+		Title:       title,
+		Discipline:  challenge.Name,
+		Description: description,
+	}
+	repoName := challengeRepoName(challenge.RepoNameFormat, challenge.Name, candidate.GithubAlias)
+
+	err := ctx.ops.createIssue(issue, challenge.OrgOrOwner(), repoName)
+	if err != nil {
+		log.Println("[ERROR] Could not create the deadline issue at ", repoName)
+		return err
+	}
+	return nil
+}
+
+// RevokeAccess removes the candidate from the collaborators of their challenge repository, the repository is kept
+func (ctx ActionContext) RevokeAccess(candidate models.Candidate, challenge models.ChallengeSetup) error {
+	repoName := challengeRepoName(challenge.RepoNameFormat, challenge.Name, candidate.GithubAlias)
+	err := ctx.ops.removeCollaborator(candidate.GithubAlias, challenge.OrgOrOwner(), repoName)
+	if err != nil {
+		log.Println("[ERROR] Cannot remove the candidate from the collaborators ", candidate.GithubAlias)
+	}
+	return err
+}
+
 func (ctx ActionContext) addCollaborator(githubAlias string, repoName string, orgOrOwner string) error {
 	return ctx.ops.addCollaborator(githubAlias, orgOrOwner, repoName)
 }
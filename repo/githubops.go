@@ -99,6 +99,12 @@ func (ctx githubOps) addCollaborator(githubName string, accountName string, repo
 	return err
 }
 
+func (ctx githubOps) removeCollaborator(githubName string, accountName string, repoName string) error {
+	client, context := ctx.getClient()
+	_, err := client.Repositories.RemoveCollaborator(context, accountName, repoName, githubName)
+	return err
+}
+
 func (ctx githubOps) pushStarterRepo(templateRepoURL string, remoteRepoURL string) error {
 	gitops := &gitOps{
 		token: ctx.token,
@@ -0,0 +1,106 @@
+package scheduling
+
+import (
+	"sort"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+)
+
+// CheckCapacity returns an error if booking the reviewer for the occurrence would exceed their weekly or monthly caps,
+// or if the occurrence is in the cool-down after a full week
+func CheckCapacity(reviewer models.Reviewer, occurrence models.SlotOccurrence) error {
+	weekStart, err := WeekOfOccurrence(occurrence)
+	if err != nil {
+		return err
+	}
+	if bookingsInWeek(reviewer, weekStart) >= reviewer.BookingsPerWeek {
+		return MaxBookingsError{}
+	}
+	if reviewer.BookingsPerMonth > 0 && bookingsInMonth(reviewer, occurrence.Date) >= reviewer.BookingsPerMonth {
+		return MaxMonthlyBookingsError{}
+	}
+	if until, ok := cooldownUntil(reviewer, weekStart); ok {
+		return CooldownError{Until: until}
+	}
+	return nil
+}
+
+func bookingsInMonth(reviewer models.Reviewer, date string) int {
+	// Dates are "2006-01-02", so the month is the prefix
+	month := date[:7]
+	count := 0
+	for _, booking := range reviewer.Bookings {
+		if !booking.IsForChallenge(reviewer.ChallengeID) {
+			continue
+		}
+		if len(booking.Occurrence.Date) >= 7 && booking.Occurrence.Date[:7] == month {
+			count++
+		}
+	}
+	return count
+}
+
+// cooldownUntil returns the first week the reviewer can be booked again, if one of the CooldownWeeks weeks
+// before the week was fully booked
+func cooldownUntil(reviewer models.Reviewer, weekStart time.Time) (time.Time, bool) {
+	if reviewer.CooldownWeeks <= 0 || reviewer.BookingsPerWeek <= 0 {
+		return time.Time{}, false
+	}
+	for i := 1; i <= reviewer.CooldownWeeks; i++ {
+		week := weekStart.AddDate(0, 0, -7*i)
+		if bookingsInWeek(reviewer, week) >= reviewer.BookingsPerWeek {
+			return week.AddDate(0, 0, 7*(reviewer.CooldownWeeks+1)), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ReviewerLoad is the booking load of a reviewer over the report period. Reviewers are overloaded if they were
+// fully booked in more than half of the weeks, or reached their monthly cap.
+type ReviewerLoad struct {
+	Reviewer models.Reviewer
+	// Bookings per week, oldest week first
+	Weekly     []int
+	Total      int
+	FullWeeks  int
+	ThisMonth  int
+	Overloaded bool
+	Unused     bool
+}
+
+// LoadReport counts the bookings of the reviewers in the given number of weeks up to and including the current week.
+// Reviewers over a cap are listed first, then by total bookings.
+func LoadReport(reviewers []models.Reviewer, weeks int, now time.Time) []ReviewerLoad {
+	currentWeek := StartOfWeek(now)
+	today := now.Format(models.DateFormat)
+
+	report := make([]ReviewerLoad, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		load := ReviewerLoad{
+			Reviewer:  reviewer,
+			Weekly:    make([]int, 0, weeks),
+			ThisMonth: bookingsInMonth(reviewer, today),
+		}
+		for i := weeks - 1; i >= 0; i-- {
+			count := bookingsInWeek(reviewer, currentWeek.AddDate(0, 0, -7*i))
+			load.Weekly = append(load.Weekly, count)
+			load.Total += count
+			if count > 0 && count >= reviewer.BookingsPerWeek {
+				load.FullWeeks++
+			}
+		}
+		load.Overloaded = load.FullWeeks*2 > weeks ||
+			(reviewer.BookingsPerMonth > 0 && load.ThisMonth >= reviewer.BookingsPerMonth)
+		load.Unused = load.Total == 0
+		report = append(report, load)
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		if report[i].Overloaded != report[j].Overloaded {
+			return report[i].Overloaded
+		}
+		return report[i].Total > report[j].Total
+	})
+	return report
+}
@@ -0,0 +1,92 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func bookingsOn(dates ...string) map[string]models.Booking {
+	bookings := make(map[string]models.Booking)
+	for _, date := range dates {
+		occurrence := models.SlotOccurrence{Date: date, SlotID: "MondayMorning"}
+		bookings[occurrence.Key()] = models.Booking{Occurrence: occurrence}
+	}
+	return bookings
+}
+
+func TestCheckCapacity(t *testing.T) {
+	reviewer := models.Reviewer{
+		BookingsPerWeek:  1,
+		BookingsPerMonth: 2,
+		Bookings:         bookingsOn("2020-01-06", "2020-01-20"),
+	}
+
+	// Full week
+	err := CheckCapacity(reviewer, models.SlotOccurrence{Date: "2020-01-10", SlotID: "FridayMorning"})
+	assert.Equal(t, MaxBookingsError{}, err)
+
+	// Full month
+	err = CheckCapacity(reviewer, models.SlotOccurrence{Date: "2020-01-31", SlotID: "FridayMorning"})
+	assert.Equal(t, MaxMonthlyBookingsError{}, err)
+
+	err = CheckCapacity(reviewer, models.SlotOccurrence{Date: "2020-02-07", SlotID: "FridayMorning"})
+	assert.Nil(t, err)
+}
+
+func TestCheckCapacityCooldown(t *testing.T) {
+	reviewer := models.Reviewer{
+		BookingsPerWeek: 1,
+		CooldownWeeks:   2,
+		Bookings:        bookingsOn("2020-01-06"),
+	}
+
+	err := CheckCapacity(reviewer, models.SlotOccurrence{Date: "2020-01-17", SlotID: "FridayMorning"})
+	assert.Equal(t, CooldownError{Until: time.Date(2020, 1, 27, 0, 0, 0, 0, time.UTC)}, err)
+
+	err = CheckCapacity(reviewer, models.SlotOccurrence{Date: "2020-01-24", SlotID: "FridayMorning"})
+	assert.NotNil(t, err)
+
+	err = CheckCapacity(reviewer, models.SlotOccurrence{Date: "2020-01-27", SlotID: "MondayMorning"})
+	assert.Nil(t, err)
+}
+
+func TestLoadReport(t *testing.T) {
+	now := time.Date(2020, 1, 22, 12, 0, 0, 0, time.UTC)
+	busy := models.Reviewer{SlackID: "U1", BookingsPerWeek: 1, Bookings: bookingsOn("2020-01-06", "2020-01-13", "2020-01-20")}
+	some := models.Reviewer{SlackID: "U2", BookingsPerWeek: 2, Bookings: bookingsOn("2020-01-13", "2019-12-02")}
+	unused := models.Reviewer{SlackID: "U3", BookingsPerWeek: 2}
+
+	report := LoadReport([]models.Reviewer{unused, some, busy}, 4, now)
+
+	assert.Equal(t, 3, len(report))
+	assert.Equal(t, "U1", report[0].Reviewer.SlackID)
+	assert.Equal(t, []int{0, 1, 1, 1}, report[0].Weekly)
+	assert.Equal(t, 3, report[0].FullWeeks)
+	assert.True(t, report[0].Overloaded)
+	assert.Equal(t, 3, report[0].ThisMonth)
+
+	assert.Equal(t, "U2", report[1].Reviewer.SlackID)
+	assert.Equal(t, 1, report[1].Total)
+	assert.False(t, report[1].Overloaded)
+	assert.False(t, report[1].Unused)
+
+	assert.Equal(t, "U3", report[2].Reviewer.SlackID)
+	assert.True(t, report[2].Unused)
+}
+
+func TestCapacityIsPerChallenge(t *testing.T) {
+	other := models.SlotOccurrence{Date: "2020-01-06", SlotID: "MondayMorning"}
+	reviewer := models.Reviewer{
+		ChallengeID:     "ios-123",
+		BookingsPerWeek: 1,
+		Bookings: map[string]models.Booking{
+			other.Key(): models.Booking{Occurrence: other, ChallengeID: "backend-456"},
+		},
+	}
+
+	err := CheckCapacity(reviewer, models.SlotOccurrence{Date: "2020-01-10", SlotID: "FridayMorning"})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,73 @@
+package scheduling
+
+import (
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/models"
+)
+
+// FutureBookingsForSlot returns the bookings of each reviewer, by Slack ID, for the slot from today on
+func FutureBookingsForSlot(reviewers []models.Reviewer, slotID models.SlotID, now time.Time) map[string][]models.Booking {
+	today := now.Format(models.DateFormat)
+	bookings := make(map[string][]models.Booking)
+	for _, reviewer := range reviewers {
+		for _, booking := range reviewer.Bookings {
+			if booking.Occurrence.SlotID == slotID && booking.Occurrence.Date >= today {
+				bookings[reviewer.SlackID] = append(bookings[reviewer.SlackID], booking)
+			}
+		}
+	}
+	return bookings
+}
+
+// ReleaseSlot removes a slot that is not part of the challenge anymore from the reviewers' schedules,
+// cancelling its future bookings. It returns the reviewers with cancelled bookings and the bookings by Slack ID.
+func ReleaseSlot(env config.Environment, reviewers []models.Reviewer, slotID models.SlotID, now time.Time) ([]models.Reviewer, map[string][]models.Booking, error) {
+	cancelled := FutureBookingsForSlot(reviewers, slotID, now)
+	affected := make([]models.Reviewer, 0, len(cancelled))
+
+	for _, reviewer := range reviewers {
+		if !usesSlot(reviewer, slotID) {
+			continue
+		}
+
+		reviewer.GeneralAvailability = removeSlot(reviewer.GeneralAvailability, slotID)
+		rules := make([]models.AvailabilityRule, 0, len(reviewer.AvailabilityRules))
+		for _, rule := range reviewer.AvailabilityRules {
+			if rule.SlotID != slotID {
+				rules = append(rules, rule)
+			}
+		}
+		reviewer.AvailabilityRules = rules
+		for _, booking := range cancelled[reviewer.SlackID] {
+			delete(reviewer.Bookings, booking.Occurrence.Key())
+		}
+
+		err := models.UpdateReviewer(env, reviewer)
+		if err != nil {
+			return affected, cancelled, err
+		}
+		if len(cancelled[reviewer.SlackID]) > 0 {
+			affected = append(affected, reviewer)
+		}
+	}
+	return affected, cancelled, nil
+}
+
+func usesSlot(reviewer models.Reviewer, slotID models.SlotID) bool {
+	if containsSlot(reviewer.GeneralAvailability, slotID) {
+		return true
+	}
+	for _, rule := range reviewer.AvailabilityRules {
+		if rule.SlotID == slotID {
+			return true
+		}
+	}
+	for _, booking := range reviewer.Bookings {
+		if booking.Occurrence.SlotID == slotID {
+			return true
+		}
+	}
+	return false
+}
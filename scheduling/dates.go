@@ -1,11 +1,13 @@
 package scheduling
 
 import (
-	"errors"
 	"fmt"
 	"time"
+
+	"github.com/keremk/challenge-bot/models"
 )
 
+// FirstDayOfWeek returns the Monday of the week to plan for. On Sundays this is the next day.
 func FirstDayOfWeek(date time.Time) time.Time {
 	var firstDay time.Time
 	weekDay := int(date.Weekday())
@@ -18,7 +20,22 @@ func FirstDayOfWeek(date time.Time) time.Time {
 		firstDay = date.AddDate(0, 0, -(weekDay - 1))
 	}
 
-	return firstDay
+	return dateOnly(firstDay)
+}
+
+// StartOfWeek returns the Monday of the ISO-8601 week the date belongs to
+func StartOfWeek(date time.Time) time.Time {
+	offset := (int(date.Weekday()) + 6) % 7
+	return dateOnly(date.AddDate(0, 0, -offset))
+}
+
+// WeekOfOccurrence returns the Monday of the ISO-8601 week of the slot occurrence
+func WeekOfOccurrence(occurrence models.SlotOccurrence) (time.Time, error) {
+	day, err := occurrence.Day()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return StartOfWeek(day), nil
 }
 
 func WeekDescription(date time.Time) string {
@@ -32,27 +49,7 @@ func WeekDescription(date time.Time) string {
 	return fmt.Sprintf("Week %d : %s %d - %s %d", weekNo, beginWeekMonth, beginWeekDay, endWeekMonth, endWeekDay)
 }
 
-func WeekDescriptionFromWeekNo(weekNo, year int) (string, error) {
-	if weekNo == 0 {
-		return "General", nil
-	}
-	date, err := TimeFromWeekNo(weekNo, year)
-	if err != nil {
-		return "", err
-	}
-
-	date = FirstDayOfWeek(date)
-	return WeekDescription(date), nil
-}
-
-func TimeFromWeekNo(weekNo, year int) (time.Time, error) {
-	if weekNo <= 0 || weekNo > 52 {
-		return time.Time{}, errors.New("Invalid week")
-	}
-	if year < 0 {
-		return time.Time{}, errors.New("Invalid year")
-	}
-	day := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
-	newDay := day.AddDate(0, 0, 7*(weekNo-1))
-	return newDay, nil
+func dateOnly(date time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 }
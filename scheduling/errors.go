@@ -1,7 +1,29 @@
 package scheduling
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+)
+
 type MaxBookingsError struct{}
 
 func (e MaxBookingsError) Error() string {
 	return "Max number of bookings reached per week"
 }
+
+type MaxMonthlyBookingsError struct{}
+
+func (e MaxMonthlyBookingsError) Error() string {
+	return "Max number of bookings reached per month"
+}
+
+// CooldownError is returned for bookings right after a fully booked week, Until is the first week that can be booked
+type CooldownError struct {
+	Until time.Time
+}
+
+func (e CooldownError) Error() string {
+	return fmt.Sprintf("Reviewer is cooling down after a full week until %s", e.Until.Format(models.DateFormat))
+}
@@ -0,0 +1,70 @@
+package scheduling
+
+import (
+	"log"
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/models"
+)
+
+// TimeRange is the half open interval [Start, End)
+type TimeRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+func (r TimeRange) Overlaps(start, end time.Time) bool {
+	return r.Start.Before(end) && start.Before(r.End)
+}
+
+// BusySlot is a slot occurrence the reviewer is available for, but is busy in their calendar
+type BusySlot struct {
+	Occurrence models.SlotOccurrence
+	Busy       TimeRange
+	IsBooked   bool
+}
+
+// FindBusySlots checks the future slot occurrences in the given number of weeks from weekStart against the busy times
+func FindBusySlots(reviewer models.Reviewer, challenge models.ChallengeSetup, busy []TimeRange, weekStart time.Time, weeks int, now time.Time) []BusySlot {
+	busySlots := make([]BusySlot, 0, 10)
+	for week := 0; week < weeks; week++ {
+		start := weekStart.AddDate(0, 0, 7*week)
+		for _, slot := range challenge.GetSlotsInOrder() {
+			slotStart, slotEnd, err := slot.Interval(start)
+			if err != nil {
+				log.Println("[ERROR] Invalid slot in challenge - ", err)
+				continue
+			}
+			occurrence := models.NewSlotOccurrence(slotStart, slot.ID)
+			if slotEnd.Before(now) || !IsAvailable(reviewer, occurrence) {
+				continue
+			}
+
+			for _, block := range busy {
+				if block.Overlaps(slotStart, slotEnd) {
+					busySlots = append(busySlots, BusySlot{
+						Occurrence: occurrence,
+						Busy:       block,
+						IsBooked:   IsBooked(reviewer, occurrence),
+					})
+					break
+				}
+			}
+		}
+	}
+	return busySlots
+}
+
+// MarkUnavailable makes the reviewer unavailable for all the slot occurrences at once
+func MarkUnavailable(env config.Environment, reviewer models.Reviewer, occurrences []models.SlotOccurrence) (models.Reviewer, error) {
+	for _, occurrence := range occurrences {
+		reviewer = setAvailability(reviewer, SlotReference{
+			Occurrence: occurrence,
+			Available:  false,
+		})
+	}
+
+	err := models.UpdateReviewer(env, reviewer)
+	return reviewer, err
+}
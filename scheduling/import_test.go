@@ -0,0 +1,36 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindingBusySlots(t *testing.T) {
+	reviewer := models.Reviewer{
+		GeneralAvailability: []models.SlotID{"MondayMorning", "FridayMorning"},
+		Bookings: map[string]models.Booking{
+			"2020-01-10_FridayMorning": models.Booking{Occurrence: models.SlotOccurrence{Date: "2020-01-10", SlotID: "FridayMorning"}},
+		},
+	}
+	busy := []TimeRange{
+		// Ends when the Berlin Monday morning slot starts
+		{Start: time.Date(2020, 1, 6, 7, 0, 0, 0, time.UTC), End: time.Date(2020, 1, 6, 8, 0, 0, 0, time.UTC)},
+		{Start: time.Date(2020, 1, 10, 9, 0, 0, 0, time.UTC), End: time.Date(2020, 1, 10, 9, 30, 0, 0, time.UTC)},
+		{Start: time.Date(2020, 1, 13, 9, 0, 0, 0, time.UTC), End: time.Date(2020, 1, 13, 9, 30, 0, 0, time.UTC)},
+	}
+	weekStart := time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2020, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	busySlots := FindBusySlots(reviewer, newTestChallenge(), busy, weekStart, 2, now)
+
+	assert.Equal(t, 2, len(busySlots))
+	assert.Equal(t, models.SlotOccurrence{Date: "2020-01-10", SlotID: "FridayMorning"}, busySlots[0].Occurrence)
+	assert.True(t, busySlots[0].IsBooked)
+	assert.Equal(t, models.SlotOccurrence{Date: "2020-01-13", SlotID: "MondayMorning"}, busySlots[1].Occurrence)
+
+	reviewer = setAvailability(reviewer, SlotReference{Occurrence: busySlots[1].Occurrence, Available: false})
+	assert.False(t, IsAvailable(reviewer, busySlots[1].Occurrence))
+}
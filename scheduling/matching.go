@@ -0,0 +1,206 @@
+package scheduling
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/models"
+)
+
+// Weights of the matching criteria, the technology match matters most
+const (
+	technologyWeight = 3.0
+	experienceWeight = 2.0
+	capacityWeight   = 2.0
+	loadWeight       = 2.0
+)
+
+// Number of weeks before the requested week that count towards the recent review load
+const recentLoadWeeks = 4
+
+type AssignmentCriteria struct {
+	Technology string
+	Experience int
+	WeekStart  time.Time
+}
+
+// ReviewerMatch is a reviewer ranked for an assignment, with the earliest free slot in the requested week
+type ReviewerMatch struct {
+	Reviewer   models.Reviewer
+	Occurrence models.SlotOccurrence
+	Score      float64
+	// Free are all the free slot occurrences of the reviewer in the requested week, earliest first
+	Free []models.SlotOccurrence
+}
+
+// RankReviewers orders the reviewers of the challenge with a free slot in the requested week, best match first
+func RankReviewers(env config.Environment, challenge models.ChallengeSetup, criteria AssignmentCriteria) ([]ReviewerMatch, error) {
+	reviewers, err := models.GetAllReviewersForChallenge(env, challenge.ID)
+	if err != nil {
+		log.Println("[ERROR] No reviewers for the challenge - ", challenge.Name)
+		return nil, err
+	}
+
+	taxonomy, err := models.GetTaxonomy(env)
+	if err != nil {
+		log.Println("[ERROR] Cannot load the tags, matching technologies by name - ", err)
+	}
+	return rankReviewers(reviewers, challenge, criteria, taxonomy, time.Now()), nil
+}
+
+// ProposePair picks the two best ranked reviewers that share a free slot, both booked at the earliest shared one.
+// It returns none if no two reviewers share a slot.
+func ProposePair(matches []ReviewerMatch) []ReviewerMatch {
+	// The pairs are tried so that the lower ranked reviewer of the pair is as high as possible
+	for j := 1; j < len(matches); j++ {
+		for i := 0; i < j; i++ {
+			occurrence, ok := firstSharedSlot(matches[i].Free, matches[j].Free)
+			if !ok {
+				continue
+			}
+			first, second := matches[i], matches[j]
+			first.Occurrence = occurrence
+			second.Occurrence = occurrence
+			return []ReviewerMatch{first, second}
+		}
+	}
+	return nil
+}
+
+func firstSharedSlot(free, others []models.SlotOccurrence) (models.SlotOccurrence, bool) {
+	for _, occurrence := range free {
+		for _, other := range others {
+			if occurrence == other {
+				return occurrence, true
+			}
+		}
+	}
+	return models.SlotOccurrence{}, false
+}
+
+func rankReviewers(reviewers []models.Reviewer, challenge models.ChallengeSetup, criteria AssignmentCriteria, taxonomy models.Taxonomy, now time.Time) []ReviewerMatch {
+	today := now.Format(models.DateFormat)
+	matches := make([]ReviewerMatch, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		techScore := technologyScore(taxonomy, reviewer, criteria.Technology)
+		if techScore == 0 {
+			continue
+		}
+
+		remaining := reviewer.BookingsPerWeek - bookingsInWeek(reviewer, criteria.WeekStart)
+		if remaining <= 0 {
+			continue
+		}
+
+		free := freeSlots(reviewer, challenge, criteria.WeekStart, today)
+		if len(free) == 0 {
+			continue
+		}
+
+		score := technologyWeight*techScore +
+			experienceWeight*experienceScore(reviewer.Experience, criteria.Experience) +
+			capacityWeight*float64(remaining)/float64(reviewer.BookingsPerWeek) +
+			loadWeight/float64(1+recentBookings(reviewer, criteria.WeekStart))
+
+		matches = append(matches, ReviewerMatch{
+			Reviewer:   reviewer,
+			Occurrence: free[0],
+			Score:      score,
+			Free:       free,
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	return matches
+}
+
+// technologyScore is 1 if the reviewer has the technology tag, less for a narrower or broader tag and 0 for none
+func technologyScore(taxonomy models.Taxonomy, reviewer models.Reviewer, technology string) float64 {
+	return taxonomy.MatchScore(taxonomy.TagsOf(reviewer), technology)
+}
+
+// experienceScore prefers reviewers at the requested experience level, over- or under-qualified ones score less.
+// The score is between 0 and 1.
+func experienceScore(experience, requested int) float64 {
+	if experience < requested {
+		return 0.25
+	}
+	score := 1 - 0.25*float64(experience-requested)
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+func recentBookings(reviewer models.Reviewer, weekStart time.Time) int {
+	from := weekStart.AddDate(0, 0, -7*recentLoadWeeks).Format(models.DateFormat)
+	to := weekStart.Format(models.DateFormat)
+
+	count := 0
+	for _, booking := range reviewer.Bookings {
+		if !booking.IsForChallenge(reviewer.ChallengeID) {
+			continue
+		}
+		if booking.Occurrence.Date >= from && booking.Occurrence.Date < to {
+			count++
+		}
+	}
+	return count
+}
+
+// freeSlots returns the occurrences the reviewer can still be booked for in the week, earliest first
+func freeSlots(reviewer models.Reviewer, challenge models.ChallengeSetup, weekStart time.Time, today string) []models.SlotOccurrence {
+	occurrences := make([]models.SlotOccurrence, 0, len(challenge.Slots))
+	for _, slotBooking := range GetAvailableSlots(reviewer, challenge, weekStart) {
+		if slotBooking.IsBooked || slotBooking.Occurrence.Date < today {
+			continue
+		}
+		if CheckCapacity(reviewer, slotBooking.Occurrence) != nil {
+			continue
+		}
+		occurrences = append(occurrences, slotBooking.Occurrence)
+	}
+
+	// Slots are in the challenge order, the earliest date comes first
+	sort.SliceStable(occurrences, func(i, j int) bool { return occurrences[i].Date < occurrences[j].Date })
+	return occurrences
+}
+
+// AlternativeReviewers finds the other reviewers of the challenge that can take over the slot occurrence,
+// the ones with the fewest bookings in that week first
+func AlternativeReviewers(env config.Environment, challengeID string, occurrence models.SlotOccurrence, excludeSlackID string) ([]ReviewerInfo, error) {
+	reviewers, err := models.GetAllReviewersForChallenge(env, challengeID)
+	if err != nil {
+		log.Println("[ERROR] No reviewers for the challenge - ", challengeID)
+		return nil, err
+	}
+
+	weekStart, err := WeekOfOccurrence(occurrence)
+	if err != nil {
+		return nil, err
+	}
+	return alternativeReviewers(reviewers, occurrence, weekStart, excludeSlackID), nil
+}
+
+func alternativeReviewers(reviewers []models.Reviewer, occurrence models.SlotOccurrence, weekStart time.Time, excludeSlackID string) []ReviewerInfo {
+	alternatives := make([]ReviewerInfo, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		if reviewer.SlackID == excludeSlackID {
+			continue
+		}
+		if !IsAvailable(reviewer, occurrence) || IsBooked(reviewer, occurrence) {
+			continue
+		}
+		if CheckCapacity(reviewer, occurrence) != nil {
+			continue
+		}
+		alternatives = append(alternatives, ReviewerInfo{Reviewer: reviewer})
+	}
+
+	sort.SliceStable(alternatives, func(i, j int) bool {
+		return bookingsInWeek(alternatives[i].Reviewer, weekStart) < bookingsInWeek(alternatives[j].Reviewer, weekStart)
+	})
+	return alternatives
+}
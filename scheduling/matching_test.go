@@ -0,0 +1,157 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankReviewersBalancesTechnologyAndLoad(t *testing.T) {
+	weekStart := time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2020, 1, 5, 12, 0, 0, 0, time.UTC)
+	available := []models.SlotID{"MondayMorning", "FridayMorning"}
+
+	busy := models.Reviewer{
+		SlackID:             "U1",
+		TechnologyList:      "Go, Swift",
+		Experience:          1,
+		BookingsPerWeek:     2,
+		GeneralAvailability: available,
+		Bookings: map[string]models.Booking{
+			"2019-12-16_MondayMorning": models.Booking{Occurrence: models.SlotOccurrence{Date: "2019-12-16", SlotID: "MondayMorning"}},
+			"2019-12-23_MondayMorning": models.Booking{Occurrence: models.SlotOccurrence{Date: "2019-12-23", SlotID: "MondayMorning"}},
+			"2020-01-06_MondayMorning": models.Booking{Occurrence: models.SlotOccurrence{Date: "2020-01-06", SlotID: "MondayMorning"}},
+		},
+	}
+	idle := models.Reviewer{
+		SlackID:             "U2",
+		TechnologyList:      "Swift",
+		Experience:          1,
+		BookingsPerWeek:     2,
+		GeneralAvailability: available,
+	}
+	partial := models.Reviewer{
+		SlackID:             "U3",
+		TechnologyList:      "SwiftUI",
+		Experience:          1,
+		BookingsPerWeek:     2,
+		GeneralAvailability: available,
+	}
+	noMatch := models.Reviewer{
+		SlackID:             "U4",
+		TechnologyList:      "Kotlin",
+		BookingsPerWeek:     2,
+		GeneralAvailability: available,
+	}
+	full := models.Reviewer{
+		SlackID:             "U5",
+		TechnologyList:      "Swift",
+		BookingsPerWeek:     1,
+		GeneralAvailability: available,
+		Bookings: map[string]models.Booking{
+			"2020-01-10_FridayMorning": models.Booking{Occurrence: models.SlotOccurrence{Date: "2020-01-10", SlotID: "FridayMorning"}},
+		},
+	}
+
+	taxonomy := models.Taxonomy{
+		"swift":   models.Tag{ID: "swift", Name: "Swift"},
+		"swiftui": models.Tag{ID: "swiftui", Name: "SwiftUI", Parent: "swift"},
+	}
+	criteria := AssignmentCriteria{Technology: "swift", Experience: 1, WeekStart: weekStart}
+	matches := rankReviewers([]models.Reviewer{busy, idle, partial, noMatch, full}, newTestChallenge(), criteria, taxonomy, now)
+
+	assert.Equal(t, 3, len(matches))
+	assert.Equal(t, "U2", matches[0].Reviewer.SlackID)
+	assert.Equal(t, models.SlotOccurrence{Date: "2020-01-06", SlotID: "MondayMorning"}, matches[0].Occurrence)
+	assert.Equal(t, "U3", matches[1].Reviewer.SlackID)
+	assert.Equal(t, "U1", matches[2].Reviewer.SlackID)
+	assert.Equal(t, models.SlotOccurrence{Date: "2020-01-10", SlotID: "FridayMorning"}, matches[2].Occurrence)
+
+	pair := ProposePair(matches)
+	assert.Equal(t, 2, len(pair))
+	assert.Equal(t, "U2", pair[0].Reviewer.SlackID)
+	assert.Equal(t, "U3", pair[1].Reviewer.SlackID)
+	assert.Equal(t, pair[0].Occurrence, pair[1].Occurrence)
+}
+
+func TestProposePairSharesSlot(t *testing.T) {
+	monday := models.SlotOccurrence{Date: "2020-01-06", SlotID: "MondayMorning"}
+	friday := models.SlotOccurrence{Date: "2020-01-10", SlotID: "FridayMorning"}
+	matches := []ReviewerMatch{
+		ReviewerMatch{Reviewer: models.Reviewer{SlackID: "U1"}, Occurrence: monday, Free: []models.SlotOccurrence{monday}},
+		ReviewerMatch{Reviewer: models.Reviewer{SlackID: "U2"}, Occurrence: friday, Free: []models.SlotOccurrence{friday}},
+		ReviewerMatch{Reviewer: models.Reviewer{SlackID: "U3"}, Occurrence: monday, Free: []models.SlotOccurrence{monday, friday}},
+	}
+
+	pair := ProposePair(matches)
+	assert.Equal(t, 2, len(pair))
+	assert.Equal(t, "U1", pair[0].Reviewer.SlackID)
+	assert.Equal(t, "U3", pair[1].Reviewer.SlackID)
+	assert.Equal(t, monday, pair[0].Occurrence)
+	assert.Equal(t, monday, pair[1].Occurrence)
+
+	// U2 and U3 only share the Friday slot
+	pair = ProposePair(matches[1:])
+	assert.Equal(t, friday, pair[0].Occurrence)
+	assert.Equal(t, friday, pair[1].Occurrence)
+
+	assert.Nil(t, ProposePair(matches[:2]))
+	assert.Nil(t, ProposePair(matches[:1]))
+}
+
+func TestExperienceScoreBounds(t *testing.T) {
+	assert.Equal(t, 1.0, experienceScore(2, 2))
+	assert.Equal(t, 0.75, experienceScore(3, 2))
+	assert.Equal(t, 0.0, experienceScore(6, 2))
+	assert.Equal(t, 0.0, experienceScore(10, 0))
+	assert.Equal(t, 0.25, experienceScore(0, 2))
+}
+
+func TestRankReviewersSkipsPastSlots(t *testing.T) {
+	weekStart := time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+	now := time.Date(2020, 1, 7, 12, 0, 0, 0, time.UTC)
+	reviewer := models.Reviewer{
+		SlackID:             "U1",
+		BookingsPerWeek:     1,
+		GeneralAvailability: []models.SlotID{"MondayMorning", "FridayMorning"},
+	}
+
+	matches := rankReviewers([]models.Reviewer{reviewer}, newTestChallenge(), AssignmentCriteria{WeekStart: weekStart}, models.Taxonomy{}, now)
+
+	assert.Equal(t, 1, len(matches))
+	assert.Equal(t, "2020-01-10", matches[0].Occurrence.Date)
+}
+
+func TestAlternativeReviewers(t *testing.T) {
+	weekStart := time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)
+	occurrence := models.SlotOccurrence{Date: "2020-01-06", SlotID: "MondayMorning"}
+	available := []models.SlotID{"MondayMorning", "FridayMorning"}
+
+	declined := models.Reviewer{SlackID: "U1", BookingsPerWeek: 2, GeneralAvailability: available}
+	loaded := models.Reviewer{
+		SlackID:             "U2",
+		BookingsPerWeek:     2,
+		GeneralAvailability: available,
+		Bookings: map[string]models.Booking{
+			"2020-01-10_FridayMorning": models.Booking{Occurrence: models.SlotOccurrence{Date: "2020-01-10", SlotID: "FridayMorning"}},
+		},
+	}
+	idle := models.Reviewer{SlackID: "U3", BookingsPerWeek: 2, GeneralAvailability: available}
+	unavailable := models.Reviewer{SlackID: "U4", BookingsPerWeek: 2, GeneralAvailability: []models.SlotID{"FridayMorning"}}
+	booked := models.Reviewer{
+		SlackID:             "U5",
+		BookingsPerWeek:     2,
+		GeneralAvailability: available,
+		Bookings: map[string]models.Booking{
+			occurrence.Key(): models.Booking{Occurrence: occurrence},
+		},
+	}
+
+	alternatives := alternativeReviewers([]models.Reviewer{declined, loaded, idle, unavailable, booked}, occurrence, weekStart, "U1")
+
+	assert.Equal(t, 2, len(alternatives))
+	assert.Equal(t, "U3", alternatives[0].Reviewer.SlackID)
+	assert.Equal(t, "U2", alternatives[1].Reviewer.SlackID)
+}
@@ -0,0 +1,58 @@
+package scheduling
+
+import (
+	"log"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+)
+
+// Number of reviewers offered a slot for each reviewer still needed, the first ones to accept are booked
+const offersPerOpening = 2
+
+// NeedOffers picks the reviewers to offer a slot of the need to, the best matches first. Reviewers that already
+// answered an offer for the need are not asked again.
+func NeedOffers(reviewers []models.Reviewer, challenge models.ChallengeSetup, need models.Need, taxonomy models.Taxonomy, now time.Time) []ReviewerMatch {
+	wanted := need.Remaining()*offersPerOpening - len(need.OpenOffers())
+	if wanted <= 0 {
+		return nil
+	}
+	weekStart, err := time.Parse(models.DateFormat, need.WeekStart)
+	if err != nil {
+		log.Println("[ERROR] Need has an invalid week - ", need.WeekStart)
+		return nil
+	}
+
+	criteria := AssignmentCriteria{
+		Technology: need.Technology,
+		Experience: need.Experience,
+		WeekStart:  weekStart,
+	}
+	offers := make([]ReviewerMatch, 0, wanted)
+	for _, match := range rankReviewers(reviewers, challenge, criteria, taxonomy, now) {
+		if need.WasOffered(match.Reviewer.SlackID) {
+			continue
+		}
+		offers = append(offers, match)
+		if len(offers) == wanted {
+			break
+		}
+	}
+	return offers
+}
+
+// NeedsInWeek finds the needs of the challenge in the week of the slot occurrence
+func NeedsInWeek(needs []models.Need, challengeID string, occurrence models.SlotOccurrence) []models.Need {
+	weekStart, err := WeekOfOccurrence(occurrence)
+	if err != nil {
+		return nil
+	}
+
+	inWeek := make([]models.Need, 0, len(needs))
+	for _, need := range needs {
+		if need.ChallengeID == challengeID && need.WeekStart == weekStart.Format(models.DateFormat) {
+			inWeek = append(inWeek, need)
+		}
+	}
+	return inWeek
+}
@@ -0,0 +1,64 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNeedOffersSkipsAnsweredReviewers(t *testing.T) {
+	now := time.Date(2020, 1, 5, 12, 0, 0, 0, time.UTC)
+	available := []models.SlotID{"MondayMorning", "FridayMorning"}
+	reviewers := []models.Reviewer{
+		models.Reviewer{SlackID: "U1", TechnologyList: "Go", Experience: 1, BookingsPerWeek: 2, GeneralAvailability: available},
+		models.Reviewer{SlackID: "U2", TechnologyList: "Go", Experience: 1, BookingsPerWeek: 2, GeneralAvailability: available},
+		models.Reviewer{SlackID: "U3", TechnologyList: "Go", Experience: 1, BookingsPerWeek: 2, GeneralAvailability: available},
+		models.Reviewer{SlackID: "U4", TechnologyList: "Java", Experience: 1, BookingsPerWeek: 2, GeneralAvailability: available},
+	}
+	need := models.Need{
+		ChallengeID: "Test-123",
+		Technology:  "go",
+		Experience:  1,
+		WeekStart:   "2020-01-06",
+		Count:       1,
+		Offers: []models.NeedOffer{
+			models.NeedOffer{ReviewerID: "U1", Status: models.OfferPassed},
+		},
+	}
+
+	offers := NeedOffers(reviewers, newTestChallenge(), need, models.Taxonomy{}, now)
+	assert.Equal(t, 2, len(offers))
+	assert.Equal(t, "U2", offers[0].Reviewer.SlackID)
+	assert.Equal(t, "U3", offers[1].Reviewer.SlackID)
+
+	// One offer is still waiting for an answer, so only one more reviewer is asked
+	need.Offers = append(need.Offers, models.NeedOffer{ReviewerID: "U2", Status: models.OfferOpen})
+	offers = NeedOffers(reviewers, newTestChallenge(), need, models.Taxonomy{}, now)
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, "U3", offers[0].Reviewer.SlackID)
+
+	need = need.Accept(need.Offers[1])
+	assert.Equal(t, 0, need.Remaining())
+	assert.Nil(t, NeedOffers(reviewers, newTestChallenge(), need, models.Taxonomy{}, now))
+
+	// A cancelled booking is offered again, but not to the reviewer who cancelled
+	need, released := need.ReleaseBooking("U2", models.SlotOccurrence{})
+	assert.True(t, released)
+	offers = NeedOffers(reviewers, newTestChallenge(), need, models.Taxonomy{}, now)
+	assert.Equal(t, 1, len(offers))
+	assert.Equal(t, "U3", offers[0].Reviewer.SlackID)
+}
+
+func TestNeedsInWeek(t *testing.T) {
+	needs := []models.Need{
+		models.Need{ID: "a", ChallengeID: "Test-123", WeekStart: "2020-01-06"},
+		models.Need{ID: "b", ChallengeID: "Test-123", WeekStart: "2020-01-13"},
+		models.Need{ID: "c", ChallengeID: "Other-456", WeekStart: "2020-01-06"},
+	}
+
+	inWeek := NeedsInWeek(needs, "Test-123", models.SlotOccurrence{Date: "2020-01-10", SlotID: "FridayMorning"})
+	assert.Equal(t, 1, len(inWeek))
+	assert.Equal(t, "a", inWeek[0].ID)
+}
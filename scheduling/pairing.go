@@ -0,0 +1,122 @@
+package scheduling
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/models"
+)
+
+// PairingWeeks is how many weeks ahead the candidate can pick a live pairing session
+const PairingWeeks = 3
+
+// Sessions starting sooner than this are not offered, so the reviewers can prepare
+const pairingNotice = 24 * time.Hour
+
+// PairingSlots finds the slot occurrences in the coming weeks, where all the reviewers are available and have
+// room for another booking. The reviewers have the capacity for the challenge, the earliest slots are first.
+func PairingSlots(reviewers []models.Reviewer, challenge models.ChallengeSetup, now time.Time, weeks int) []models.SlotOccurrence {
+	if len(reviewers) == 0 {
+		return nil
+	}
+
+	type slotStart struct {
+		occurrence models.SlotOccurrence
+		start      time.Time
+	}
+	starts := make([]slotStart, 0, len(challenge.Slots)*weeks)
+	firstWeek := FirstDayOfWeek(now)
+	for i := 0; i < weeks; i++ {
+		weekStart := firstWeek.AddDate(0, 0, 7*i)
+		for _, slot := range challenge.GetSlotsInOrder() {
+			start, _, err := slot.Interval(weekStart)
+			if err != nil {
+				log.Println("[ERROR] Invalid slot in challenge - ", err)
+				continue
+			}
+			if start.Before(now.Add(pairingNotice)) {
+				continue
+			}
+			occurrence := models.NewSlotOccurrence(start, slot.ID)
+			if canPair(reviewers, occurrence) {
+				starts = append(starts, slotStart{occurrence: occurrence, start: start})
+			}
+		}
+	}
+
+	sort.SliceStable(starts, func(i, j int) bool { return starts[i].start.Before(starts[j].start) })
+	occurrences := make([]models.SlotOccurrence, 0, len(starts))
+	for _, s := range starts {
+		occurrences = append(occurrences, s.occurrence)
+	}
+	return occurrences
+}
+
+func canPair(reviewers []models.Reviewer, occurrence models.SlotOccurrence) bool {
+	for _, reviewer := range reviewers {
+		if !IsAvailable(reviewer, occurrence) || IsBooked(reviewer, occurrence) || CheckCapacity(reviewer, occurrence) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrSlotTaken is returned when the slot is not free anymore by the time the reviewer is booked
+var ErrSlotTaken = errors.New("[ERROR] Slot is not free anymore")
+
+// BookPairing books all the reviewers for the live pairing session. Each reviewer is booked in a transaction that
+// checks the slot is still free, if one of them cannot be booked the others are unbooked again.
+func BookPairing(env config.Environment, reviewers []models.Reviewer, occurrence models.SlotOccurrence, input map[string]string) ([]models.Reviewer, error) {
+	input["kind"] = models.LivePairing
+
+	booked := make([]models.Reviewer, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		booking := models.NewBooking(reviewer.SlackID, occurrence, input)
+		updated, err := bookIfFree(env, reviewer.ID, reviewer.ChallengeID, booking)
+		if err != nil {
+			log.Println("[ERROR] Cannot book the reviewer for the pairing session - ", reviewer.Name, err)
+			for _, other := range booked {
+				undoErr := cancelBooking(env, other.ID, other.Bookings[occurrence.Key()])
+				if undoErr != nil {
+					log.Println("[ERROR] Cannot undo the pairing booking of - ", other.Name, undoErr)
+				}
+			}
+			return nil, err
+		}
+		booked = append(booked, updated)
+	}
+	return booked, nil
+}
+
+// bookIfFree books the stored reviewer if they are still available, not booked and have room for the booking
+func bookIfFree(env config.Environment, reviewerID string, challengeID string, booking models.Booking) (models.Reviewer, error) {
+	return models.ChangeReviewer(env, reviewerID, func(reviewer models.Reviewer) (models.Reviewer, error) {
+		reviewer, err := reviewer.ForChallenge(challengeID)
+		if err != nil {
+			return reviewer, err
+		}
+		if !canPair([]models.Reviewer{reviewer}, booking.Occurrence) {
+			return reviewer, ErrSlotTaken
+		}
+		if reviewer.Bookings == nil {
+			reviewer.Bookings = make(map[string]models.Booking)
+		}
+		reviewer.Bookings[booking.Occurrence.Key()] = booking
+		return reviewer, nil
+	})
+}
+
+// cancelBooking removes the booking from the stored reviewer, unless the slot was booked again in the meantime
+func cancelBooking(env config.Environment, reviewerID string, booking models.Booking) error {
+	_, err := models.ChangeReviewer(env, reviewerID, func(reviewer models.Reviewer) (models.Reviewer, error) {
+		key := booking.Occurrence.Key()
+		if stored, ok := reviewer.Bookings[key]; ok && stored.ID == booking.ID {
+			delete(reviewer.Bookings, key)
+		}
+		return reviewer, nil
+	})
+	return err
+}
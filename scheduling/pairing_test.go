@@ -0,0 +1,32 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPairingSlots(t *testing.T) {
+	// Thursday, the Friday slot of this week is too soon
+	now := time.Date(2020, 1, 9, 12, 0, 0, 0, time.UTC)
+	available := []models.SlotID{"MondayMorning", "FridayMorning"}
+	reviewers := []models.Reviewer{
+		models.Reviewer{SlackID: "U1", ChallengeID: "Test-123", BookingsPerWeek: 2, GeneralAvailability: available},
+		models.Reviewer{SlackID: "U2", ChallengeID: "Test-123", BookingsPerWeek: 2, GeneralAvailability: available,
+			Bookings: map[string]models.Booking{
+				"2020-01-13_MondayMorning": models.Booking{ChallengeID: "Test-123", Occurrence: models.SlotOccurrence{Date: "2020-01-13", SlotID: "MondayMorning"}},
+			},
+		},
+	}
+
+	slots := PairingSlots(reviewers, newTestChallenge(), now, 2)
+	assert.Equal(t, []models.SlotOccurrence{{Date: "2020-01-17", SlotID: "FridayMorning"}}, slots)
+
+	// A reviewer without room for another booking in the week leaves no slot
+	reviewers[1].BookingsPerWeek = 1
+	assert.Empty(t, PairingSlots(reviewers, newTestChallenge(), now, 2))
+
+	assert.Empty(t, PairingSlots(nil, newTestChallenge(), now, 2))
+}
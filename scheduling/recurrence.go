@@ -0,0 +1,98 @@
+package scheduling
+
+import (
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/models"
+)
+
+// RuleMatches checks if the recurring availability rule includes the slot occurrence
+func RuleMatches(rule models.AvailabilityRule, occurrence models.SlotOccurrence) bool {
+	if rule.SlotID != occurrence.SlotID {
+		return false
+	}
+	if occurrence.Date < rule.Start || (rule.Until != "" && occurrence.Date > rule.Until) {
+		return false
+	}
+
+	day, err := occurrence.Day()
+	if err != nil {
+		return false
+	}
+	if len(rule.ByMonth) > 0 && !containsMonth(rule.ByMonth, int(day.Month())) {
+		return false
+	}
+
+	interval := rule.Interval
+	if interval <= 1 {
+		return true
+	}
+	start, err := models.SlotOccurrence{Date: rule.Start}.Day()
+	if err != nil {
+		return false
+	}
+	weeks := int(StartOfWeek(day).Sub(StartOfWeek(start)).Hours()/24) / 7
+	return weeks%interval == 0
+}
+
+func IsOutOfOffice(reviewer models.Reviewer, occurrence models.SlotOccurrence) bool {
+	for _, away := range reviewer.OutOfOffice {
+		if away.Contains(occurrence.Date) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyRule(reviewer models.Reviewer, occurrence models.SlotOccurrence) bool {
+	for _, rule := range reviewer.AvailabilityRules {
+		if RuleMatches(rule, occurrence) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsMonth(months []int, month int) bool {
+	for _, m := range months {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+func AddAvailabilityRule(env config.Environment, reviewer models.Reviewer, rule models.AvailabilityRule) (models.Reviewer, error) {
+	reviewer.AvailabilityRules = append(reviewer.AvailabilityRules, rule)
+	err := models.UpdateReviewer(env, reviewer)
+	return reviewer, err
+}
+
+func RemoveAvailabilityRule(env config.Environment, reviewer models.Reviewer, ruleID string) (models.Reviewer, error) {
+	rules := make([]models.AvailabilityRule, 0, len(reviewer.AvailabilityRules))
+	for _, rule := range reviewer.AvailabilityRules {
+		if rule.ID != ruleID {
+			rules = append(rules, rule)
+		}
+	}
+	reviewer.AvailabilityRules = rules
+	err := models.UpdateReviewer(env, reviewer)
+	return reviewer, err
+}
+
+func AddOutOfOffice(env config.Environment, reviewer models.Reviewer, away models.DateRange) (models.Reviewer, error) {
+	reviewer.OutOfOffice = append(reviewer.OutOfOffice, away)
+	err := models.UpdateReviewer(env, reviewer)
+	return reviewer, err
+}
+
+func RemoveOutOfOffice(env config.Environment, reviewer models.Reviewer, awayID string) (models.Reviewer, error) {
+	ranges := make([]models.DateRange, 0, len(reviewer.OutOfOffice))
+	for _, away := range reviewer.OutOfOffice {
+		if away.ID != awayID {
+			ranges = append(ranges, away)
+		}
+	}
+	reviewer.OutOfOffice = ranges
+	err := models.UpdateReviewer(env, reviewer)
+	return reviewer, err
+}
@@ -0,0 +1,51 @@
+package scheduling
+
+import (
+	"testing"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEveryOtherWeekRule(t *testing.T) {
+	rule := models.AvailabilityRule{SlotID: "TuesdayAfternoon", Interval: 2, Start: "2019-07-15"}
+
+	assert.True(t, RuleMatches(rule, models.SlotOccurrence{Date: "2019-07-16", SlotID: "TuesdayAfternoon"}))
+	assert.False(t, RuleMatches(rule, models.SlotOccurrence{Date: "2019-07-23", SlotID: "TuesdayAfternoon"}))
+	assert.True(t, RuleMatches(rule, models.SlotOccurrence{Date: "2019-07-30", SlotID: "TuesdayAfternoon"}))
+	assert.False(t, RuleMatches(rule, models.SlotOccurrence{Date: "2019-07-30", SlotID: "TuesdayMorning"}))
+	assert.False(t, RuleMatches(rule, models.SlotOccurrence{Date: "2019-07-02", SlotID: "TuesdayAfternoon"}))
+}
+
+func TestMonthlyRuleWithOutOfOffice(t *testing.T) {
+	reviewer := models.Reviewer{
+		AvailabilityRules: []models.AvailabilityRule{
+			models.AvailabilityRule{SlotID: "MondayMorning", Interval: 1, Start: "2020-01-06", ByMonth: []int{3}},
+		},
+		OutOfOffice: []models.DateRange{
+			models.DateRange{From: "2020-03-09", To: "2020-03-13"},
+		},
+	}
+
+	assert.False(t, IsAvailable(reviewer, models.SlotOccurrence{Date: "2020-02-24", SlotID: "MondayMorning"}))
+	assert.True(t, IsAvailable(reviewer, models.SlotOccurrence{Date: "2020-03-02", SlotID: "MondayMorning"}))
+	assert.False(t, IsAvailable(reviewer, models.SlotOccurrence{Date: "2020-03-09", SlotID: "MondayMorning"}))
+	assert.True(t, IsAvailable(reviewer, models.SlotOccurrence{Date: "2020-03-16", SlotID: "MondayMorning"}))
+	assert.Equal(t, "FREQ=WEEKLY;BYMONTH=3", reviewer.AvailabilityRules[0].String())
+}
+
+func TestPausedReviewerIsNotAvailable(t *testing.T) {
+	reviewer := models.Reviewer{
+		GeneralAvailability: []models.SlotID{"MondayMorning"},
+		Paused:              true,
+		PausedUntil:         "2020-03-09",
+	}
+
+	assert.False(t, IsAvailable(reviewer, models.SlotOccurrence{Date: "2020-03-02", SlotID: "MondayMorning"}))
+	assert.False(t, IsAvailable(reviewer, models.SlotOccurrence{Date: "2020-03-09", SlotID: "MondayMorning"}))
+	assert.True(t, IsAvailable(reviewer, models.SlotOccurrence{Date: "2020-03-16", SlotID: "MondayMorning"}))
+
+	// Paused without an end date
+	reviewer.PausedUntil = ""
+	assert.False(t, IsAvailable(reviewer, models.SlotOccurrence{Date: "2020-03-16", SlotID: "MondayMorning"}))
+}
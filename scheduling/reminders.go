@@ -0,0 +1,175 @@
+package scheduling
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+)
+
+// How long before a booked slot the reminders are sent
+const (
+	dayReminderLead  = 24 * time.Hour
+	hourReminderLead = time.Hour
+)
+
+// The weekly digest is sent on Mondays from this hour on, in the reviewer's time zone
+const digestHour = 9
+
+// Number of weeks, starting with the current one, the digest checks for missing availability
+const digestWeeks = 4
+
+type ReminderKind int
+
+const (
+	DayReminder ReminderKind = iota + 1
+	HourReminder
+)
+
+// Reminder is a booking of the reviewer that starts soon
+type Reminder struct {
+	Kind    ReminderKind
+	Booking models.Booking
+	Start   time.Time
+}
+
+// Digest lists the bookings of the week and the upcoming weeks without any availability
+type Digest struct {
+	WeekStart  time.Time
+	Bookings   []Reminder
+	UnsetWeeks []time.Time
+}
+
+// Key identifies the reminder of the booking, e.g. "abc123-day", to send it once. Bookings migrated from the week
+// keyed schedule may have no ID, they are identified by the reviewer and the occurrence.
+func (r Reminder) Key() string {
+	kind := "day"
+	if r.Kind == HourReminder {
+		kind = "hour"
+	}
+	bookingID := r.Booking.ID
+	if bookingID == "" {
+		bookingID = fmt.Sprintf("%s-%s", r.Booking.ReviewerID, r.Booking.Occurrence.Key())
+	}
+	return fmt.Sprintf("%s-%s", bookingID, kind)
+}
+
+// Key identifies the digest of the reviewer for the challenge and the week, to send it once
+func (d Digest) Key(reviewerID, challengeID string) string {
+	return fmt.Sprintf("digest-%s-%s-%s", reviewerID, challengeID, d.WeekStart.Format(models.DateFormat))
+}
+
+// DueReminders finds the bookings that need a reminder now. The bookings of the reminders are marked as reminded,
+// to be saved once the reminders are delivered.
+func DueReminders(reviewer models.Reviewer, challenge models.ChallengeSetup, now time.Time) []Reminder {
+	reminders := make([]Reminder, 0)
+	if reviewer.RemindersOff {
+		return reminders
+	}
+
+	for key, booking := range reviewer.Bookings {
+		if booking.HourReminderSent || !booking.IsForChallenge(challenge.ID) {
+			continue
+		}
+		start, err := bookingStart(booking, challenge)
+		if err != nil {
+			log.Println("[ERROR] Booking is not for a valid slot - ", key)
+			continue
+		}
+		until := start.Sub(now)
+		if until <= 0 || until > dayReminderLead {
+			continue
+		}
+
+		var kind ReminderKind
+		if until <= hourReminderLead {
+			kind = HourReminder
+			booking.HourReminderSent = true
+			// No need for the day before reminder anymore
+			booking.DayReminderSent = true
+		} else if !booking.DayReminderSent {
+			kind = DayReminder
+			booking.DayReminderSent = true
+		} else {
+			continue
+		}
+		reminders = append(reminders, Reminder{
+			Kind:    kind,
+			Booking: booking,
+			Start:   start,
+		})
+	}
+
+	sort.SliceStable(reminders, func(i, j int) bool { return reminders[i].Start.Before(reminders[j].Start) })
+	return reminders
+}
+
+// DueDigest returns the weekly digest of the reviewer if it is Monday morning and it was not sent yet this week
+func DueDigest(reviewer models.Reviewer, challenge models.ChallengeSetup, now time.Time) (Digest, bool) {
+	if reviewer.RemindersOff {
+		return Digest{}, false
+	}
+
+	local := now.In(models.LoadLocation(reviewer.TimeZone))
+	if local.Weekday() != time.Monday || local.Hour() < digestHour {
+		return Digest{}, false
+	}
+
+	weekStart := StartOfWeek(local)
+	if reviewer.LastDigestWeek == weekStart.Format(models.DateFormat) {
+		return Digest{}, false
+	}
+
+	digest := Digest{
+		WeekStart:  weekStart,
+		Bookings:   make([]Reminder, 0),
+		UnsetWeeks: make([]time.Time, 0, digestWeeks),
+	}
+
+	from := weekStart.Format(models.DateFormat)
+	to := weekStart.AddDate(0, 0, 7).Format(models.DateFormat)
+	for key, booking := range reviewer.Bookings {
+		if booking.Occurrence.Date < from || booking.Occurrence.Date >= to || !booking.IsForChallenge(challenge.ID) {
+			continue
+		}
+		start, err := bookingStart(booking, challenge)
+		if err != nil {
+			log.Println("[ERROR] Booking is not for a valid slot - ", key)
+			continue
+		}
+		digest.Bookings = append(digest.Bookings, Reminder{Booking: booking, Start: start})
+	}
+	sort.SliceStable(digest.Bookings, func(i, j int) bool { return digest.Bookings[i].Start.Before(digest.Bookings[j].Start) })
+
+	for i := 0; i < digestWeeks; i++ {
+		week := weekStart.AddDate(0, 0, 7*i)
+		if !hasAvailability(reviewer, challenge, week) {
+			digest.UnsetWeeks = append(digest.UnsetWeeks, week)
+		}
+	}
+	return digest, true
+}
+
+func hasAvailability(reviewer models.Reviewer, challenge models.ChallengeSetup, weekStart time.Time) bool {
+	for _, slot := range SlotsForWeek(weekStart, reviewer, challenge) {
+		if slot.IsSelected {
+			return true
+		}
+	}
+	return false
+}
+
+func bookingStart(booking models.Booking, challenge models.ChallengeSetup) (time.Time, error) {
+	slot := challenge.Slots[booking.Occurrence.SlotID]
+	if slot == nil {
+		return time.Time{}, fmt.Errorf("[ERROR] Unknown slot - %s", booking.Occurrence.SlotID)
+	}
+	weekStart, err := WeekOfOccurrence(booking.Occurrence)
+	if err != nil {
+		return time.Time{}, err
+	}
+	start, _, err := slot.Interval(weekStart)
+	return start, err
+}
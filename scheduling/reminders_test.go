@@ -0,0 +1,109 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func reminderChallenge() models.ChallengeSetup {
+	return models.ChallengeSetup{
+		Slots: map[models.SlotID]*models.Slot{
+			"MondayMorning":    &models.Slot{ID: "MondayMorning", Ordinal: 0, Day: "Monday", StartTime: "9:00", EndTime: "11:00", TimeZone: "UTC"},
+			"TuesdayAfternoon": &models.Slot{ID: "TuesdayAfternoon", Ordinal: 1, Day: "Tuesday", StartTime: "14:00", EndTime: "16:00", TimeZone: "UTC"},
+		},
+	}
+}
+
+func TestDueReminders(t *testing.T) {
+	reviewer := models.Reviewer{
+		Bookings: map[string]models.Booking{
+			"2020-01-06_MondayMorning":    models.Booking{ID: "a", Occurrence: models.SlotOccurrence{Date: "2020-01-06", SlotID: "MondayMorning"}},
+			"2020-01-07_TuesdayAfternoon": models.Booking{ID: "b", Occurrence: models.SlotOccurrence{Date: "2020-01-07", SlotID: "TuesdayAfternoon"}},
+		},
+	}
+	challenge := reminderChallenge()
+
+	// The Monday booking is the next day, the Tuesday one is too far away
+	reminders := DueReminders(reviewer, challenge, time.Date(2020, 1, 5, 10, 0, 0, 0, time.UTC))
+	assert.Equal(t, 1, len(reminders))
+	assert.Equal(t, DayReminder, reminders[0].Kind)
+	assert.Equal(t, "a", reminders[0].Booking.ID)
+	assert.Equal(t, "a-day", reminders[0].Key())
+	assert.True(t, reminders[0].Booking.DayReminderSent)
+
+	// Once the day reminder is sent, only the hour reminder is left
+	booking := reviewer.Bookings["2020-01-06_MondayMorning"]
+	booking.DayReminderSent = true
+	reviewer.Bookings["2020-01-06_MondayMorning"] = booking
+	reminders = DueReminders(reviewer, challenge, time.Date(2020, 1, 5, 18, 0, 0, 0, time.UTC))
+	assert.Equal(t, 0, len(reminders))
+
+	reminders = DueReminders(reviewer, challenge, time.Date(2020, 1, 6, 8, 30, 0, 0, time.UTC))
+	assert.Equal(t, 1, len(reminders))
+	assert.Equal(t, HourReminder, reminders[0].Kind)
+	assert.Equal(t, "a-hour", reminders[0].Key())
+	assert.True(t, reminders[0].Booking.HourReminderSent)
+
+	reviewer.RemindersOff = true
+	reminders = DueReminders(reviewer, challenge, time.Date(2020, 1, 6, 8, 30, 0, 0, time.UTC))
+	assert.Equal(t, 0, len(reminders))
+}
+
+func TestRemindersOfBookingsWithoutID(t *testing.T) {
+	occurrence := models.SlotOccurrence{Date: "2020-01-06", SlotID: "MondayMorning"}
+	reviewers := []models.Reviewer{
+		models.Reviewer{SlackID: "U1", Bookings: map[string]models.Booking{occurrence.Key(): models.Booking{ReviewerID: "U1", Occurrence: occurrence}}},
+		models.Reviewer{SlackID: "U2", Bookings: map[string]models.Booking{occurrence.Key(): models.Booking{ReviewerID: "U2", Occurrence: occurrence}}},
+	}
+
+	// Each reminder is sent once its key is claimed, so the bookings need keys of their own
+	claimed := make(map[string]bool)
+	sent := 0
+	for _, reviewer := range reviewers {
+		for _, reminder := range DueReminders(reviewer, reminderChallenge(), time.Date(2020, 1, 5, 10, 0, 0, 0, time.UTC)) {
+			if claimed[reminder.Key()] {
+				continue
+			}
+			claimed[reminder.Key()] = true
+			sent++
+		}
+	}
+	assert.Equal(t, 2, sent)
+	assert.True(t, claimed["U1-2020-01-06_MondayMorning-day"])
+}
+
+func TestDueDigest(t *testing.T) {
+	reviewer := models.Reviewer{
+		TimeZone:            "Europe/Berlin",
+		GeneralAvailability: []models.SlotID{},
+		Availability: map[string]bool{
+			"2020-01-06_MondayMorning":    true,
+			"2020-01-14_TuesdayAfternoon": true,
+		},
+		Bookings: map[string]models.Booking{
+			"2020-01-06_MondayMorning": models.Booking{ID: "a", Occurrence: models.SlotOccurrence{Date: "2020-01-06", SlotID: "MondayMorning"}},
+		},
+	}
+	challenge := reminderChallenge()
+
+	// 7:30 UTC is 8:30 in Berlin, too early for the digest
+	_, ok := DueDigest(reviewer, challenge, time.Date(2020, 1, 6, 7, 30, 0, 0, time.UTC))
+	assert.False(t, ok)
+
+	digest, ok := DueDigest(reviewer, challenge, time.Date(2020, 1, 6, 8, 30, 0, 0, time.UTC))
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC), digest.WeekStart)
+	assert.Equal(t, "digest-r1-c1-2020-01-06", digest.Key("r1", "c1"))
+	assert.Equal(t, 1, len(digest.Bookings))
+	assert.Equal(t, []time.Time{
+		time.Date(2020, 1, 20, 0, 0, 0, 0, time.UTC),
+		time.Date(2020, 1, 27, 0, 0, 0, 0, time.UTC),
+	}, digest.UnsetWeeks)
+
+	reviewer.LastDigestWeek = "2020-01-06"
+	_, ok = DueDigest(reviewer, challenge, time.Date(2020, 1, 6, 8, 30, 0, 0, time.UTC))
+	assert.False(t, ok)
+}
@@ -3,101 +3,144 @@ package scheduling
 import (
 	"fmt"
 	"log"
-	"strings"
+	"time"
 
 	"github.com/keremk/challenge-bot/config"
 	"github.com/keremk/challenge-bot/models"
 )
 
+// SlotInfo is a challenge slot in a reviewer's schedule. The Occurrence is empty for the general (all weeks) schedule.
 type SlotInfo struct {
 	Slot       models.Slot
+	Occurrence models.SlotOccurrence
 	IsSelected bool
 }
 
+// SlotBooking is a slot occurrence of a reviewer. Booking holds the booking details if it is booked.
 type SlotBooking struct {
-	SlotID   string
-	WeekNo   int
-	Year     int
-	IsBooked bool
+	Occurrence models.SlotOccurrence
+	IsBooked   bool
+	Booking    models.Booking
 }
+
 type SlotReference struct {
-	SlotID    string
-	WeekNo    int
-	Year      int
-	Available bool
+	Occurrence models.SlotOccurrence
+	Available  bool
 }
 
-func SlotIndex(week, year int) string {
-	var slotIndex string
-	if week == 0 {
-		// Return all weeks (general) setup
-		slotIndex = "General"
-	} else {
-		// Return the specific week info
-		slotIndex = fmt.Sprintf("%d-%d", week, year)
+// ChallengeOfSlot finds the challenge of the reviewer with the slot. The returned reviewer has the skills and
+// capacity for that challenge. It fails if several challenges have the slot, which only happens for the slots that
+// were created before the slot IDs were unique across challenges.
+func ChallengeOfSlot(env config.Environment, reviewer models.Reviewer, slotID string) (models.Reviewer, models.ChallengeSetup, error) {
+	var found []models.ChallengeSetup
+	for _, challengeID := range reviewer.ChallengeIDs() {
+		challenge, err := models.GetChallengeSetupByID(env, challengeID)
+		if err != nil {
+			log.Println("[ERROR] Invalid challenge for reviewer - ", challengeID, err)
+			continue
+		}
+		if challenge.Slots[slotID] != nil {
+			found = append(found, challenge)
+		}
 	}
+	return challengeOfSlot(reviewer, slotID, found)
+}
 
-	return slotIndex
+func challengeOfSlot(reviewer models.Reviewer, slotID string, found []models.ChallengeSetup) (models.Reviewer, models.ChallengeSetup, error) {
+	switch len(found) {
+	case 0:
+		return reviewer, models.ChallengeSetup{}, fmt.Errorf("[ERROR] No challenge of reviewer %s has the slot %s", reviewer.Name, slotID)
+	case 1:
+		reviewer, err := reviewer.ForChallenge(found[0].ID)
+		return reviewer, found[0], err
+	default:
+		return reviewer, models.ChallengeSetup{}, fmt.Errorf("[ERROR] Several challenges of reviewer %s have the slot %s", reviewer.Name, slotID)
+	}
 }
 
-func GetAvailableSlots(reviewer models.Reviewer, weekNo, year int) []SlotBooking {
-	slotIndex := SlotIndex(weekNo, year)
-	availableSlots := reviewer.Availability[slotIndex]
-	if availableSlots == nil {
-		availableSlots = reviewer.Availability["General"]
+// IsAvailable checks the reviewer's availability for a slot occurrence. Pauses and out of office dates always win,
+// then changes for the specific date, then the general availability and the recurring rules.
+func IsAvailable(reviewer models.Reviewer, occurrence models.SlotOccurrence) bool {
+	if reviewer.IsPausedOn(occurrence.Date) || IsOutOfOffice(reviewer, occurrence) {
+		return false
 	}
+	if available, ok := reviewer.Availability[occurrence.Key()]; ok {
+		return available
+	}
+	return containsSlot(reviewer.GeneralAvailability, occurrence.SlotID) || matchesAnyRule(reviewer, occurrence)
+}
 
-	bookedSlots := reviewer.Bookings[slotIndex]
+func IsBooked(reviewer models.Reviewer, occurrence models.SlotOccurrence) bool {
+	_, ok := reviewer.Bookings[occurrence.Key()]
+	return ok
+}
 
-	var slotBookings = make([]SlotBooking, 0, len(availableSlots))
-	for _, slotID := range availableSlots {
-		isBooked := false
-		for _, bookedID := range bookedSlots {
-			if bookedID == slotID {
-				isBooked = true
-				break
-			}
+func GetBooking(reviewer models.Reviewer, occurrence models.SlotOccurrence) (models.Booking, bool) {
+	booking, ok := reviewer.Bookings[occurrence.Key()]
+	return booking, ok
+}
+
+func GetAvailableSlots(reviewer models.Reviewer, challenge models.ChallengeSetup, weekStart time.Time) []SlotBooking {
+	challengeSlots := challenge.GetSlotsInOrder()
+
+	var slotBookings = make([]SlotBooking, 0, len(challengeSlots))
+	for _, slot := range challengeSlots {
+		occurrence, err := slot.OccurrenceInWeek(weekStart)
+		if err != nil {
+			log.Println("[ERROR] Invalid slot in challenge - ", err)
+			continue
+		}
+		if !IsAvailable(reviewer, occurrence) {
+			continue
 		}
+		booking, isBooked := GetBooking(reviewer, occurrence)
 		slotBookings = append(slotBookings, SlotBooking{
-			SlotID:   slotID,
-			WeekNo:   weekNo,
-			Year:     year,
-			IsBooked: isBooked,
+			Occurrence: occurrence,
+			IsBooked:   isBooked,
+			Booking:    booking,
 		})
 	}
 
 	return slotBookings
 }
 
-func SlotsForWeek(week, year int, reviewer models.Reviewer, challenge models.ChallengeSetup) []SlotInfo {
-	slotIndex := SlotIndex(week, year)
+// GeneralSlots returns the recurring schedule of the reviewer, that applies to every week unless overridden
+func GeneralSlots(reviewer models.Reviewer, challenge models.ChallengeSetup) []SlotInfo {
+	challengeSlots := challenge.GetSlotsInOrder()
+
+	slots := make([]SlotInfo, 0, len(challengeSlots))
+	for _, slot := range challengeSlots {
+		slots = append(slots, SlotInfo{
+			Slot:       *slot,
+			IsSelected: containsSlot(reviewer.GeneralAvailability, slot.ID),
+		})
+	}
+	return slots
+}
 
-	slots := make([]SlotInfo, 0, len(challenge.Slots))
-	availableSlots := reviewer.Availability[slotIndex]
+func SlotsForWeek(weekStart time.Time, reviewer models.Reviewer, challenge models.ChallengeSetup) []SlotInfo {
 	challengeSlots := challenge.GetSlotsInOrder()
 
-	if availableSlots == nil || len(availableSlots) == 0 {
-		for _, slot := range challengeSlots {
-			slots = append(slots, SlotInfo{
-				Slot:       *slot,
-				IsSelected: isSelected(*slot, reviewer.Availability["General"]),
-			})
-		}
-	} else {
-		for _, slot := range challengeSlots {
-			slots = append(slots, SlotInfo{
-				Slot:       *slot,
-				IsSelected: isSelected(*slot, availableSlots),
-			})
+	slots := make([]SlotInfo, 0, len(challengeSlots))
+	for _, slot := range challengeSlots {
+		occurrence, err := slot.OccurrenceInWeek(weekStart)
+		if err != nil {
+			log.Println("[ERROR] Invalid slot in challenge - ", err)
+			continue
 		}
+		slots = append(slots, SlotInfo{
+			Slot:       *slot,
+			Occurrence: occurrence,
+			IsSelected: IsAvailable(reviewer, occurrence),
+		})
 	}
 
 	return slots
 }
 
-func isSelected(slot models.Slot, availableSlots []string) bool {
-	for _, slotID := range availableSlots {
-		if slotID == slot.ID {
+func containsSlot(slots []string, slotID string) bool {
+	for _, id := range slots {
+		if id == slotID {
 			return true
 		}
 	}
@@ -109,20 +152,23 @@ type DayOfWeek = string
 type ReviewerInfo struct {
 	Reviewer models.Reviewer
 	IsBooked bool
+	Booking  models.Booking
 }
 type SlotAvailability struct {
-	Slot      *models.Slot
-	Reviewers []ReviewerInfo
+	Slot       *models.Slot
+	Occurrence models.SlotOccurrence
+	Reviewers  []ReviewerInfo
 }
 
-func newSlotAvailability(slot *models.Slot) *SlotAvailability {
+func newSlotAvailability(slot *models.Slot, occurrence models.SlotOccurrence) *SlotAvailability {
 	return &SlotAvailability{
-		Slot:      slot,
-		Reviewers: make([]ReviewerInfo, 0, 100),
+		Slot:       slot,
+		Occurrence: occurrence,
+		Reviewers:  make([]ReviewerInfo, 0, 100),
 	}
 }
 
-func FindAvailableReviewers(env config.Environment, challengeID string, tech string, weekNo, year int) (map[DayOfWeek]map[string]*SlotAvailability, error) {
+func FindAvailableReviewers(env config.Environment, challengeID string, tech string, weekStart time.Time) (map[DayOfWeek]map[string]*SlotAvailability, error) {
 	challenge, err := models.GetChallengeSetupByID(env, challengeID)
 	if err != nil {
 		log.Println("[ERROR] Cannot find the challenge setup - ", challengeID)
@@ -135,32 +181,34 @@ func FindAvailableReviewers(env config.Environment, challengeID string, tech str
 		return nil, err
 	}
 
+	taxonomy, err := models.GetTaxonomy(env)
+	if err != nil {
+		log.Println("[ERROR] Cannot load the tags, matching technologies by name - ", err)
+	}
+
 	selectedReviewers := make(map[DayOfWeek]map[string]*SlotAvailability)
 	for _, reviewer := range reviewers {
-		if tech != "" && !strings.Contains(reviewer.TechnologyList, tech) {
+		if technologyScore(taxonomy, reviewer, tech) == 0 {
 			continue
 		}
 
-		slotBookings := GetAvailableSlots(reviewer, weekNo, year)
+		slotBookings := GetAvailableSlots(reviewer, challenge, weekStart)
 
 		for _, slotBooking := range slotBookings {
-			slotID := slotBooking.SlotID
+			slotID := slotBooking.Occurrence.SlotID
 			isBooked := slotBooking.IsBooked
 			slot := challenge.Slots[slotID]
-			if slot == nil {
-				log.Println("[ERROR] Invalid slot name for reviewer - ", slotID)
-				continue
-			}
 			if selectedReviewers[slot.Day] == nil {
 				selectedReviewers[slot.Day] = make(map[string]*SlotAvailability)
 			}
 			if selectedReviewers[slot.Day][slotID] == nil {
-				selectedReviewers[slot.Day][slotID] = newSlotAvailability(slot)
+				selectedReviewers[slot.Day][slotID] = newSlotAvailability(slot, slotBooking.Occurrence)
 			}
 
 			reviewerInfo := ReviewerInfo{
 				Reviewer: reviewer,
 				IsBooked: isBooked,
+				Booking:  slotBooking.Booking,
 			}
 			selectedReviewers[slot.Day][slotID].Reviewers = append(selectedReviewers[slot.Day][slotID].Reviewers, reviewerInfo)
 		}
@@ -169,69 +217,85 @@ func FindAvailableReviewers(env config.Environment, challengeID string, tech str
 	return selectedReviewers, nil
 }
 
-func UpdateReviewerAvailability(env config.Environment, reviewer models.Reviewer, ref SlotReference) (models.Reviewer, error) {
-	slotIndex := SlotIndex(ref.WeekNo, ref.Year)
-	slots := initializeSlots(slotIndex, reviewer)
+func UpdateGeneralAvailability(env config.Environment, reviewer models.Reviewer, slotID string, available bool) (models.Reviewer, error) {
+	slots := reviewer.GeneralAvailability
+	if slots == nil {
+		slots = make([]string, 0, 50)
+	}
 
-	var newSlots []string
-	if ref.Available {
-		newSlots = addSlot(slots, ref.SlotID)
+	if available {
+		reviewer.GeneralAvailability = addSlot(slots, slotID)
 	} else {
-		newSlots = removeSlot(slots, ref.SlotID)
+		reviewer.GeneralAvailability = removeSlot(slots, slotID)
 	}
 
-	reviewer.Availability[slotIndex] = newSlots
 	err := models.UpdateReviewer(env, reviewer)
+	return reviewer, err
+}
+
+func UpdateReviewerAvailability(env config.Environment, reviewer models.Reviewer, ref SlotReference) (models.Reviewer, error) {
+	reviewer = setAvailability(reviewer, ref)
 
+	err := models.UpdateReviewer(env, reviewer)
 	return reviewer, err
 }
 
-func initializeSlots(slotIndex string, reviewer models.Reviewer) []string {
-	var slots []string
-	if slotIndex == "General" {
-		// Get General or if not create empty
-		slots = reviewer.Availability[slotIndex]
-		if slots == nil {
-			slots = make([]string, 0, 50)
-		}
-	} else {
-		// Get the slot if not copy General or if general does not exist init with zero
-		slots = reviewer.Availability[slotIndex]
-		if slots == nil {
-			slots = reviewer.Availability["General"]
-			if slots == nil {
-				slots = make([]string, 0, 50)
-			}
-		}
+func setAvailability(reviewer models.Reviewer, ref SlotReference) models.Reviewer {
+	if reviewer.Availability == nil {
+		reviewer.Availability = make(map[string]bool)
 	}
 
-	return slots
+	key := ref.Occurrence.Key()
+	delete(reviewer.Availability, key)
+	if ref.Available != IsAvailable(reviewer, ref.Occurrence) {
+		// Only override when it differs from the general availability and rules
+		reviewer.Availability[key] = ref.Available
+	}
+	return reviewer
 }
 
+// UpdateReviewerBooking books the reviewer with the details in ref.Booking, or removes the booking
 func UpdateReviewerBooking(env config.Environment, reviewer models.Reviewer, ref SlotBooking) (models.Reviewer, error) {
-	slotIndex := SlotIndex(ref.WeekNo, ref.Year)
-	slots := reviewer.Bookings[slotIndex]
-	if slots == nil {
-		slots = make([]string, 0, 20)
+	if reviewer.Bookings == nil {
+		reviewer.Bookings = make(map[string]models.Booking)
 	}
-	maxBookings := reviewer.BookingsPerWeek
 
-	var newSlots []string
+	key := ref.Occurrence.Key()
 	if ref.IsBooked {
-		if len(slots) >= maxBookings {
-			return reviewer, MaxBookingsError{}
+		if _, ok := reviewer.Bookings[key]; !ok {
+			if err := CheckCapacity(reviewer, ref.Occurrence); err != nil {
+				return reviewer, err
+			}
+		}
+		booking := ref.Booking
+		if booking.ID == "" {
+			booking = models.NewBooking(reviewer.SlackID, ref.Occurrence, map[string]string{})
 		}
-		newSlots = addSlot(slots, ref.SlotID)
+		booking.ReviewerID = reviewer.SlackID
+		booking.Occurrence = ref.Occurrence
+		reviewer.Bookings[key] = booking
 	} else {
-		newSlots = removeSlot(slots, ref.SlotID)
+		delete(reviewer.Bookings, key)
 	}
 
-	reviewer.Bookings[slotIndex] = newSlots
 	err := models.UpdateReviewer(env, reviewer)
-
 	return reviewer, err
 }
 
+func bookingsInWeek(reviewer models.Reviewer, weekStart time.Time) int {
+	count := 0
+	for _, booking := range reviewer.Bookings {
+		if !booking.IsForChallenge(reviewer.ChallengeID) {
+			continue
+		}
+		bookingWeek, err := WeekOfOccurrence(booking.Occurrence)
+		if err == nil && bookingWeek.Equal(weekStart) {
+			count++
+		}
+	}
+	return count
+}
+
 func addSlot(slots []string, newSlot string) []string {
 	for _, slot := range slots {
 		if slot == newSlot {
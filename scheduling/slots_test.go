@@ -0,0 +1,82 @@
+package scheduling
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestChallenge() models.ChallengeSetup {
+	return models.ChallengeSetup{
+		ID:       "Test-123",
+		TimeZone: "Europe/Berlin",
+		Slots: map[models.SlotID]*models.Slot{
+			"MondayMorning": &models.Slot{ID: "MondayMorning", Ordinal: 0, Day: "Monday", StartTime: "9:00", EndTime: "11:00", TimeZone: "Europe/Berlin"},
+			"FridayMorning": &models.Slot{ID: "FridayMorning", Ordinal: 1, Day: "Friday", StartTime: "9:00", EndTime: "11:00", TimeZone: "Europe/Berlin"},
+		},
+	}
+}
+
+func TestStartOfWeekAroundNewYear(t *testing.T) {
+	sunday := time.Date(2020, 1, 5, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2019, 12, 30, 0, 0, 0, 0, time.UTC), StartOfWeek(sunday))
+
+	week53 := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, weekNo := week53.ISOWeek()
+	assert.Equal(t, 53, weekNo)
+	assert.Equal(t, time.Date(2020, 12, 28, 0, 0, 0, 0, time.UTC), StartOfWeek(week53))
+}
+
+func TestSlotsForWeekSpanningNewYear(t *testing.T) {
+	reviewer := models.Reviewer{
+		GeneralAvailability: []models.SlotID{"MondayMorning"},
+		Availability: map[string]bool{
+			"2020-01-03_FridayMorning": true,
+		},
+	}
+
+	slots := SlotsForWeek(time.Date(2019, 12, 30, 0, 0, 0, 0, time.UTC), reviewer, newTestChallenge())
+
+	assert.Equal(t, 2, len(slots))
+	assert.Equal(t, models.SlotOccurrence{Date: "2019-12-30", SlotID: "MondayMorning"}, slots[0].Occurrence)
+	assert.True(t, slots[0].IsSelected)
+	assert.Equal(t, models.SlotOccurrence{Date: "2020-01-03", SlotID: "FridayMorning"}, slots[1].Occurrence)
+	assert.True(t, slots[1].IsSelected)
+}
+
+func TestBookingsCountedPerISOWeek(t *testing.T) {
+	reviewer := models.Reviewer{
+		Bookings: map[string]models.Booking{
+			"2019-12-30_MondayMorning": models.Booking{Occurrence: models.SlotOccurrence{Date: "2019-12-30", SlotID: "MondayMorning"}},
+			"2020-01-03_FridayMorning": models.Booking{Occurrence: models.SlotOccurrence{Date: "2020-01-03", SlotID: "FridayMorning"}},
+			"2020-01-06_MondayMorning": models.Booking{Occurrence: models.SlotOccurrence{Date: "2020-01-06", SlotID: "MondayMorning"}},
+		},
+	}
+
+	assert.Equal(t, 2, bookingsInWeek(reviewer, time.Date(2019, 12, 30, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 1, bookingsInWeek(reviewer, time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestChallengeOfSlotIsUnambiguous(t *testing.T) {
+	reviewer := models.Reviewer{
+		Name: "Jane",
+		Challenges: []models.ChallengeMembership{
+			models.ChallengeMembership{ChallengeID: "Test-123", BookingsPerWeek: 2},
+			models.ChallengeMembership{ChallengeID: "Other-456", BookingsPerWeek: 1},
+		},
+	}
+	other := newTestChallenge()
+	other.ID = "Other-456"
+
+	reviewer, challenge, err := challengeOfSlot(reviewer, "MondayMorning", []models.ChallengeSetup{other})
+	assert.Nil(t, err)
+	assert.Equal(t, "Other-456", challenge.ID)
+	assert.Equal(t, 1, reviewer.BookingsPerWeek)
+
+	_, _, err = challengeOfSlot(reviewer, "MondayMorning", []models.ChallengeSetup{newTestChallenge(), other})
+	assert.NotNil(t, err)
+	_, _, err = challengeOfSlot(reviewer, "MondayMorning", nil)
+	assert.NotNil(t, err)
+}
@@ -2,6 +2,7 @@ package slackops
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -10,141 +11,356 @@ import (
 	"github.com/nlopes/slack"
 )
 
+// command is a parsed slash command. arg is the value of its first argument, args and flags have the values of all
+// of them by their names.
 type command struct {
 	ctx      commCtx
 	command  string
 	sub      string
 	arg      string
+	args     map[string]string
+	flags    map[string]string
+	group    commandGroup
 	slashCmd *slack.SlashCommand
+	// share posts the replies in the channel, otherwise they are only visible to the user
+	share bool
 }
 
-func newCommand(env config.Environment, slashCommand *slack.SlashCommand) command {
-	sub, arg := parseSlashCommand(slashCommand)
-	if sub == "" {
-		sub = "help"
-	}
+// The optional user mention of the reviewer commands, the user of the command if it is omitted
+var reviewerMention = argSpec{Name: "SLACKID", Kind: mentionArg, Optional: true}
+
+var helpCommand = commandSpec{
+	Name:        "help",
+	Args:        []argSpec{{Name: "COMMAND", Optional: true}},
+	Description: "Displays this message, or the usage of the COMMAND",
+	Run:         command.executeHelp,
+}
+
+var commandGroups = []commandGroup{
+	{
+		Name:    "/challenge",
+		Aliases: []string{"/challengetest"},
+		Commands: []commandSpec{
+			helpCommand,
+			{
+				Name:        "list",
+				Description: "Lists the challenges with their Github account, reviewers and candidates in flight",
+				Run:         command.executeListChallenges,
+			},
+			{
+				Name:        "show",
+				Args:        []argSpec{{Name: "CHALLENGENAME"}},
+				Description: "Shows the template repository, slots, reviewers and candidates in flight of the challenge with the name CHALLENGENAME",
+				Run:         command.executeShowChallenge,
+			},
+			{
+				Name:        "new",
+				Description: "Opens a dialog to create a new challenge",
+				Run:         command.executeNewChallenge,
+			},
+			{
+				Name:        "edit",
+				Args:        []argSpec{{Name: "CHALLENGENAME"}},
+				Description: "Edits the challenge with the name CHALLENGENAME",
+				Run:         command.executeEditChallenge,
+			},
+			{
+				Name:        "delete",
+				Args:        []argSpec{{Name: "CHALLENGENAME"}},
+				Description: "Deletes the challenge with the name CHALLENGENAME after you confirm, and removes it from its reviewers. Challenges with candidates in flight or upcoming bookings cannot be deleted",
+				Run:         command.executeDeleteChallenge,
+			},
+			{
+				Name:        "send",
+				Args:        []argSpec{{Name: "CHALLENGENAME", Optional: true}},
+				Description: "Opens a dialog to send a challenge to a candidate",
+				Run:         command.executeSendChallenge,
+			},
+			{
+				Name:        "slots",
+				Args:        []argSpec{{Name: "CHALLENGENAME"}},
+				Description: "Shows the interview slots of the challenge, to add, remove and reorder them",
+				Run:         command.executeChallengeSlots,
+			},
+			{
+				Name:        "tags",
+				Description: "Shows the technology tags reviewers pick from, to add them and edit their aliases and broader technology",
+				Run:         command.executeShowTags,
+			},
+			{
+				Name:        "pairing",
+				Args:        []argSpec{{Name: "GITHUBALIAS"}},
+				Description: "Invites the candidate with the Github alias to pick a live pairing session with their reviewers, through an issue in their challenge repository",
+				Run:         command.executeInvitePairing,
+			},
+		},
+	},
+	{
+		Name:    "/reviewer",
+		Aliases: []string{"/reviewertest"},
+		Commands: []commandSpec{
+			helpCommand,
+			{
+				Name:        "new",
+				Description: "Opens a dialog to register a reviewer, or to add a challenge to a registered reviewer",
+				Run:         command.executeNewReviewer,
+			},
+			{
+				Name:        "edit",
+				Args:        []argSpec{reviewerMention},
+				Description: "Opens a dialog to edit the reviewer you specified with SLACKID. Picking another challenge adds it to the reviewer. If SLACKID is omitted, it assumes you are the reviewer",
+				Run:         command.executeEditReviewer,
+			},
+			{
+				Name:        "challenges",
+				Args:        []argSpec{reviewerMention},
+				Description: "Shows the challenges of the reviewer with their skills and capacity, to edit or leave them. If SLACKID is omitted, assumes you are the reviewer",
+				Run:         command.executeShowChallenges,
+			},
+			{
+				Name:        "tags",
+				Args:        []argSpec{reviewerMention},
+				Description: "Shows the technology tags of the reviewer for each of their challenges, to pick them. If SLACKID is omitted, assumes you are the reviewer",
+				Run:         command.executeReviewerTags,
+			},
+			{
+				Name:        "pause",
+				Args:        []argSpec{reviewerMention},
+				Flags:       []flagSpec{{Name: "until", Value: argSpec{Name: "DATE", Kind: dateArg}}},
+				Description: "Pauses the reviewer, e.g. for a leave, they are not offered for bookings until DATE (included) or until they are resumed. If SLACKID is omitted, assumes you are the reviewer",
+				Run:         command.executePauseReviewer,
+			},
+			{
+				Name:        "resume",
+				Args:        []argSpec{reviewerMention},
+				Description: "Ends the pause of the reviewer. If SLACKID is omitted, assumes you are the reviewer",
+				Run:         command.executeResumeReviewer,
+			},
+			{
+				Name:        "remove",
+				Args:        []argSpec{{Name: "SLACKID", Kind: mentionArg}},
+				Description: "Removes the reviewer after you confirm, e.g. when they leave. Their upcoming bookings are listed with the reviewers that can take them over",
+				Run:         command.executeRemoveReviewer,
+			},
+			{
+				Name:        "find",
+				Description: "Opens a dialog to find reviewers and book them",
+				Run:         command.executeFindReviewers,
+			},
+			{
+				Name:        "assign",
+				Description: "Opens a dialog to pick the best matching pair of reviewers for a candidate and book them in one click",
+				Run:         command.executeAssignReviewers,
+			},
+			{
+				Name:        "need",
+				Description: "Opens a dialog to register the reviewers a candidate needs in a week. Matching reviewers are offered a slot when one opens, the first ones to accept are booked",
+				Run:         command.executeNewNeed,
+			},
+			{
+				Name:        "needs",
+				Description: "Shows the registered needs with the reviewers booked so far, to cancel them",
+				Run:         command.executeShowNeeds,
+			},
+			{
+				Name:        "schedule",
+				Args:        []argSpec{reviewerMention},
+				Description: "Opens a dialog to setup a reviewer schedule for all weeks or a specific week. If SLACKID is omitted, assumes you are the reviewer",
+				Run:         command.executeSchedule,
+			},
+			{
+				Name:        "bookings",
+				Args:        []argSpec{reviewerMention},
+				Description: "Shows all active bookings for the reviewer with SLACKID. If SLACKID is omitted, assumes you are the reviewer",
+				Run:         command.executeShowBookings,
+			},
+			{
+				Name:        "rule",
+				Args:        []argSpec{reviewerMention},
+				Description: "Opens a dialog to add a recurring availability, e.g. every other Tuesday afternoon or only in March. If SLACKID is omitted, assumes you are the reviewer",
+				Run:         command.executeNewRule,
+			},
+			{
+				Name:        "away",
+				Args:        []argSpec{reviewerMention},
+				Description: "Opens a dialog to add out of office dates, no bookings can be made for those days. If SLACKID is omitted, assumes you are the reviewer",
+				Run:         command.executeOutOfOffice,
+			},
+			{
+				Name:        "import",
+				Args:        []argSpec{reviewerMention},
+				Description: "Opens a dialog to import a calendar (.ics) file, slots that conflict with it are marked as unavailable after you review them. If SLACKID is omitted, assumes you are the reviewer",
+				Run:         command.executeImportCalendar,
+			},
+			{
+				Name:        "rules",
+				Args:        []argSpec{reviewerMention},
+				Description: "Shows the recurring availability and out of office dates of the reviewer. If SLACKID is omitted, assumes you are the reviewer",
+				Run:         command.executeShowRules,
+			},
+			{
+				Name:        "load",
+				Args:        []argSpec{{Name: "WEEKS", Kind: intArg, Optional: true}},
+				Description: "Shows the bookings of all reviewers in the last WEEKS weeks (8 if omitted) against their caps, to spot overloaded and unused reviewers",
+				Run:         command.executeLoadReport,
+			},
+			{
+				Name:        "reminders",
+				Args:        []argSpec{{Name: "STATUS", Kind: choiceArg, Choices: []string{"on", "off"}, Optional: true}},
+				Description: "Turns your booking reminders (a day and an hour before) and the Monday weekly digest on or off",
+				Run:         command.executeReminders,
+			},
+		},
+	},
+	{
+		Name:    "/candidate",
+		Aliases: []string{"/candidatetest"},
+		Commands: []commandSpec{
+			helpCommand,
+			{
+				Name: "list",
+				Args: []argSpec{
+					{Name: "CHALLENGENAME", Optional: true},
+					{Name: "STATE", Kind: choiceArg, Choices: candidateStates, Optional: true},
+				},
+				Description: "Lists the candidates of all challenges or the challenge CHALLENGENAME, the ones in flight unless a STATE is given",
+				Run:         command.executeListCandidates,
+			},
+			{
+				Name:        "show",
+				Args:        []argSpec{{Name: "NAME"}},
+				Description: "Shows the candidate with the name or Github alias NAME, with their repository, reviewers, deadline, build and review verdicts",
+				Run:         command.executeShowCandidate,
+			},
+			{
+				Name:        "extend",
+				Args:        []argSpec{{Name: "NAME"}, {Name: "DAYS", Kind: intArg}},
+				Description: "Extends the deadline of the candidate NAME by DAYS days, the candidate is told through an issue in their repository",
+				Run:         command.executeExtendCandidate,
+			},
+			{
+				Name:        "withdraw",
+				Args:        []argSpec{{Name: "NAME"}},
+				Description: "Withdraws the candidate NAME from the process, they lose their access to the challenge repository and the reviewers are told",
+				Run:         command.executeWithdrawCandidate,
+			},
+		},
+	},
+}
+
+func newCommand(env config.Environment, slashCommand *slack.SlashCommand, group commandGroup) command {
 	ctx := newCommCtx(env, slashCommand.UserID, slashCommand.TeamID, false)
 
 	return command{
 		ctx:      ctx,
 		command:  slashCommand.Command,
-		sub:      sub,
-		arg:      arg,
+		group:    group,
 		slashCmd: slashCommand,
 	}
 }
 
 func ExecuteCommand(env config.Environment, request *http.Request) error {
-	slashCommand, err := parsePayload(request, env.VerificationToken)
+	slashCommand, err := parsePayload(request)
 	if err != nil {
 		return err
 	}
 
-	c := newCommand(env, slashCommand)
-	log.Printf("[INFO] Main Command %s, Sub Command %s, Text %s", c.command, c.sub, c.arg)
-
-	switch c.command {
-	case "/challenge":
-		fallthrough
-	case "/challengetest":
-		switch c.sub {
-		case "help":
-			go c.executeChallengeHelp()
-		case "new":
-			go c.executeNewChallenge()
-		case "edit":
-			go c.executeEditChallenge()
-		case "send":
-			go c.executeSendChallenge()
-		}
-	case "/reviewer":
-		fallthrough
-	case "/reviewertest":
-		switch c.sub {
-		case "help":
-			go c.executeReviewerHelp()
-		case "new":
-			go c.executeNewReviewer()
-		case "edit":
-			go c.executeEditReviewer()
-		case "schedule":
-			go c.executeSchedule()
-		case "find":
-			go c.executeFindReviewers()
-		case "bookings":
-			go c.executeShowBookings()
-		default:
-			log.Println("[ERROR] Unexpected Command ", c.command)
-			return errors.New("Unexpected command")
-		}
+	group, ok := findCommandGroup(slashCommand.Command)
+	if !ok {
+		log.Println("[ERROR] Unexpected Command ", slashCommand.Command)
+		return errors.New("Unexpected command")
 	}
-	return nil
-}
+	c := newCommand(env, slashCommand, group)
+	log.Printf("[INFO] Main Command %s, Text %s", c.command, slashCommand.Text)
 
-func parsePayload(request *http.Request, verificationToken string) (*slack.SlashCommand, error) {
-	s, err := slack.SlashCommandParse(request)
+	if _, err := c.ctx.getToken(); err == errAppNotInstalled {
+		return respond(slashCommand.ResponseURL, reinstallMessage(env))
+	}
+
+	words, err := tokenize(slashCommand.Text)
 	if err != nil {
-		log.Println("[ERROR] Unable to parse command ", err)
-		return nil, err
+		go c.replyUsage(helpCommand, err)
+		return nil
+	}
+	words, c.share = takeShareFlag(words)
+	c.sub = "help"
+	if len(words) > 0 {
+		c.sub = strings.ToLower(words[0])
+		words = words[1:]
 	}
 
-	if !s.ValidateToken(verificationToken) {
-		log.Println("[ERROR] Unable to validate command ", err)
-		return nil, ValidationError{}
+	spec, ok := group.find(c.sub)
+	if !ok {
+		go c.replyUnknown()
+		return nil
 	}
-	return &s, nil
+	c.args, c.flags, err = spec.parse(words)
+	if err != nil {
+		go c.replyUsage(spec, err)
+		return nil
+	}
+	if len(spec.Args) > 0 {
+		c.arg = c.args[spec.Args[0].Name]
+	}
+
+	go spec.Run(c)
+	return nil
 }
 
-func parseSlashCommand(slashCommand *slack.SlashCommand) (string, string) {
-	if len(slashCommand.Text) == 0 {
-		return "", ""
+func findCommandGroup(name string) (commandGroup, bool) {
+	for _, group := range commandGroups {
+		if group.isNamed(name) {
+			return group, true
+		}
 	}
+	return commandGroup{}, false
+}
 
-	c := strings.Split(slashCommand.Text, " ")
-	switch len(c) {
-	case 1:
-		return c[0], ""
-	case 2:
-		return c[0], c[1]
-	default:
-		return "", ""
+func (c command) executeHelp() error {
+	if c.arg == "" {
+		return c.reply(renderCommandHelp(c.command, c.group))
+	}
+	spec, ok := c.group.find(strings.ToLower(c.arg))
+	if !ok {
+		c.sub = c.arg
+		return c.replyUnknown()
 	}
+	return c.reply(renderUsage(c.command, spec, nil))
 }
 
-func newExternalOptionsDialogInput(name, label, value string, optional bool) *slack.DialogInputSelect {
-	return &slack.DialogInputSelect{
-		DialogInput: slack.DialogInput{
-			Type:     slack.InputTypeSelect,
-			Name:     name,
-			Label:    label,
-			Optional: optional,
-		},
-		DataSource: slack.DialogDataSourceExternal,
+// replyUnknown suggests the subcommand that was most likely meant
+func (c command) replyUnknown() error {
+	msg := fmt.Sprintf("There is no %s %s command.", c.command, c.sub)
+	if suggestion := c.group.suggest(c.sub); suggestion != "" {
+		msg = fmt.Sprintf("%s Did you mean *%s %s*?", msg, c.command, suggestion)
 	}
+	msg = fmt.Sprintf("%s Type *%s help* to see all the commands.", msg, c.command)
+	return c.reply(toMsgOption(msg))
 }
 
-func newStaticOptionsDialogInput(name, label, value string, optional bool, options []slack.DialogSelectOption) *slack.DialogInputSelect {
-	return &slack.DialogInputSelect{
-		DialogInput: slack.DialogInput{
-			Type:     slack.InputTypeSelect,
-			Name:     name,
-			Label:    label,
-			Optional: optional,
-		},
-		DataSource: slack.DialogDataSourceStatic,
-		Options:    options,
-		Value:      value,
+func (c command) replyUsage(spec commandSpec, err error) error {
+	return c.reply(renderUsage(c.command, spec, err))
+}
+
+// reply answers the user through the response URL of the command, so it works in the channels the bot is not a
+// member of. It is posted in the channel only if the user chose to share it.
+func (c command) reply(msgOption slack.MsgOption) error {
+	if c.share {
+		return c.ctx.postMessage(c.slashCmd.ChannelID, msgOption)
+	}
+	err := respondMessage(c.slashCmd.ResponseURL, msgOption, false)
+	if err != nil {
+		log.Println("[ERROR] Cannot reply to the command, replying ephemerally - ", err)
+		return c.ctx.postEphemeral(c.slashCmd.ChannelID, c.slashCmd.UserID, msgOption)
 	}
+	return nil
 }
 
-func newUsersSelect(name, label string, optional bool) *slack.DialogInputSelect {
-	return &slack.DialogInputSelect{
-		DialogInput: slack.DialogInput{
-			Type:     slack.InputTypeSelect,
-			Label:    label,
-			Name:     name,
-			Optional: optional,
-		},
-		DataSource: slack.DialogDataSourceUsers,
+func parsePayload(request *http.Request) (*slack.SlashCommand, error) {
+	s, err := slack.SlashCommandParse(request)
+	if err != nil {
+		log.Println("[ERROR] Unable to parse command ", err)
+		return nil, err
 	}
+	return &s, nil
 }
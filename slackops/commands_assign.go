@@ -0,0 +1,37 @@
+package slackops
+
+import (
+	"time"
+
+	"github.com/keremk/challenge-bot/scheduling"
+)
+
+func (c command) executeAssignReviewers() error {
+	technologyEl := technologyDialogInput(c.ctx.Env, "Technology")
+	dialog := newAssignDialog(technologyEl)
+
+	return c.ctx.openModal(c.slashCmd.TriggerID, c.slashCmd.ChannelID, dialog)
+}
+
+func newAssignDialog(technologyEl *modalInput) modal {
+	candidateNameEl := newTextInput("candidate_name", "Candidate Name", "")
+	challengeNameEl := newExternalSelectInput("challenge_id", "Challenge Name", "", false)
+	experienceEl := newStaticSelectInput("experience", "Candidate Experience Level", "1", true, experienceOptions())
+	weekOfYearDefault := encodeWeek(scheduling.FirstDayOfWeek(time.Now()))
+	weekOfYearEl := newStaticSelectInput("year_week", "Week of the Year", weekOfYearDefault, true, weekOfYearOptions(false))
+
+	elements := []*modalInput{
+		candidateNameEl,
+		challengeNameEl,
+		technologyEl,
+		experienceEl,
+		weekOfYearEl,
+	}
+	return modal{
+		CallbackID:  "assign_reviewers",
+		Title:       "Assign Reviewers",
+		SubmitLabel: "Propose",
+		State:       "",
+		Elements:    elements,
+	}
+}
@@ -0,0 +1,232 @@
+package slackops
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/repo"
+	"github.com/nlopes/slack"
+)
+
+// The STATE of /candidate list, all lists the candidates in any state
+var candidateStates = []string{
+	models.CandidateSent,
+	models.CandidateOverdue,
+	models.CandidateSubmitted,
+	models.CandidateClosed,
+	models.CandidateWithdrawn,
+	"all",
+}
+
+// Longest extension of a deadline in days
+const maxExtensionDays = 60
+
+func (c command) executeListCandidates() error {
+	challengeName, state := c.args["CHALLENGENAME"], c.args["STATE"]
+	if state == "" && isCandidateState(challengeName) {
+		// Only the state is given, e.g. /candidate list overdue
+		if _, err := models.GetChallengeSetupByName(c.ctx.Env, challengeName); err != nil {
+			challengeName, state = "", strings.ToLower(challengeName)
+		}
+	}
+
+	var candidates []models.Candidate
+	var err error
+	if challengeName == "" {
+		candidates, err = models.GetAllCandidates(c.ctx.Env)
+	} else {
+		var challenge models.ChallengeSetup
+		challenge, err = models.GetChallengeSetupByName(c.ctx.Env, challengeName)
+		if err != nil {
+			log.Println("[ERROR] No such challenge is registered.", err)
+			errorMsg := fmt.Sprintf("Challenge named %s is not registered. Type /challenge list to see all the challenges.", challengeName)
+			return c.reply(toMsgOption(errorMsg))
+		}
+		candidates, err = models.GetCandidatesForChallenge(c.ctx.Env, challenge.ID)
+	}
+	if err != nil {
+		log.Println("[ERROR] Cannot get the candidates - ", err)
+		c.reply(toMsgOption("There was an error. The candidates cannot be listed."))
+		return err
+	}
+
+	now := time.Now()
+	selected := make([]models.Candidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if matchesCandidateState(candidate, state, now) {
+			selected = append(selected, candidate)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].DueDate().Before(selected[j].DueDate()) })
+
+	title := "Candidates in flight"
+	if state != "" {
+		title = fmt.Sprintf("Candidates (%s)", state)
+	}
+	if challengeName != "" {
+		title = fmt.Sprintf("%s of %s", title, challengeName)
+	}
+	sections := renderCandidateList(title, selected, candidateChallengeNames(c.ctx, selected), now, c.ctx.getUserLocation(c.slashCmd.UserID))
+	return c.reply(slack.MsgOptionBlocks(sections...))
+}
+
+func isCandidateState(text string) bool {
+	for _, state := range candidateStates {
+		if strings.EqualFold(state, text) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesCandidateState checks the state of the candidate, no state matches the candidates in flight
+func matchesCandidateState(candidate models.Candidate, state string, now time.Time) bool {
+	switch state {
+	case "":
+		return candidate.InFlight()
+	case "all":
+		return true
+	default:
+		return candidate.State(now) == state
+	}
+}
+
+func (c command) executeShowCandidate() error {
+	candidate, err := c.findCandidate()
+	if err != nil {
+		return err
+	}
+
+	sections := renderCandidateCard(candidate, candidateChallengeNames(c.ctx, []models.Candidate{candidate})[candidate.ChallengeID],
+		time.Now(), c.ctx.getUserLocation(c.slashCmd.UserID))
+	return c.reply(slack.MsgOptionBlocks(sections...))
+}
+
+func (c command) executeExtendCandidate() error {
+	days, _ := strconv.Atoi(c.args["DAYS"])
+	if days <= 0 || days > maxExtensionDays {
+		errorMsg := fmt.Sprintf("Please give the number of days between 1 and %d, e.g. /candidate extend NAME 3", maxExtensionDays)
+		return c.reply(toMsgOption(errorMsg))
+	}
+	candidate, err := c.findCandidate()
+	if err != nil {
+		return err
+	}
+
+	candidate, err = extendCandidate(c.ctx, candidate, days)
+	if err != nil {
+		c.reply(toMsgOption(err.Error()))
+		return err
+	}
+	loc := c.ctx.getUserLocation(c.slashCmd.UserID)
+	msg := fmt.Sprintf("The deadline of %s is extended to %s.", candidate.Name, candidate.DueDate().In(loc).Format("Monday, 2 January"))
+	return c.reply(toMsgOption(msg))
+}
+
+func (c command) executeWithdrawCandidate() error {
+	candidate, err := c.findCandidate()
+	if err != nil {
+		return err
+	}
+
+	candidate, err = withdrawFromChallenge(c.ctx, candidate, c.slashCmd.UserID)
+	if err != nil {
+		c.reply(toMsgOption(err.Error()))
+		return err
+	}
+	msg := fmt.Sprintf("%s is withdrawn, they cannot access the challenge repository anymore.", candidate.Name)
+	return c.reply(toMsgOption(msg))
+}
+
+// findCandidate finds the candidate of the NAME argument, telling the user if there is none
+func (c command) findCandidate() (models.Candidate, error) {
+	name := c.args["NAME"]
+	candidate, err := models.FindCandidate(c.ctx.Env, name)
+	if err != nil {
+		log.Println("[ERROR] No such candidate - ", name, err)
+		errorMsg := fmt.Sprintf("No challenge was sent to a candidate with the name or Github alias %s. Type /candidate list to see the candidates in flight.", name)
+		c.reply(toMsgOption(errorMsg))
+	}
+	return candidate, err
+}
+
+func candidateChallengeNames(ctx commCtx, candidates []models.Candidate) map[string]string {
+	names := make(map[string]string)
+	for _, candidate := range candidates {
+		if _, ok := names[candidate.ChallengeID]; ok {
+			continue
+		}
+		challenge, err := models.GetChallengeSetupByID(ctx.Env, candidate.ChallengeID)
+		if err != nil {
+			log.Println("[ERROR] Cannot find the challenge of the candidate - ", candidate.ChallengeID, err)
+			continue
+		}
+		names[candidate.ChallengeID] = challenge.Name
+	}
+	return names
+}
+
+// extendCandidate moves the deadline of the candidate, and tells them through an issue in their repository.
+// The returned error is the message for the user.
+func extendCandidate(ctx commCtx, candidate models.Candidate, days int) (models.Candidate, error) {
+	if !candidate.InFlight() {
+		return candidate, fmt.Errorf("%s is not in flight anymore, their deadline cannot be extended.", candidate.Name)
+	}
+	challenge, err := models.GetChallengeSetupByID(ctx.Env, candidate.ChallengeID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find the challenge of the candidate - ", candidate.ChallengeID, err)
+		return candidate, fmt.Errorf("There was an error. The challenge of %s cannot be found.", candidate.Name)
+	}
+
+	candidate = candidate.Extend(days)
+	err = models.UpdateCandidate(ctx.Env, candidate)
+	if err != nil {
+		log.Println("[ERROR] Could not update candidate in db ", err)
+		return candidate, fmt.Errorf("We were not able to extend the deadline of %s.", candidate.Name)
+	}
+
+	err = repo.NewActionContext(ctx.Env, challenge).ExtendDeadline(candidate, challenge)
+	if err != nil {
+		return candidate, fmt.Errorf("The deadline of %s is extended, but the issue to tell them could not be created because of %s", candidate.Name, err.Error())
+	}
+	return candidate, nil
+}
+
+// withdrawFromChallenge takes the access of the candidate to their repository away, and tells their reviewers.
+// The returned error is the message for the user.
+func withdrawFromChallenge(ctx commCtx, candidate models.Candidate, userID string) (models.Candidate, error) {
+	if !candidate.InFlight() {
+		return candidate, fmt.Errorf("%s is not in flight anymore.", candidate.Name)
+	}
+	challenge, err := models.GetChallengeSetupByID(ctx.Env, candidate.ChallengeID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find the challenge of the candidate - ", candidate.ChallengeID, err)
+		return candidate, fmt.Errorf("There was an error. The challenge of %s cannot be found.", candidate.Name)
+	}
+
+	err = repo.NewActionContext(ctx.Env, challenge).RevokeAccess(candidate, challenge)
+	if err != nil {
+		return candidate, fmt.Errorf("Unable to remove %s from the challenge repository because of %s", candidate.Name, err.Error())
+	}
+
+	candidate.WithdrawnAt = time.Now()
+	err = models.UpdateCandidate(ctx.Env, candidate)
+	if err != nil {
+		log.Println("[ERROR] Could not update candidate in db ", err)
+		return candidate, fmt.Errorf("We were not able to withdraw %s.", candidate.Name)
+	}
+
+	for _, reviewerID := range candidate.ReviewerIDs {
+		msg := fmt.Sprintf("<@%s> withdrew %s from the %s challenge, their review is not needed anymore.", userID, candidate.Name, challenge.Name)
+		err = ctx.postMessage(reviewerID, toMsgOption(msg))
+		if err != nil {
+			log.Println("[ERROR] Cannot tell the reviewer about the withdrawal - ", reviewerID, err)
+		}
+	}
+	return candidate, nil
+}
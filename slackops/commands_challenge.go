@@ -3,32 +3,40 @@ package slackops
 import (
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/scheduling"
 	"github.com/nlopes/slack"
 )
 
-func (c command) executeChallengeHelp() error {
-	return c.ctx.postMessage(c.slashCmd.ChannelID, renderChallengeHelp())
-}
-
 func (c command) executeSendChallenge() error {
-	challengeName := c.arg // Optional
-	dialog := sendChallengeDialog(c.slashCmd.TriggerID, challengeName)
+	var challenge models.ChallengeSetup
+	if challengeName := c.arg; challengeName != "" {
+		var err error
+		challenge, err = models.GetChallengeSetupByName(c.ctx.Env, challengeName)
+		if err != nil {
+			log.Println("[ERROR] No such challenge is registered, it is not picked - ", challengeName)
+		}
+	}
+	dialog := sendChallengeDialog(challenge)
 
-	return c.ctx.showDialog(c.slashCmd.TriggerID, dialog)
+	return c.ctx.openModal(c.slashCmd.TriggerID, c.slashCmd.ChannelID, dialog)
 }
 
 func (c command) executeNewChallenge() error {
-	dialog := newChallengeDialog(c.slashCmd.TriggerID)
+	dialog := newChallengeDialog()
 
-	return c.ctx.showDialog(c.slashCmd.TriggerID, dialog)
+	return c.ctx.openModal(c.slashCmd.TriggerID, c.slashCmd.ChannelID, dialog)
 }
 
 func (c command) executeEditChallenge() error {
 	var challengeName string
 	if c.arg == "" {
-		c.ctx.postMessage(c.slashCmd.ChannelID, toMsgOption("You need to provide a challenge name. Please try /challenge edit CHALLENGENAME"))
+		c.reply(toMsgOption("You need to provide a challenge name. Please try /challenge edit CHALLENGENAME"))
 	} else {
 		challengeName = c.arg
 	}
@@ -36,82 +44,340 @@ func (c command) executeEditChallenge() error {
 	if err != nil {
 		log.Println("[ERROR] No such challenge is registered.", err)
 		errorMsg := fmt.Sprintf("Challenge named %s is not registered. Please register first using /challenge new command.", challengeName)
-		c.ctx.postMessage(c.slashCmd.ChannelID, toMsgOption(errorMsg))
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
+
+	dialog := editChallengeDialog(challenge)
+
+	return c.ctx.openModal(c.slashCmd.TriggerID, c.slashCmd.ChannelID, dialog)
+}
+
+func (c command) executeListChallenges() error {
+	challenges, err := models.GetAllChallenges(c.ctx.Env)
+	if err != nil {
+		log.Println("[ERROR] Cannot get the challenges - ", err)
+		c.reply(toMsgOption("There was an error. The challenges cannot be listed."))
+		return err
+	}
+	sort.Slice(challenges, func(i, j int) bool { return strings.ToLower(challenges[i].Name) < strings.ToLower(challenges[j].Name) })
+
+	reviewerCounts := make(map[string]int)
+	reviewers, err := models.GetAllReviewers(c.ctx.Env)
+	if err != nil {
+		log.Println("[ERROR] Cannot get the reviewers - ", err)
+	}
+	for _, reviewer := range reviewers {
+		for _, challengeID := range reviewer.ChallengeIDs() {
+			reviewerCounts[challengeID]++
+		}
+	}
+
+	inFlightCounts := make(map[string]int)
+	candidates, err := models.GetAllCandidates(c.ctx.Env)
+	if err != nil {
+		log.Println("[ERROR] Cannot get the candidates - ", err)
+	}
+	for _, candidate := range candidates {
+		if candidate.InFlight() {
+			inFlightCounts[candidate.ChallengeID]++
+		}
+	}
+
+	sections := renderChallengeList(challenges, reviewerCounts, inFlightCounts)
+	return c.reply(slack.MsgOptionBlocks(sections...))
+}
+
+func (c command) executeShowChallenge() error {
+	challenge, err := models.GetChallengeSetupByName(c.ctx.Env, c.arg)
+	if err != nil {
+		log.Println("[ERROR] No such challenge is registered.", err)
+		errorMsg := fmt.Sprintf("Challenge named %s is not registered. Type /challenge list to see all the challenges.", c.arg)
+		c.reply(toMsgOption(errorMsg))
 		return err
 	}
 
-	dialog := editChallengeDialog(c.slashCmd.TriggerID, challenge)
+	reviewers, err := models.GetAllReviewersForChallenge(c.ctx.Env, challenge.ID)
+	if err != nil {
+		log.Println("[ERROR] Cannot get the reviewers of the challenge - ", err)
+	}
+	candidates, err := challengeCandidatesInFlight(c.ctx, challenge.ID)
+	if err != nil {
+		log.Println("[ERROR] Cannot get the candidates of the challenge - ", err)
+	}
 
-	return c.ctx.showDialog(c.slashCmd.TriggerID, dialog)
+	sections := renderChallengeDetails(challenge, reviewers, candidates, c.ctx.getUserLocation(c.slashCmd.UserID))
+	return c.reply(slack.MsgOptionBlocks(sections...))
 }
 
-func sendChallengeDialog(triggerID string, challengeName string) slack.Dialog {
-	candidateNameElement := slack.NewTextInput("candidate_name", "Candidate Name", "")
-	githubNameElement := slack.NewTextInput("github_alias", "Github Alias", "")
-	resumeURLElement := slack.NewTextInput("resume_URL", "Resume URL", "")
-	challengeNameElement := newExternalOptionsDialogInput("challenge_id", "Challenge Name", "", false)
+func (c command) executeDeleteChallenge() error {
+	challenge, err := models.GetChallengeSetupByName(c.ctx.Env, c.arg)
+	if err != nil {
+		log.Println("[ERROR] No such challenge is registered.", err)
+		errorMsg := fmt.Sprintf("Challenge named %s is not registered. Type /challenge list to see all the challenges.", c.arg)
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
+
+	if reason := challengeDeletionBlocker(c.ctx, challenge); reason != "" {
+		return c.reply(toMsgOption(reason))
+	}
+
+	reviewers, err := models.GetAllReviewersForChallenge(c.ctx.Env, challenge.ID)
+	if err != nil {
+		log.Println("[ERROR] Cannot get the reviewers of the challenge - ", err)
+	}
+	sections := renderDeleteChallenge(challenge, len(reviewers))
+	return c.reply(slack.MsgOptionBlocks(sections...))
+}
 
-	reviewer1El := newUsersSelect("reviewer1_id", "Reviewer 1", true)
-	reviewer2El := newUsersSelect("reviewer2_id", "Reviewer 2", true)
+func challengeCandidatesInFlight(ctx commCtx, challengeID string) ([]models.Candidate, error) {
+	candidates, err := models.GetCandidatesForChallenge(ctx.Env, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	inFlight := make([]models.Candidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.InFlight() {
+			inFlight = append(inFlight, candidate)
+		}
+	}
+	return inFlight, nil
+}
 
-	// reviewer1OptionsElement := newExternalOptionsDialogInput("reviewer1_id", "Reviewer 1", "", true)
-	// reviewer2OptionsElement := newExternalOptionsDialogInput("reviewer2_id", "Reviewer 2", "", true)
+// challengeDeletionBlocker explains why the challenge cannot be deleted yet, empty if it can be
+func challengeDeletionBlocker(ctx commCtx, challenge models.ChallengeSetup) string {
+	candidates, err := challengeCandidatesInFlight(ctx, challenge.ID)
+	if err != nil {
+		log.Println("[ERROR] Cannot get the candidates of the challenge - ", err)
+		return fmt.Sprintf("%s cannot be deleted, its candidates cannot be checked. Please try again later.", challenge.Name)
+	}
+	if len(candidates) > 0 {
+		names := make([]string, 0, len(candidates))
+		for _, candidate := range candidates {
+			names = append(names, candidate.Name)
+		}
+		return fmt.Sprintf("%s cannot be deleted while candidates are in flight: %s. It can be deleted once their reviews are closed.",
+			challenge.Name, strings.Join(names, ", "))
+	}
 
-	elements := []slack.DialogElement{
+	reviewers, err := models.GetAllReviewersForChallenge(ctx.Env, challenge.ID)
+	if err != nil {
+		log.Println("[ERROR] Cannot get the reviewers of the challenge - ", err)
+		return fmt.Sprintf("%s cannot be deleted, its reviewers cannot be checked. Please try again later.", challenge.Name)
+	}
+	today := time.Now().Format(models.DateFormat)
+	bookings := 0
+	for _, reviewer := range reviewers {
+		for _, booking := range reviewer.Bookings {
+			if booking.ChallengeID == challenge.ID && booking.Occurrence.Date >= today {
+				bookings++
+			}
+		}
+	}
+	if bookings > 0 {
+		return fmt.Sprintf("%s cannot be deleted, its reviewers have %d upcoming bookings. Please unbook them first.", challenge.Name, bookings)
+	}
+
+	needs, err := models.GetCurrentNeeds(ctx.Env, time.Now())
+	if err != nil {
+		log.Println("[ERROR] Cannot load the needs - ", err)
+	}
+	for _, need := range needs {
+		if need.ChallengeID == challenge.ID {
+			return fmt.Sprintf("%s cannot be deleted, reviewers are still needed for %s. Please cancel the need first with /reviewer needs.", challenge.Name, need.CandidateName)
+		}
+	}
+	return ""
+}
+
+// sendChallengeDialog is the first step of sending a challenge, the reviewers are picked in the next step
+func sendChallengeDialog(challenge models.ChallengeSetup) modal {
+	candidateNameElement := newTextInput("candidate_name", "Candidate Name", "")
+	githubNameElement := newTextInput("github_alias", "Github Alias", "")
+	githubNameElement.Hint = "The Github user name of the candidate, not their email address"
+	resumeURLElement := newTextInput("resume_URL", "Resume URL", "")
+	challengeNameElement := newExternalSelectInput("challenge_id", "Challenge Name", challenge.ID, false)
+	challengeNameElement.SelectedLabel = challenge.Name
+
+	elements := []*modalInput{
 		candidateNameElement,
 		githubNameElement,
 		resumeURLElement,
 		challengeNameElement,
-		reviewer1El,
-		reviewer2El,
 	}
 
-	return slack.Dialog{
-		TriggerID:      triggerID,
-		CallbackID:     "send_challenge",
-		Title:          "Send Coding Challenge",
-		SubmitLabel:    "Send",
-		NotifyOnCancel: false,
-		State:          challengeName,
-		Elements:       elements,
+	return modal{
+		CallbackID:  "send_challenge",
+		Title:       "Send Coding Challenge",
+		SubmitLabel: "Next",
+		Elements:    elements,
+	}
+}
+
+// sendReviewersDialog picks the reviewers of the challenge for the candidate
+func sendReviewersDialog(challenge models.ChallengeSetup, reviewers []models.Reviewer) modal {
+	reviewerOptions := make([]option, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		if len(reviewerOptions) == maxSelectOptions {
+			break
+		}
+		reviewerOptions = append(reviewerOptions, option{
+			Label: fmt.Sprintf("%s (%s)", reviewer.Name, reviewer.TechnologyList),
+			Value: reviewer.SlackID,
+		})
+	}
+	reviewer1El := newStaticSelectInput("reviewer1_id", "Reviewer 1", "", true, reviewerOptions)
+	reviewer2El := newStaticSelectInput("reviewer2_id", "Reviewer 2", "", true, reviewerOptions)
+
+	return modal{
+		CallbackID:  "send_challenge_reviewers",
+		Title:       "Pick Reviewers",
+		SubmitLabel: "Send",
+		State:       challenge.ID,
+		Elements: []*modalInput{
+			reviewer1El,
+			reviewer2El,
+		},
 	}
 }
 
-func newChallengeDialog(triggerID string) slack.Dialog {
-	return slack.Dialog{
-		TriggerID:      triggerID,
-		CallbackID:     "new_challenge",
-		Title:          "New Coding Challenge",
-		SubmitLabel:    "Create",
-		NotifyOnCancel: false,
+func newChallengeDialog() modal {
+	return modal{
+		CallbackID:  "new_challenge",
+		Title:       "New Coding Challenge",
+		SubmitLabel: "Create",
 		Elements: challengeDialogElements(models.ChallengeSetup{
 			RepoNameFormat: "test_CHALLENGENAME-GITHUBALIAS",
 		}),
 	}
 }
 
-func editChallengeDialog(triggerID string, challenge models.ChallengeSetup) slack.Dialog {
-	return slack.Dialog{
-		TriggerID:      triggerID,
-		CallbackID:     "edit_challenge",
-		Title:          "Edit Coding Challenge",
-		SubmitLabel:    "Edit",
-		State:          challenge.ID,
-		NotifyOnCancel: false,
-		Elements:       challengeDialogElements(challenge),
+func editChallengeDialog(challenge models.ChallengeSetup) modal {
+	return modal{
+		CallbackID:  "edit_challenge",
+		Title:       "Edit Coding Challenge",
+		SubmitLabel: "Edit",
+		State:       challenge.ID,
+		Elements:    challengeDialogElements(challenge),
 	}
 }
 
-func challengeDialogElements(challenge models.ChallengeSetup) []slack.DialogElement {
-	challengeNameEl := slack.NewTextInput("challenge_name", "Challenge Name", challenge.Name)
-	templateRepoNameEl := slack.NewTextInput("template_repo", "Template Repo Name", challenge.TemplateRepo)
-	repoNameFormatEl := slack.NewTextInput("repo_name_format", "Repo Name Format", challenge.RepoNameFormat)
+func challengeDialogElements(challenge models.ChallengeSetup) []*modalInput {
+	challengeNameEl := newTextInput("challenge_name", "Challenge Name", challenge.Name)
+	templateRepoNameEl := newTextInput("template_repo", "Template Repo Name", challenge.TemplateRepo)
+	repoNameFormatEl := newTextInput("repo_name_format", "Repo Name Format", challenge.RepoNameFormat)
+
+	timeZone := challenge.TimeZone
+	if timeZone == "" {
+		timeZone = models.DefaultTimeZone
+	}
+	timeZoneEl := newTextInput("time_zone", "Time Zone", timeZone)
+	timeZoneEl.Hint = "Canonical time zone for the interview slots, e.g. Europe/Berlin"
 
-	githubAccountEl := newExternalOptionsDialogInput("github_account", "Github Account Name", "", false)
-	return []slack.DialogElement{
+	githubAccountEl := newExternalSelectInput("github_account", "Github Account Name", "", false)
+	return []*modalInput{
 		challengeNameEl,
 		templateRepoNameEl,
 		repoNameFormatEl,
+		timeZoneEl,
 		githubAccountEl,
 	}
 }
+
+func (c command) executeChallengeSlots() error {
+	challengeName := c.arg
+	if challengeName == "" {
+		return c.reply(toMsgOption("You need to provide a challenge name. Please try /challenge slots CHALLENGENAME"))
+	}
+	challenge, err := models.GetChallengeSetupByName(c.ctx.Env, challengeName)
+	if err != nil {
+		log.Println("[ERROR] No such challenge is registered.", err)
+		errorMsg := fmt.Sprintf("Challenge named %s is not registered. Please register first using /challenge new command.", challengeName)
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
+
+	sections := challengeSlotSections(c.ctx, challenge)
+	return c.reply(slack.MsgOptionBlocks(sections...))
+}
+
+// challengeSlotSections renders the slots with the number of future bookings that removing them would cancel
+func challengeSlotSections(ctx commCtx, challenge models.ChallengeSetup) []slack.Block {
+	reviewers, err := models.GetAllReviewersForChallenge(ctx.Env, challenge.ID)
+	if err != nil {
+		log.Println("[ERROR] Cannot get the reviewers of the challenge - ", err)
+	}
+
+	bookingCounts := make(map[models.SlotID]int)
+	for slotID := range challenge.Slots {
+		for _, bookings := range scheduling.FutureBookingsForSlot(reviewers, slotID, time.Now()) {
+			bookingCounts[slotID] += len(bookings)
+		}
+	}
+	return renderChallengeSlots(challenge, bookingCounts)
+}
+
+func newSlotDialog(challengeID string) modal {
+	return slotDialog(models.Slot{Day: "Monday", StartTime: "9:00", EndTime: "11:00"}, "add_slot", "Add Slot", "Add", challengeID)
+}
+
+// editSlotDialog shows the slot for editing, the state keeps the slot ID before the challenge ID
+func editSlotDialog(challengeID string, slot models.Slot) modal {
+	return slotDialog(slot, "edit_slot", "Edit Slot", "Save", encodeSlotState(slot.ID, challengeID))
+}
+
+func slotDialog(slot models.Slot, callbackID, title, submitLabel, state string) modal {
+	nameEl := newTextInput("slot_name", "Slot Name", slot.Name)
+	nameEl.Optional = true
+	nameEl.Hint = "E.g. Monday Morning. Leave empty to name it after the day and start time."
+	dayEl := newStaticSelectInput("day", "Day", slot.Day, false, weekDayOptions())
+	startEl := newTextInput("start_time", "Start Time", slot.StartTime)
+	startEl.Hint = "24 hour clock in the challenge time zone, e.g. 9:00"
+	endEl := newTextInput("end_time", "End Time", slot.EndTime)
+	endEl.Hint = "24 hour clock in the challenge time zone, e.g. 11:00"
+	duration := ""
+	if slot.Duration > 0 {
+		duration = strconv.Itoa(slot.Duration)
+	}
+	durationEl := newStaticSelectInput("duration", "Interview Duration", duration, true, durationOptions())
+
+	return modal{
+		CallbackID:  callbackID,
+		Title:       title,
+		SubmitLabel: submitLabel,
+		State:       state,
+		Elements: []*modalInput{
+			nameEl,
+			dayEl,
+			startEl,
+			endEl,
+			durationEl,
+		},
+	}
+}
+
+func weekDayOptions() []option {
+	selectOptions := make([]option, 0, 7)
+	for i := 0; i < 7; i++ {
+		// Monday first
+		day := time.Weekday((i + 1) % 7).String()
+		selectOptions = append(selectOptions, option{
+			Label: day,
+			Value: day,
+		})
+	}
+	return selectOptions
+}
+
+func durationOptions() []option {
+	durations := []int{30, 45, 60, 90, 120}
+	selectOptions := make([]option, 0, len(durations))
+	for _, duration := range durations {
+		selectOptions = append(selectOptions, option{
+			Label: fmt.Sprintf("%d minutes", duration),
+			Value: strconv.Itoa(duration),
+		})
+	}
+	return selectOptions
+}
@@ -0,0 +1,47 @@
+package slackops
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Default number of weeks an imported calendar is checked for
+const defaultImportWeeks = 4
+
+func (c command) executeImportCalendar() error {
+	dialog := newImportDialog(c.reviewerSlackID())
+
+	return c.ctx.openModal(c.slashCmd.TriggerID, c.slashCmd.ChannelID, dialog)
+}
+
+func newImportDialog(reviewerSlackID string) modal {
+	calendarURLEl := newTextInput("calendar_url", "Calendar File Link", "")
+	calendarURLEl.Hint = "Upload the .ics export of your calendar to Slack, e.g. in a DM to the bot, and paste the link to the file here."
+	weeksEl := newStaticSelectInput("weeks", "Weeks", strconv.Itoa(defaultImportWeeks), false, importWeeksOptions())
+
+	return modal{
+		CallbackID:  "import_availability",
+		Title:       "Import Calendar",
+		SubmitLabel: "Import",
+		State:       reviewerSlackID,
+		Elements: []*modalInput{
+			calendarURLEl,
+			weeksEl,
+		},
+	}
+}
+
+func importWeeksOptions() []option {
+	selectOptions := make([]option, 0, 12)
+	for i := 1; i <= 12; i++ {
+		label := fmt.Sprintf("Next %d weeks", i)
+		if i == 1 {
+			label = "This week"
+		}
+		selectOptions = append(selectOptions, option{
+			Label: label,
+			Value: strconv.Itoa(i),
+		})
+	}
+	return selectOptions
+}
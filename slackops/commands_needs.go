@@ -0,0 +1,86 @@
+package slackops
+
+import (
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/scheduling"
+	"github.com/nlopes/slack"
+)
+
+// Most reviewers a need can ask for
+const maxNeedCount = 4
+
+func (c command) executeNewNeed() error {
+	technologyEl := technologyDialogInput(c.ctx.Env, "Technology")
+	dialog := newNeedDialog(technologyEl)
+
+	return c.ctx.openModal(c.slashCmd.TriggerID, c.slashCmd.ChannelID, dialog)
+}
+
+func newNeedDialog(technologyEl *modalInput) modal {
+	candidateNameEl := newTextInput("candidate_name", "Candidate Name", "")
+	challengeNameEl := newExternalSelectInput("challenge_id", "Challenge Name", "", false)
+	countEl := newStaticSelectInput("count", "# Reviewers", "2", false, needCountOptions())
+	experienceEl := newStaticSelectInput("experience", "Candidate Experience Level", "1", true, experienceOptions())
+	weekOfYearDefault := encodeWeek(scheduling.FirstDayOfWeek(time.Now()))
+	weekOfYearEl := newStaticSelectInput("year_week", "Week of the Year", weekOfYearDefault, false, weekOfYearOptions(false))
+	kindEl := newStaticSelectInput("kind", "Kind", models.CodeReview, false, bookingKindOptions())
+
+	return modal{
+		CallbackID:  "new_need",
+		Title:       "Need Reviewers",
+		SubmitLabel: "Register",
+		Elements: []*modalInput{
+			candidateNameEl,
+			challengeNameEl,
+			countEl,
+			technologyEl,
+			experienceEl,
+			weekOfYearEl,
+			kindEl,
+		},
+	}
+}
+
+func needCountOptions() []option {
+	selectOptions := make([]option, 0, maxNeedCount)
+	for i := 1; i <= maxNeedCount; i++ {
+		selectOptions = append(selectOptions, option{
+			Label: strconv.Itoa(i),
+			Value: strconv.Itoa(i),
+		})
+	}
+	return selectOptions
+}
+
+func (c command) executeShowNeeds() error {
+	needs, err := models.GetCurrentNeeds(c.ctx.Env, time.Now())
+	if err != nil {
+		log.Println("[ERROR] Cannot load the needs - ", err)
+		c.reply(toMsgOption("There was an error. The needs cannot be shown."))
+		return err
+	}
+
+	sections := renderNeeds(needs, needChallengeNames(c.ctx, needs))
+	return c.reply(slack.MsgOptionBlocks(sections...))
+}
+
+func needChallengeNames(ctx commCtx, needs []models.Need) map[string]string {
+	names := make(map[string]string)
+	for _, need := range needs {
+		if _, ok := names[need.ChallengeID]; ok {
+			continue
+		}
+		challenge, err := models.GetChallengeByID(ctx.Env, need.ChallengeID)
+		if err != nil {
+			log.Println("[ERROR] Invalid challenge for need - ", need.ChallengeID, err)
+			names[need.ChallengeID] = need.ChallengeID
+			continue
+		}
+		names[need.ChallengeID] = challenge.Name
+	}
+	return names
+}
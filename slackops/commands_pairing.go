@@ -0,0 +1,64 @@
+package slackops
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/repo"
+)
+
+func (c command) executeInvitePairing() error {
+	githubAlias := strings.TrimSpace(c.arg)
+	if githubAlias == "" {
+		return c.reply(toMsgOption("You need to provide the Github alias of the candidate. Please try /challenge pairing GITHUBALIAS"))
+	}
+	if c.ctx.Env.ServerURL == "" || c.ctx.Env.LinkSecret == "" {
+		log.Println("[INFO] SERVER_URL or LINK_SECRET is not set, pairing links are not available")
+		return c.reply(toMsgOption("Pairing links are not available, the server needs SERVER_URL and LINK_SECRET to be set."))
+	}
+
+	candidate, err := models.GetCandidateByGithubAlias(c.ctx.Env, githubAlias)
+	if err != nil {
+		log.Println("[ERROR] No such candidate - ", githubAlias, err)
+		errorMsg := fmt.Sprintf("No challenge was sent to the Github alias %s. Please send one first using /challenge send command.", githubAlias)
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
+	if len(candidate.ReviewerIDs) == 0 {
+		errorMsg := fmt.Sprintf("%s has no reviewers assigned, the live pairing session is booked with the reviewers of the challenge.", candidate.Name)
+		return c.reply(toMsgOption(errorMsg))
+	}
+	if candidate.HasPairing() {
+		errorMsg := fmt.Sprintf("%s already picked a live pairing session on %s.", candidate.Name, candidate.Pairing.Date)
+		return c.reply(toMsgOption(errorMsg))
+	}
+
+	challenge, err := models.GetChallengeSetupByID(c.ctx.Env, candidate.ChallengeID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find the challenge of the candidate - ", candidate.ChallengeID, err)
+		c.reply(toMsgOption("There was an error. The challenge of the candidate cannot be found."))
+		return err
+	}
+
+	link := pairingURL(c.ctx.Env.ServerURL, c.ctx.Env.LinkSecret, candidate.ID, time.Now().Add(models.PairingLinkDuration))
+	repoCtx := repo.NewActionContext(c.ctx.Env, challenge)
+	err = repoCtx.InvitePairing(candidate, challenge, link)
+	if err != nil {
+		errorMsg := fmt.Sprintf("Unable to create the pairing issue for %s because of %s", candidate.Name, err.Error())
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
+
+	msg := fmt.Sprintf("%s is invited to pick a live pairing session through an issue at %s. Their personal link is %s",
+		candidate.Name, candidate.ChallengeURL, link)
+	return c.reply(toMsgOption(msg))
+}
+
+// pairingURL is the signed link of the candidate's page to pick a live pairing session
+func pairingURL(serverURL, secret, candidateID string, expires time.Time) string {
+	signature := models.SignLink(secret, candidateID, expires.Unix())
+	return fmt.Sprintf("%s/pairing/%s?expires=%d&sig=%s", strings.TrimSuffix(serverURL, "/"), candidateID, expires.Unix(), signature)
+}
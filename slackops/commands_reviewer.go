@@ -1,25 +1,24 @@
 package slackops
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/keremk/challenge-bot/config"
 	"github.com/keremk/challenge-bot/models"
 	"github.com/keremk/challenge-bot/scheduling"
 	"github.com/nlopes/slack"
 )
 
-func (c command) executeReviewerHelp() error {
-	return c.ctx.postMessage(c.slashCmd.ChannelID, renderReviewerHelp())
-}
-
 func (c command) executeNewReviewer() error {
-	dialog := newAddReviewerDialog(c.slashCmd.TriggerID)
+	dialog := newAddReviewerDialog()
 
-	return c.ctx.showDialog(c.slashCmd.TriggerID, dialog)
+	return c.ctx.openModal(c.slashCmd.TriggerID, c.slashCmd.ChannelID, dialog)
 }
 
 func (c command) executeEditReviewer() error {
@@ -33,54 +32,66 @@ func (c command) executeEditReviewer() error {
 	if err != nil {
 		log.Println("[ERROR] No such reviewer registered.", err)
 		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", reviewerSlackID)
-		c.ctx.postMessage(c.slashCmd.ChannelID, toMsgOption(errorMsg))
+		c.reply(toMsgOption(errorMsg))
 		return err
 	}
 
-	dialog := newEditReviewerDialog(c.slashCmd.TriggerID, reviewer)
+	dialog := newEditReviewerDialog(reviewer, challengeOptions(c.ctx.Env, nil))
 
-	return c.ctx.showDialog(c.slashCmd.TriggerID, dialog)
+	return c.ctx.openModal(c.slashCmd.TriggerID, c.slashCmd.ChannelID, dialog)
 }
 
-func newAddReviewerDialog(triggerID string) slack.Dialog {
-	return slack.Dialog{
-		TriggerID:      triggerID,
-		CallbackID:     "new_reviewer",
-		Title:          "Add Reviewer",
-		SubmitLabel:    "Add",
-		NotifyOnCancel: false,
-		State:          "",
-		Elements:       reviewerDialogElements(models.Reviewer{}, false),
+func newAddReviewerDialog() modal {
+	return modal{
+		CallbackID:  "new_reviewer",
+		Title:       "Add Reviewer",
+		SubmitLabel: "Add",
+		State:       "",
+		Elements:    reviewerDialogElements(models.Reviewer{}, nil),
 	}
 }
 
-func newEditReviewerDialog(triggerID string, reviewer models.Reviewer) slack.Dialog {
-	return slack.Dialog{
-		TriggerID:      triggerID,
-		CallbackID:     "edit_reviewer",
-		Title:          "Edit Reviewer",
-		SubmitLabel:    "Edit",
-		NotifyOnCancel: false,
-		State:          reviewer.SlackID,
-		Elements:       reviewerDialogElements(reviewer, true),
+// newEditReviewerDialog edits the reviewer's skills and capacity for the challenge selected, picking another challenge
+// adds it to the reviewer
+func newEditReviewerDialog(reviewer models.Reviewer, challenges []option) modal {
+	return modal{
+		CallbackID:  "edit_reviewer",
+		Title:       "Edit Reviewer",
+		SubmitLabel: "Edit",
+		State:       reviewer.SlackID,
+		Elements:    reviewerDialogElements(reviewer, challenges),
 	}
 }
 
-func reviewerDialogElements(reviewer models.Reviewer, editMode bool) []slack.DialogElement {
-	elements := make([]slack.DialogElement, 0, 10)
+// reviewerDialogElements has the static challenge options when editing, new reviewers pick from the external options
+func reviewerDialogElements(reviewer models.Reviewer, challenges []option) []*modalInput {
+	editMode := challenges != nil
+	elements := make([]*modalInput, 0, 10)
 	if !editMode {
-		reviewerEl := slack.NewUsersSelect("reviewer_id", "Reviewer")
+		reviewerEl := newUsersSelect("reviewer_id", "Reviewer", false)
 		elements = append(elements, reviewerEl)
 	}
 
-	githubNameEl := slack.NewTextInput("github_alias", "Github Alias", reviewer.GithubAlias)
-	challengeNameEl := newExternalOptionsDialogInput("challenge_id", "Challenge Name", "", false)
-	technologyListEl := slack.NewTextInput("technology_list", "Technology List", reviewer.TechnologyList)
+	githubNameEl := newTextInput("github_alias", "Github Alias", reviewer.GithubAlias)
+	var challengeNameEl *modalInput
+	if editMode {
+		challengeNameEl = newStaticSelectInput("challenge_id", "Challenge Name", reviewer.ChallengeID, false, challenges)
+	} else {
+		challengeNameEl = newExternalSelectInput("challenge_id", "Challenge Name", "", false)
+	}
+	technologyListEl := newTextInput("technology_list", "Technology List", reviewer.TechnologyList)
+	technologyListEl.Hint = "Comma separated, e.g. Kotlin, Java. Pick them from the tags afterwards with /reviewer tags."
 	experienceLevel := strconv.Itoa(reviewer.Experience)
-	experienceLevelEl := newStaticOptionsDialogInput("experience", "Experience Level", experienceLevel, true, experienceOptions())
+	experienceLevelEl := newStaticSelectInput("experience", "Experience Level", experienceLevel, true, experienceOptions())
 	bookingsPerWeek := strconv.Itoa(reviewer.BookingsPerWeek)
-	bookingsPerWeekEl := newStaticOptionsDialogInput("bookings_week", "# Bookings per Week", bookingsPerWeek, true,
+	bookingsPerWeekEl := newStaticSelectInput("bookings_week", "# Bookings per Week", bookingsPerWeek, true,
 		bookingsOptions())
+	bookingsPerMonth := strconv.Itoa(reviewer.BookingsPerMonth)
+	bookingsPerMonthEl := newStaticSelectInput("bookings_month", "# Bookings per Month", bookingsPerMonth, true,
+		monthlyBookingsOptions())
+	cooldownWeeks := strconv.Itoa(reviewer.CooldownWeeks)
+	cooldownWeeksEl := newStaticSelectInput("cooldown_weeks", "Cool-down after a Full Week", cooldownWeeks, true,
+		cooldownOptions())
 
 	return append(elements,
 		githubNameEl,
@@ -88,6 +99,8 @@ func reviewerDialogElements(reviewer models.Reviewer, editMode bool) []slack.Dia
 		technologyListEl,
 		experienceLevelEl,
 		bookingsPerWeekEl,
+		bookingsPerMonthEl,
+		cooldownWeeksEl,
 	)
 }
 
@@ -98,58 +111,104 @@ func (c command) executeSchedule() error {
 	} else {
 		reviewerSlackID = parseSlackIDFromString(c.arg)
 	}
+	reviewer, err := models.GetReviewerBySlackID(c.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", reviewerSlackID)
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
 
-	dialog := newScheduleDialog(c.slashCmd.TriggerID, reviewerSlackID)
+	dialog := newScheduleDialog(reviewer, challengeOptions(c.ctx.Env, reviewer.ChallengeIDs()))
 
-	return c.ctx.showDialog(c.slashCmd.TriggerID, dialog)
+	return c.ctx.openModal(c.slashCmd.TriggerID, c.slashCmd.ChannelID, dialog)
 }
 
-func newScheduleDialog(triggerID string, reviewerSlackID string) slack.Dialog {
-	weekOfYearDefault := encodeWeekAndYear(0, time.Now().Year())
-	weekOfYearEl := newStaticOptionsDialogInput("year_week", "Week of the Year", weekOfYearDefault, true, weekOfYearOptions(true))
+func newScheduleDialog(reviewer models.Reviewer, challenges []option) modal {
+	weekOfYearEl := newStaticSelectInput("year_week", "Week of the Year", generalWeek, true, weekOfYearOptions(true))
 
-	elements := []slack.DialogElement{
+	elements := []*modalInput{
 		weekOfYearEl,
 	}
-	return slack.Dialog{
-		TriggerID:      triggerID,
-		CallbackID:     "schedule_update",
-		Title:          "Update Schedule",
-		SubmitLabel:    "Update",
-		NotifyOnCancel: false,
-		State:          reviewerSlackID,
-		Elements:       elements,
+	// Reviewers of several challenges pick the challenge, as each has its own slots
+	if len(challenges) > 1 {
+		challengeEl := newStaticSelectInput("challenge_id", "Challenge", reviewer.ChallengeID, false, challenges)
+		elements = append(elements, challengeEl)
+	}
+	return modal{
+		CallbackID:  "schedule_update",
+		Title:       "Update Schedule",
+		SubmitLabel: "Update",
+		State:       reviewer.SlackID,
+		Elements:    elements,
 	}
 }
 
-func weekOfYearOptions(includeAllWeeks bool) []slack.DialogSelectOption {
+// challengeOptions lists the challenges with the IDs, all challenges if there are no IDs
+func challengeOptions(env config.Environment, challengeIDs []string) []option {
+	selectOptions := make([]option, 0, len(challengeIDs))
+	if len(challengeIDs) == 0 {
+		challenges, err := models.GetAllChallenges(env)
+		if err != nil {
+			log.Println("[ERROR] Cannot load the challenges - ", err)
+		}
+		for _, challenge := range challenges {
+			selectOptions = append(selectOptions, option{
+				Label: challenge.Name,
+				Value: challenge.ID,
+			})
+		}
+		return selectOptions
+	}
+
+	for _, challengeID := range challengeIDs {
+		challenge, err := models.GetChallengeByID(env, challengeID)
+		if err != nil {
+			log.Println("[ERROR] Invalid challenge for reviewer - ", challengeID, err)
+			continue
+		}
+		selectOptions = append(selectOptions, option{
+			Label: challenge.Name,
+			Value: challenge.ID,
+		})
+	}
+	return selectOptions
+}
+
+func weekOfYearOptions(includeAllWeeks bool) []option {
 	week := scheduling.FirstDayOfWeek(time.Now())
-	year, weekNo := week.ISOWeek()
-	selectOptions := make([]slack.DialogSelectOption, 0, 25)
+	selectOptions := make([]option, 0, 25)
 
 	if includeAllWeeks {
-		selectOptions = append(selectOptions, slack.DialogSelectOption{
+		selectOptions = append(selectOptions, option{
 			Label: "All Weeks",
-			Value: encodeWeekAndYear(0, year),
+			Value: generalWeek,
 		})
 	}
 	for i := 0; i < 24; i++ {
 		weekLabel := scheduling.WeekDescription(week)
-		selectOptions = append(selectOptions, slack.DialogSelectOption{
+		selectOptions = append(selectOptions, option{
 			Label: weekLabel,
-			Value: encodeWeekAndYear(weekNo, year),
+			Value: encodeWeek(week),
 		})
 		week = week.AddDate(0, 0, 7)
-		year, weekNo = week.ISOWeek()
 	}
 	return selectOptions
 }
 
-func experienceOptions() []slack.DialogSelectOption {
-	experienceLevel := []string{"Low", "Mid", "High"}
-	selectOptions := make([]slack.DialogSelectOption, 0, len(experienceLevel))
-	for i, level := range experienceLevel {
-		selectOptions = append(selectOptions, slack.DialogSelectOption{
+var experienceLevels = []string{"Low", "Mid", "High"}
+
+func experienceLabel(experience int) string {
+	if experience < 0 || experience >= len(experienceLevels) {
+		return strconv.Itoa(experience)
+	}
+	return experienceLevels[experience]
+}
+
+func experienceOptions() []option {
+	selectOptions := make([]option, 0, len(experienceLevels))
+	for i, level := range experienceLevels {
+		selectOptions = append(selectOptions, option{
 			Label: level,
 			Value: strconv.Itoa(i),
 		})
@@ -157,12 +216,12 @@ func experienceOptions() []slack.DialogSelectOption {
 	return selectOptions
 }
 
-func dayOptions() []slack.DialogSelectOption {
+func dayOptions() []option {
 	daysOfWeek := []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}
 
-	selectOptions := make([]slack.DialogSelectOption, 0, len(daysOfWeek))
+	selectOptions := make([]option, 0, len(daysOfWeek))
 	for _, day := range daysOfWeek {
-		selectOptions = append(selectOptions, slack.DialogSelectOption{
+		selectOptions = append(selectOptions, option{
 			Label: day,
 			Value: day,
 		})
@@ -170,11 +229,11 @@ func dayOptions() []slack.DialogSelectOption {
 	return selectOptions
 }
 
-func bookingsOptions() []slack.DialogSelectOption {
-	selectOptions := make([]slack.DialogSelectOption, 0, 7)
+func bookingsOptions() []option {
+	selectOptions := make([]option, 0, 7)
 	for i := 1; i < 7; i++ {
 		label := fmt.Sprintf("Max %d times/week", i)
-		selectOptions = append(selectOptions, slack.DialogSelectOption{
+		selectOptions = append(selectOptions, option{
 			Label: label,
 			Value: strconv.Itoa(i),
 		})
@@ -182,6 +241,32 @@ func bookingsOptions() []slack.DialogSelectOption {
 	return selectOptions
 }
 
+func monthlyBookingsOptions() []option {
+	selectOptions := []option{
+		{Label: "No monthly limit", Value: "0"},
+	}
+	for _, i := range []int{2, 4, 6, 8, 10, 12} {
+		selectOptions = append(selectOptions, option{
+			Label: fmt.Sprintf("Max %d times/month", i),
+			Value: strconv.Itoa(i),
+		})
+	}
+	return selectOptions
+}
+
+func cooldownOptions() []option {
+	selectOptions := []option{
+		{Label: "No cool-down", Value: "0"},
+	}
+	for i := 1; i < 5; i++ {
+		selectOptions = append(selectOptions, option{
+			Label: fmt.Sprintf("%d week(s) off", i),
+			Value: strconv.Itoa(i),
+		})
+	}
+	return selectOptions
+}
+
 func parseSlackIDFromString(combinedID string) string {
 	// Format is <@U1234|user>
 	match := "([A-Z])\\w+"
@@ -198,36 +283,67 @@ func (c command) executeFindReviewers() error {
 		reviewerSlackID = parseSlackIDFromString(c.arg)
 	}
 
-	dialog := newFindDialog(c.slashCmd.TriggerID, reviewerSlackID)
+	technologyEl := technologyDialogInput(c.ctx.Env, "Technology")
+	dialog := newFindDialog(reviewerSlackID, technologyEl)
 
-	return c.ctx.showDialog(c.slashCmd.TriggerID, dialog)
+	return c.ctx.openModal(c.slashCmd.TriggerID, c.slashCmd.ChannelID, dialog)
 }
 
-func newFindDialog(triggerID string, reviewerSlackID string) slack.Dialog {
-	defaultYear, defaultWeekNo := time.Now().ISOWeek()
-	weekOfYearDefault := encodeWeekAndYear(defaultWeekNo, defaultYear)
-	weekOfYearEl := newStaticOptionsDialogInput("year_week", "Week of the Year", weekOfYearDefault, true, weekOfYearOptions(false))
+func newFindDialog(reviewerSlackID string, technologyEl *modalInput) modal {
+	weekOfYearDefault := encodeWeek(scheduling.FirstDayOfWeek(time.Now()))
+	weekOfYearEl := newStaticSelectInput("year_week", "Week of the Year", weekOfYearDefault, true, weekOfYearOptions(false))
 	defaultDay := "Monday"
-	dayEl := newStaticOptionsDialogInput("day", "Day of Week", defaultDay, true, dayOptions())
-	challengeNameEl := newExternalOptionsDialogInput("challenge_name", "Challenge Name", "", false)
-	technologyEl := slack.NewTextInput("technology", "Technology List", "")
-	elements := []slack.DialogElement{
+	dayEl := newStaticSelectInput("day", "Day of Week", defaultDay, true, dayOptions())
+	challengeNameEl := newExternalSelectInput("challenge_name", "Challenge Name", "", false)
+	elements := []*modalInput{
 		weekOfYearEl,
 		dayEl,
 		challengeNameEl,
 		technologyEl,
 	}
-	return slack.Dialog{
-		TriggerID:      triggerID,
-		CallbackID:     "find_reviewers",
-		Title:          "Find Reviewers",
-		SubmitLabel:    "Search",
-		NotifyOnCancel: false,
-		State:          reviewerSlackID,
-		Elements:       elements,
+	return modal{
+		CallbackID:  "find_reviewers",
+		Title:       "Find Reviewers",
+		SubmitLabel: "Search",
+		State:       reviewerSlackID,
+		Elements:    elements,
 	}
 }
 
+func newBookingDialog(encodedScheduleAction string) modal {
+	candidateNameEl := newTextInput("candidate_name", "Candidate Name", "")
+	kindEl := newStaticSelectInput("kind", "Kind", models.CodeReview, false, bookingKindOptions())
+	challengeURLEl := newTextInput("challenge_url", "Challenge Repository URL", "")
+	challengeURLEl.Optional = true
+	notesEl := newTextAreaInput("notes", "Notes", "")
+	notesEl.Optional = true
+
+	elements := []*modalInput{
+		candidateNameEl,
+		kindEl,
+		challengeURLEl,
+		notesEl,
+	}
+	return modal{
+		CallbackID:  "book_reviewer",
+		Title:       "Book Reviewer",
+		SubmitLabel: "Book",
+		State:       encodedScheduleAction,
+		Elements:    elements,
+	}
+}
+
+func bookingKindOptions() []option {
+	selectOptions := make([]option, 0, len(models.BookingKinds))
+	for _, kind := range models.BookingKinds {
+		selectOptions = append(selectOptions, option{
+			Label: models.BookingKindLabel(kind),
+			Value: kind,
+		})
+	}
+	return selectOptions
+}
+
 type sectionMsg struct {
 	ReplaceOriginal bool          `json:"replace_original,omitempty"`
 	Blocks          []slack.Block `json:"blocks,omitempty"`
@@ -245,18 +361,239 @@ func (c command) executeShowBookings() error {
 	if err != nil {
 		log.Println("[ERROR] No such reviewer registered.", err)
 		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", reviewerSlackID)
-		c.ctx.postMessage(c.slashCmd.ChannelID, toMsgOption(errorMsg))
+		c.reply(toMsgOption(errorMsg))
 		return err
 	}
-	challenge, err := models.GetChallengeSetupByName(c.ctx.Env, reviewer.ChallengeName)
-	if err != nil {
-		log.Println("[ERROR] Invalid challenge for reviewer", err)
+	if len(reviewer.Challenges) == 0 {
 		errorMsg := fmt.Sprintf("Reviewer <@%s> does not seem to have a valid challenge they registered. Please use /reviewer edit to register a challenge.", reviewerSlackID)
-		c.ctx.postMessage(c.slashCmd.ChannelID, toMsgOption(errorMsg))
+		c.reply(toMsgOption(errorMsg))
+		return errors.New("[ERROR] Reviewer has no challenge")
+	}
+
+	loc := c.ctx.getUserLocation(c.slashCmd.UserID)
+	for _, challengeID := range reviewer.ChallengeIDs() {
+		challenge, err := models.GetChallengeSetupByID(c.ctx.Env, challengeID)
+		if err != nil {
+			log.Println("[ERROR] Invalid challenge for reviewer", err)
+			continue
+		}
+		member, _ := reviewer.ForChallenge(challengeID)
+		sections := renderBookings(member, challenge, loc)
+		sections = append(sections, c.calendarLinks(member, challenge)...)
+
+		err = c.reply(slack.MsgOptionBlocks(sections...))
+		if err != nil {
+			log.Println("[ERROR] Cannot send the bookings - ", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// calendarLinks offers the bookings of the reviewer and the challenge as calendar feeds
+func (c command) calendarLinks(reviewer models.Reviewer, challenge models.ChallengeSetup) []slack.Block {
+	if c.ctx.Env.ServerURL == "" {
+		log.Println("[INFO] SERVER_URL is not set, calendar links are not available")
+		return nil
+	}
+
+	reviewer, err := models.EnsureReviewerCalendarToken(c.ctx.Env, reviewer)
+	if err != nil {
+		log.Println("[ERROR] Cannot create the reviewer calendar token - ", err)
+		return nil
+	}
+	challengeToken, err := models.EnsureChallengeCalendarToken(c.ctx.Env, challenge.ID)
+	if err != nil {
+		log.Println("[ERROR] Cannot create the challenge calendar token - ", err)
+		return nil
+	}
+
+	reviewerURL := calendarURL(c.ctx.Env.ServerURL, "reviewer", reviewer.CalendarToken)
+	challengeURL := calendarURL(c.ctx.Env.ServerURL, "challenge", challengeToken)
+	return renderCalendarLinks(reviewer, challenge, reviewerURL, challengeURL)
+}
+
+func calendarURL(serverURL, kind, token string) string {
+	return fmt.Sprintf("%s/calendar/%s/%s.ics", strings.TrimSuffix(serverURL, "/"), kind, token)
+}
+
+func (c command) executeReminders() error {
+	reviewer, err := models.GetReviewerBySlackID(c.ctx.Env, c.slashCmd.UserID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", c.slashCmd.UserID)
+		c.reply(toMsgOption(errorMsg))
 		return err
 	}
 
-	sections := renderBookings(reviewer, challenge)
+	switch c.arg {
+	case "on":
+		reviewer.RemindersOff = false
+	case "off":
+		reviewer.RemindersOff = true
+	default:
+		status := "on"
+		if reviewer.RemindersOff {
+			status = "off"
+		}
+		msgText := fmt.Sprintf("Booking reminders and the weekly digest are %s for <@%s>. Use `/reviewer reminders on` or `/reviewer reminders off` to change it.", status, reviewer.SlackID)
+		return c.reply(toMsgOption(msgText))
+	}
 
-	return c.ctx.postMessage(c.slashCmd.ChannelID, slack.MsgOptionBlocks(sections...))
+	err = models.UpdateReviewer(c.ctx.Env, reviewer)
+	if err != nil {
+		log.Println("[ERROR] Could not update reviewer in db ", err)
+		c.reply(toMsgOption("We were not able to update your reminders"))
+		return err
+	}
+
+	msgText := fmt.Sprintf("Booking reminders and the weekly digest are now %s for <@%s>.", c.arg, reviewer.SlackID)
+	return c.reply(toMsgOption(msgText))
+}
+
+// Default and longest report period of /reviewer load, in weeks
+const (
+	defaultLoadWeeks = 8
+	maxLoadWeeks     = 26
+)
+
+func (c command) executeLoadReport() error {
+	weeks := defaultLoadWeeks
+	if c.arg != "" {
+		var err error
+		weeks, err = strconv.Atoi(c.arg)
+		if err != nil || weeks <= 0 || weeks > maxLoadWeeks {
+			errorMsg := fmt.Sprintf("Please give the number of weeks between 1 and %d, e.g. /reviewer load 8", maxLoadWeeks)
+			return c.reply(toMsgOption(errorMsg))
+		}
+	}
+
+	reviewers, err := models.GetAllReviewers(c.ctx.Env)
+	if err != nil {
+		log.Println("[ERROR] Cannot load the reviewers - ", err)
+		c.reply(toMsgOption("We were not able to load the reviewers"))
+		return err
+	}
+
+	report := scheduling.LoadReport(reviewers, weeks, time.Now())
+	sections := renderLoadReport(report, weeks)
+	return c.reply(slack.MsgOptionBlocks(sections...))
+}
+
+func (c command) executeShowChallenges() error {
+	reviewerSlackID := c.reviewerSlackID()
+	reviewer, err := models.GetReviewerBySlackID(c.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", reviewerSlackID)
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
+
+	sections := renderReviewerChallenges(reviewer, challengeNames(c.ctx.Env, reviewer))
+	return c.reply(slack.MsgOptionBlocks(sections...))
+}
+
+func (c command) executePauseReviewer() error {
+	reviewerSlackID := c.reviewerSlackID()
+	reviewer, err := models.GetReviewerBySlackID(c.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", reviewerSlackID)
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
+
+	today := time.Now().Format(models.DateFormat)
+	until := c.flags["until"]
+	if until != "" && until < today {
+		return c.reply(toMsgOption(fmt.Sprintf("%s is in the past, please give the last day of the pause.", until)))
+	}
+	reviewer.Paused = true
+	reviewer.PausedUntil = until
+	err = models.UpdateReviewer(c.ctx.Env, reviewer)
+	if err != nil {
+		log.Println("[ERROR] Could not update reviewer in db ", err)
+		c.reply(toMsgOption("We were not able to pause the reviewer"))
+		return err
+	}
+
+	msgText := fmt.Sprintf("<@%s> is paused, they are not offered for bookings until they are resumed with /reviewer resume.", reviewer.SlackID)
+	if until != "" {
+		msgText = fmt.Sprintf("<@%s> is paused until %s (included), they are not offered for bookings until then.", reviewer.SlackID, until)
+	}
+	kept := 0
+	for _, booking := range reviewer.UpcomingBookings(today) {
+		if reviewer.IsPausedOn(booking.Occurrence.Date) {
+			kept++
+		}
+	}
+	if kept > 0 {
+		msgText = fmt.Sprintf("%s Their %d bookings in that time are kept, use /reviewer bookings <@%s> to unbook them.", msgText, kept, reviewer.SlackID)
+	}
+	return c.reply(toMsgOption(msgText))
+}
+
+func (c command) executeResumeReviewer() error {
+	reviewerSlackID := c.reviewerSlackID()
+	reviewer, err := models.GetReviewerBySlackID(c.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", reviewerSlackID)
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
+	if !reviewer.IsPausedOn(time.Now().Format(models.DateFormat)) {
+		return c.reply(toMsgOption(fmt.Sprintf("<@%s> is not paused.", reviewer.SlackID)))
+	}
+
+	reviewer.Paused = false
+	reviewer.PausedUntil = ""
+	err = models.UpdateReviewer(c.ctx.Env, reviewer)
+	if err != nil {
+		log.Println("[ERROR] Could not update reviewer in db ", err)
+		c.reply(toMsgOption("We were not able to resume the reviewer"))
+		return err
+	}
+	return c.reply(toMsgOption(fmt.Sprintf("<@%s> is offered for bookings again.", reviewer.SlackID)))
+}
+
+func (c command) executeRemoveReviewer() error {
+	reviewer, err := models.GetReviewerBySlackID(c.ctx.Env, c.arg)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered.", c.arg)
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
+
+	loc := c.ctx.getUserLocation(c.slashCmd.UserID)
+	bookings := reviewer.UpcomingBookings(time.Now().Format(models.DateFormat))
+	sections := renderRemoveReviewer(reviewer, bookings, bookingChallenges(c.ctx, reviewer, bookings), loc)
+	return c.reply(slack.MsgOptionBlocks(sections...))
+}
+
+// bookingChallenges loads the challenges of the bookings by their IDs
+func bookingChallenges(ctx commCtx, reviewer models.Reviewer, bookings []models.Booking) map[string]models.ChallengeSetup {
+	challenges := make(map[string]models.ChallengeSetup)
+	for _, booking := range bookings {
+		challengeID := bookingChallengeID(reviewer, booking)
+		if _, ok := challenges[challengeID]; ok {
+			continue
+		}
+		challenge, err := models.GetChallengeSetupByID(ctx.Env, challengeID)
+		if err != nil {
+			log.Println("[ERROR] Invalid challenge for reviewer", err)
+		}
+		challenges[challengeID] = challenge
+	}
+	return challenges
+}
+
+func challengeNames(env config.Environment, reviewer models.Reviewer) map[string]string {
+	names := make(map[string]string)
+	for _, option := range challengeOptions(env, reviewer.ChallengeIDs()) {
+		names[option.Value] = option.Label
+	}
+	return names
 }
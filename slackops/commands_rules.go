@@ -0,0 +1,146 @@
+package slackops
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/scheduling"
+	"github.com/nlopes/slack"
+)
+
+func (c command) reviewerSlackID() string {
+	if c.arg == "" {
+		return c.slashCmd.UserID
+	}
+	return parseSlackIDFromString(c.arg)
+}
+
+func (c command) executeNewRule() error {
+	reviewerSlackID := c.reviewerSlackID()
+	reviewer, err := models.GetReviewerBySlackID(c.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", reviewerSlackID)
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
+
+	challenge, err := models.GetChallengeSetupByID(c.ctx.Env, reviewer.ChallengeID)
+	if err != nil {
+		log.Println("[ERROR] Invalid challenge for reviewer", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> does not seem to have a valid challenge they registered. Please use /reviewer edit to register a challenge.", reviewerSlackID)
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
+
+	dialog := newRuleDialog(reviewerSlackID, challenge)
+
+	return c.ctx.openModal(c.slashCmd.TriggerID, c.slashCmd.ChannelID, dialog)
+}
+
+func newRuleDialog(reviewerSlackID string, challenge models.ChallengeSetup) modal {
+	slotEl := newStaticSelectInput("slot_id", "Slot", "", false, slotOptions(challenge))
+	intervalEl := newStaticSelectInput("interval", "Repeat", "1", false, intervalOptions())
+	startWeek := encodeWeek(scheduling.FirstDayOfWeek(time.Now()))
+	startEl := newStaticSelectInput("start_date", "Starting Week", startWeek, false, weekOfYearOptions(false))
+
+	monthsEl := newTextInput("months", "Only In Months", "")
+	monthsEl.Optional = true
+	monthsEl.Hint = "Comma separated list of months, e.g. March, April. Leave empty for all months."
+	untilEl := newTextInput("until", "Until", "")
+	untilEl.Optional = true
+	untilEl.Hint = "Last date of the rule as YYYY-MM-DD. Leave empty if it does not end."
+
+	elements := []*modalInput{
+		slotEl,
+		intervalEl,
+		startEl,
+		monthsEl,
+		untilEl,
+	}
+	return modal{
+		CallbackID:  "availability_rule",
+		Title:       "Recurring Availability",
+		SubmitLabel: "Add",
+		State:       reviewerSlackID,
+		Elements:    elements,
+	}
+}
+
+func slotOptions(challenge models.ChallengeSetup) []option {
+	slots := challenge.GetSlotsInOrder()
+	selectOptions := make([]option, 0, len(slots))
+	for _, slot := range slots {
+		selectOptions = append(selectOptions, option{
+			Label: fmt.Sprintf("%s (%s - %s)", slot.Name, slot.StartTime, slot.EndTime),
+			Value: slot.ID,
+		})
+	}
+	return selectOptions
+}
+
+func intervalOptions() []option {
+	selectOptions := make([]option, 0, 4)
+	selectOptions = append(selectOptions, option{
+		Label: "Every week",
+		Value: "1",
+	})
+	for i := 2; i < 5; i++ {
+		selectOptions = append(selectOptions, option{
+			Label: fmt.Sprintf("Every %d weeks", i),
+			Value: strconv.Itoa(i),
+		})
+	}
+	return selectOptions
+}
+
+func (c command) executeOutOfOffice() error {
+	dialog := newOutOfOfficeDialog(c.reviewerSlackID())
+
+	return c.ctx.openModal(c.slashCmd.TriggerID, c.slashCmd.ChannelID, dialog)
+}
+
+func newOutOfOfficeDialog(reviewerSlackID string) modal {
+	fromEl := newTextInput("from_date", "From", time.Now().Format(models.DateFormat))
+	fromEl.Hint = "First day away as YYYY-MM-DD"
+	toEl := newTextInput("to_date", "To", "")
+	toEl.Optional = true
+	toEl.Hint = "Last day away as YYYY-MM-DD. Leave empty for a single day."
+	reasonEl := newTextInput("reason", "Reason", "")
+	reasonEl.Optional = true
+
+	return modal{
+		CallbackID:  "out_of_office",
+		Title:       "Out of Office",
+		SubmitLabel: "Add",
+		State:       reviewerSlackID,
+		Elements: []*modalInput{
+			fromEl,
+			toEl,
+			reasonEl,
+		},
+	}
+}
+
+func (c command) executeShowRules() error {
+	reviewerSlackID := c.reviewerSlackID()
+	reviewer, err := models.GetReviewerBySlackID(c.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", reviewerSlackID)
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
+
+	challenge, err := models.GetChallengeSetupByID(c.ctx.Env, reviewer.ChallengeID)
+	if err != nil {
+		log.Println("[ERROR] Invalid challenge for reviewer", err)
+	}
+
+	sections := renderAvailabilityRules(reviewer, challenge)
+
+	return c.reply(slack.MsgOptionBlocks(sections...))
+}
@@ -0,0 +1,190 @@
+package slackops
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/models"
+	"github.com/nlopes/slack"
+)
+
+// Slack modals show at most 100 options in a select, and 100 groups of them
+const (
+	maxSelectOptions = 100
+	maxOptionGroups  = 100
+)
+
+func (c command) executeShowTags() error {
+	taxonomy, err := models.GetTaxonomy(c.ctx.Env)
+	if err != nil {
+		log.Println("[ERROR] Cannot load the tags - ", err)
+		c.reply(toMsgOption("There was an error. The tags cannot be shown."))
+		return err
+	}
+
+	sections := renderTags(taxonomy, 0)
+	return c.reply(slack.MsgOptionBlocks(sections...))
+}
+
+func (c command) executeReviewerTags() error {
+	reviewerSlackID := c.reviewerSlackID()
+	reviewer, err := models.GetReviewerBySlackID(c.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", reviewerSlackID)
+		c.reply(toMsgOption(errorMsg))
+		return err
+	}
+
+	for _, challengeID := range reviewer.ChallengeIDs() {
+		postTagPicker(c.ctx, c.reply, reviewer, challengeID)
+	}
+	return nil
+}
+
+// postTagPicker replies with the tags of the reviewer for the challenge, to pick them from the vocabulary
+func postTagPicker(ctx commCtx, reply func(slack.MsgOption) error, reviewer models.Reviewer, challengeID string) {
+	sections, err := tagPickerSections(ctx.Env, reviewer, challengeID)
+	if err != nil {
+		log.Println("[ERROR] Cannot show the tags of the reviewer - ", err)
+		return
+	}
+	reply(slack.MsgOptionBlocks(sections...))
+}
+
+func tagPickerSections(env config.Environment, reviewer models.Reviewer, challengeID string) ([]slack.Block, error) {
+	reviewer, err := reviewer.ForChallenge(challengeID)
+	if err != nil {
+		return nil, err
+	}
+	taxonomy, err := models.GetTaxonomy(env)
+	if err != nil {
+		return nil, err
+	}
+
+	challengeName := challengeID
+	if challenge, err := models.GetChallengeByID(env, challengeID); err == nil {
+		challengeName = challenge.Name
+	}
+	return renderTagPicker(reviewer, taxonomy, challengeName), nil
+}
+
+// tagOptionGroups groups the tags to pick by their broadest ancestor, and counts the tags that do not fit in the
+// select
+func tagOptionGroups(taxonomy models.Taxonomy) ([]optionGroup, int) {
+	groups := make([]optionGroup, 0)
+	left := 0
+	for _, group := range tagGroups(taxonomy) {
+		if len(groups) == maxOptionGroups {
+			left += len(group)
+			continue
+		}
+		options := make([]option, 0, len(group))
+		for _, tag := range group {
+			if len(options) == maxSelectOptions {
+				left++
+				continue
+			}
+			options = append(options, option{
+				Label: tag.Name,
+				Value: tag.ID,
+			})
+		}
+		groups = append(groups, optionGroup{
+			Label:   group[0].Name,
+			Options: options,
+		})
+	}
+	return groups, left
+}
+
+// newTagPickerDialog picks the technologies of the reviewer for the challenge from the tags
+func newTagPickerDialog(reviewer models.Reviewer, taxonomy models.Taxonomy) modal {
+	groups, left := tagOptionGroups(taxonomy)
+	tagsEl := newMultiSelectInput("tags", "Technologies", taxonomy.TagsOf(reviewer), groups)
+	tagsEl.Hint = "Searching a technology also finds the reviewers of the narrower ones."
+	if left > 0 {
+		tagsEl.Hint = fmt.Sprintf("%d tags do not fit in the list, the picked ones are kept. Ask to move them under fewer broader tags.", left)
+	}
+	return modal{
+		CallbackID:  "pick_tags",
+		Title:       "Pick Tags",
+		SubmitLabel: "Save",
+		State:       encodeTagPickerState(reviewer.SlackID, reviewer.ChallengeID),
+		Elements:    []*modalInput{tagsEl},
+	}
+}
+
+func newTagDialog(tag models.Tag, taxonomy models.Taxonomy) modal {
+	nameEl := newTextInput("tag_name", "Name", tag.Name)
+	nameEl.Hint = "E.g. Kotlin. Searches are case insensitive."
+	aliasesEl := newTextInput("aliases", "Aliases", strings.Join(tag.Aliases, ", "))
+	aliasesEl.Optional = true
+	aliasesEl.Hint = "Comma separated other names of the technology, e.g. kt"
+	elements := []*modalInput{
+		nameEl,
+		aliasesEl,
+	}
+
+	// The tag and its narrower tags cannot be the parent
+	parents := make([]option, 0, len(taxonomy))
+	for _, other := range taxonomy.Ordered() {
+		if other.ID == tag.ID || (tag.ID != "" && taxonomy.IsAncestor(tag.ID, other.ID)) {
+			continue
+		}
+		if len(parents) == maxSelectOptions {
+			break
+		}
+		parents = append(parents, option{
+			Label: other.Name,
+			Value: other.ID,
+		})
+	}
+	if len(parents) > 0 {
+		parentEl := newStaticSelectInput("parent", "Broader Technology", tag.Parent, true, parents)
+		parentEl.Hint = "Searching the broader technology finds this one too, e.g. Android for Kotlin"
+		elements = append(elements, parentEl)
+	}
+
+	title := "Add Tag"
+	if tag.ID != "" {
+		title = "Edit Tag"
+	}
+	return modal{
+		CallbackID:  "edit_tag",
+		Title:       title,
+		SubmitLabel: "Save",
+		State:       tag.ID,
+		Elements:    elements,
+	}
+}
+
+// technologyDialogInput picks the technology from the tags, or types it if there are no tags yet
+func technologyDialogInput(env config.Environment, label string) *modalInput {
+	taxonomy, err := models.GetTaxonomy(env)
+	if err != nil {
+		log.Println("[ERROR] Cannot load the tags - ", err)
+	}
+	if len(taxonomy) == 0 {
+		technologyEl := newTextInput("technology", label, "")
+		technologyEl.Optional = true
+		technologyEl.Hint = "E.g. Swift. Leave empty to match all reviewers."
+		return technologyEl
+	}
+
+	options := make([]option, 0, len(taxonomy))
+	for _, tag := range taxonomy.Ordered() {
+		if len(options) == maxSelectOptions {
+			break
+		}
+		options = append(options, option{
+			Label: tag.Name,
+			Value: tag.ID,
+		})
+	}
+	technologyEl := newStaticSelectInput("technology", label, "", true, options)
+	technologyEl.Hint = "Also finds the narrower technologies, e.g. Kotlin for Android. Leave empty to match all reviewers."
+	return technologyEl
+}
@@ -1,13 +1,33 @@
 package slackops
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/keremk/challenge-bot/config"
 	"github.com/keremk/challenge-bot/models"
 	"github.com/nlopes/slack"
 )
 
+// Largest file that is downloaded
+const maxDownloadSize = 5 * 1024 * 1024
+
+// errAppNotInstalled is returned for the teams that uninstalled the app or revoked its token
+var errAppNotInstalled = errors.New("[ERROR] The app is not installed in the workspace anymore")
+
+// Slack API errors of a bot token that is not valid anymore
+var revokedTokenErrors = []string{"token_revoked", "account_inactive", "invalid_auth"}
+
 type commCtx struct {
 	Env    config.Environment
 	UserID string
@@ -34,11 +54,39 @@ func (c commCtx) postMessage(targetChannel string, msgOption slack.MsgOption) er
 	_, _, err = slackClient.PostMessage(targetChannel, msgOption)
 	// log.Printf("[INFO]Message TS is : %s", messageTs)
 	if err != nil {
+		c.deactivateIfRevoked(err)
 		return err
 	}
 	return nil
 }
 
+// postEphemeral posts the message in the channel, only visible to the user
+func (c commCtx) postEphemeral(targetChannel, userID string, msgOption slack.MsgOption) error {
+	token, err := c.getToken()
+	if err != nil {
+		return err
+	}
+
+	slackClient := slack.New(token)
+	_, err = slackClient.PostEphemeral(targetChannel, userID, msgOption)
+	if err != nil {
+		c.deactivateIfRevoked(err)
+		return err
+	}
+	return nil
+}
+
+// postMessageTs posts the message and returns its channel and timestamp, to update the message later
+func (c commCtx) postMessageTs(targetChannel string, msgOption slack.MsgOption) (string, string, error) {
+	token, err := c.getToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	slackClient := slack.New(token)
+	return slackClient.PostMessage(targetChannel, msgOption)
+}
+
 func (c commCtx) updateMessage(targetChannel, messageTs string, msgOption slack.MsgOption) error {
 	token, err := c.getToken()
 	if err != nil {
@@ -56,17 +104,62 @@ func (c commCtx) updateMessage(targetChannel, messageTs string, msgOption slack.
 	return nil
 }
 
-func (c commCtx) showDialog(triggerID string, dialog slack.Dialog) error {
+func (c commCtx) openModal(triggerID, channelID string, m modal) error {
+	request := struct {
+		TriggerID string `json:"trigger_id"`
+		View      view   `json:"view"`
+	}{
+		View: modalView(m, modalMetadata{Channel: channelID}),
+	}
+	err := c.callAPI("views.open", request)
+	if err != nil {
+		log.Println("[ERROR] Cannot open the modal ", err)
+	}
+	return err
+}
+
+// callAPI calls a Slack Web API method with a JSON body, for the methods the Slack client does not have
+func (c commCtx) callAPI(method string, request interface{}) error {
 	token, err := c.getToken()
 	if err != nil {
 		return err
 	}
-	slackClient := slack.New(token)
-	err = slackClient.OpenDialog(triggerID, dialog)
+	body, err := json.Marshal(request)
 	if err != nil {
-		log.Println("[ERROR] Cannot create the dialog ", err)
+		return err
 	}
-	return err
+
+	req, err := http.NewRequest(http.MethodPost, slack.APIURL+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	client := &http.Client{
+		Timeout: time.Second * 10,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var response struct {
+		slack.SlackResponse
+		ResponseMetadata struct {
+			Messages []string `json:"messages"`
+		} `json:"response_metadata"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	if err != nil {
+		return fmt.Errorf("[ERROR] Invalid response from %s, status - %d", method, resp.StatusCode)
+	}
+	if !response.Ok {
+		c.deactivateIfRevoked(errors.New(response.Error))
+		return fmt.Errorf("[ERROR] %s failed - %s %s", method, response.Error, strings.Join(response.ResponseMetadata.Messages, ", "))
+	}
+	return nil
 }
 
 func (c commCtx) getUserInfo(userID string) (slack.User, error) {
@@ -85,6 +178,165 @@ func (c commCtx) getUserInfo(userID string) (slack.User, error) {
 	return *user, nil
 }
 
+// getUserLocation returns the time zone from the user's Slack profile, UTC if it cannot be retrieved.
+func (c commCtx) getUserLocation(userID string) *time.Location {
+	user, err := c.getUserInfo(userID)
+	if err != nil {
+		return time.UTC
+	}
+	return models.LoadLocation(user.TZ)
+}
+
+// The file ID in the links to files shared in Slack, e.g. https://team.slack.com/files/U123/F456/cal.ics or
+// https://files.slack.com/files-pri/T123-F456/cal.ics
+var slackFileLinkRegexp = regexp.MustCompile(`^https://[a-z0-9-]+\.slack\.com/(?:files/[^/]+|files-pri/T[A-Z0-9]+-)/?(F[A-Z0-9]+)`)
+var slackFileIDRegexp = regexp.MustCompile(`^F[A-Z0-9]+$`)
+
+// The only host the files are downloaded from, with the bot token
+const slackFilesHost = "files.slack.com"
+
+// slackFileID returns the ID of the file shared in Slack, by its link or by its ID
+func slackFileID(fileLink string) (string, error) {
+	fileLink = strings.TrimSpace(fileLink)
+	if m := slackFileLinkRegexp.FindStringSubmatch(fileLink); m != nil {
+		return m[1], nil
+	}
+	if slackFileIDRegexp.MatchString(fileLink) {
+		return fileLink, nil
+	}
+	return "", fmt.Errorf("[ERROR] Not a link to a file shared in Slack - %s", fileLink)
+}
+
+// downloadFile reads a file shared in Slack by its link or ID. The file is looked up with files.info, and is only
+// downloaded from files.slack.com, as the request has the bot token.
+func (c commCtx) downloadFile(fileLink string) ([]byte, error) {
+	fileID, err := slackFileID(fileLink)
+	if err != nil {
+		return nil, err
+	}
+	token, err := c.getToken()
+	if err != nil {
+		return nil, err
+	}
+	file, _, _, err := slack.New(token).GetFileInfo(fileID, 0, 0)
+	if err != nil {
+		log.Println("[ERROR] Cannot get the file info - ", err)
+		return nil, err
+	}
+	if file.Size > maxDownloadSize {
+		return nil, fmt.Errorf("[ERROR] File is larger than %d bytes", maxDownloadSize)
+	}
+
+	downloadURL, err := url.Parse(file.URLPrivateDownload)
+	if err != nil || downloadURL.Scheme != "https" || downloadURL.Hostname() != slackFilesHost {
+		return nil, fmt.Errorf("[ERROR] Unexpected download URL of the file %s - %s", fileID, file.URLPrivateDownload)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, downloadURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	client := &http.Client{
+		Timeout: time.Second * 10,
+		// Redirects would send the token to another host
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		log.Println("[ERROR] Cannot download the file - ", err)
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("[ERROR] Cannot download the file, status - %d", resp.StatusCode)
+	}
+
+	contents, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxDownloadSize+1))
+	if err == nil && len(contents) > maxDownloadSize {
+		return nil, fmt.Errorf("[ERROR] File is larger than %d bytes", maxDownloadSize)
+	}
+	return contents, err
+}
+
+// deactivateIfRevoked marks the team inactive when a Slack API call fails because its bot token is revoked, in case
+// the app_uninstalled or tokens_revoked event was missed
+func (c commCtx) deactivateIfRevoked(err error) {
+	if c.AsUser {
+		return
+	}
+	for _, revoked := range revokedTokenErrors {
+		if err.Error() == revoked {
+			log.Println("[INFO] Bot token of the team is revoked - ", c.TeamID, err)
+			err = models.DeactivateSlackTeam(c.Env, c.TeamID)
+			if err != nil {
+				log.Println("[ERROR] Cannot deactivate the team - ", err)
+			}
+			return
+		}
+	}
+}
+
+// reinstallMessage asks to install the app again, when it is not installed in the workspace anymore
+func reinstallMessage(env config.Environment) string {
+	if env.ServerURL == "" {
+		return "The challenge app is not installed in this workspace anymore. Please ask your Slack admin to install it again."
+	}
+	return fmt.Sprintf("The challenge app is not installed in this workspace anymore. Please install it again at %s/auth/slack/install.html",
+		strings.TrimSuffix(env.ServerURL, "/"))
+}
+
+// respond replies to the response URL of a command or an interaction, which works without a token
+func respond(responseURL string, text string) error {
+	return respondMessage(responseURL, toMsgOption(text), false)
+}
+
+// respondMessage replies to the response URL only to the user, or replaces the message of the interaction.
+// It works in the channels the bot is not a member of.
+func respondMessage(responseURL string, msgOption slack.MsgOption, replaceOriginal bool) error {
+	_, values, err := slack.UnsafeApplyMsgOptions("", "", "", msgOption)
+	if err != nil {
+		return err
+	}
+	response := struct {
+		ResponseType    string          `json:"response_type,omitempty"`
+		ReplaceOriginal bool            `json:"replace_original"`
+		Text            string          `json:"text,omitempty"`
+		Blocks          json.RawMessage `json:"blocks,omitempty"`
+	}{
+		ReplaceOriginal: replaceOriginal,
+		Text:            values.Get("text"),
+	}
+	// The replaced message stays visible to the ones who could see it
+	if !replaceOriginal {
+		response.ResponseType = "ephemeral"
+	}
+	if blocks := values.Get("blocks"); blocks != "" {
+		response.Blocks = json.RawMessage(blocks)
+	}
+	body, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{
+		Timeout: time.Second * 10,
+	}
+	resp, err := client.Post(responseURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		log.Println("[ERROR] Cannot reply to the response URL - ", err)
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("[ERROR] Cannot reply to the response URL, status - %d", resp.StatusCode)
+	}
+	return nil
+}
+
 func (c commCtx) getToken() (string, error) {
 	if c.AsUser {
 		return getUserToken(c.Env, c.UserID)
@@ -99,6 +351,10 @@ func getUserToken(env config.Environment, userID string) (string, error) {
 		log.Println("[ERROR] Cannot retrieve user token ", err)
 		return "", err
 	}
+	if user.Token == "" {
+		log.Println("[ERROR] User token is revoked - ", userID)
+		return "", errAppNotInstalled
+	}
 	return user.Token, err
 }
 
@@ -108,5 +364,9 @@ func getBotToken(env config.Environment, teamID string) (string, error) {
 		log.Println("[ERROR] Cannot retrieve bot token ", err)
 		return "", err
 	}
+	if team.Inactive || team.BotToken == "" {
+		log.Println("[ERROR] App is not installed in the team anymore - ", teamID)
+		return "", errAppNotInstalled
+	}
 	return team.BotToken, err
 }
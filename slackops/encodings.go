@@ -7,35 +7,58 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/keremk/challenge-bot/models"
 )
 
-func encodeWeekAndYear(weekNo, year int) string {
-	return fmt.Sprintf("%d-%d", weekNo, year)
+const generalWeek = "General"
+const compactDateFormat = "20060102"
+
+func encodeWeek(weekStart time.Time) string {
+	return weekStart.Format(compactDateFormat)
 }
 
-func decodeWeekAndYear(input string) (int, int) {
-	log.Println("[INFO] Incoming encoded input - ", input)
-	s := strings.Split(input, "-")
-	if len(s) < 2 {
-		return 0, time.Now().Year()
-	}
-	year, err := strconv.Atoi(s[1])
-	if err != nil || year < 2019 || year > 2050 {
-		return 0, time.Now().Year()
-	}
-	week, err := strconv.Atoi(s[0])
-	if err != nil || week < 0 || week > 52 {
-		return 0, time.Now().Year()
+func decodeWeek(input string) (time.Time, error) {
+	weekStart, err := time.Parse(compactDateFormat, input)
+	if err != nil {
+		log.Println("[ERROR] Incorrect week encoding - ", input)
 	}
-
-	return week, year
+	return weekStart, err
 }
 
+// scheduleActionInfo identifies a slot of a reviewer. Date is the compact date of the slot occurrence,
+// or generalWeek for the general (all weeks) schedule.
 type scheduleActionInfo struct {
 	SlotID     string
 	ReviewerID string
-	WeekNo     int
-	Year       int
+	Date       string
+}
+
+func newScheduleActionInfo(reviewerID string, slotID string, occurrence models.SlotOccurrence) scheduleActionInfo {
+	date := generalWeek
+	if occurrence.Date != "" {
+		day, err := occurrence.Day()
+		if err == nil {
+			date = day.Format(compactDateFormat)
+		}
+	}
+	return scheduleActionInfo{
+		SlotID:     slotID,
+		ReviewerID: reviewerID,
+		Date:       date,
+	}
+}
+
+func (info scheduleActionInfo) isGeneral() bool {
+	return info.Date == generalWeek
+}
+
+func (info scheduleActionInfo) occurrence() (models.SlotOccurrence, error) {
+	day, err := time.Parse(compactDateFormat, info.Date)
+	if err != nil {
+		return models.SlotOccurrence{}, err
+	}
+	return models.NewSlotOccurrence(day, info.SlotID), nil
 }
 
 type actionType = string
@@ -44,6 +67,25 @@ const (
 	scheduleUpdate actionType = "schedule_update"
 	findReviewers  actionType = "find_reviewers"
 	showBookings   actionType = "show_bookings"
+	assignReviewer actionType = "assign_reviewers"
+	declineBooking actionType = "decline_booking"
+
+	importAvailability actionType = "import_availability"
+	reviewerChallenges actionType = "reviewer_challenges"
+	editSlots          actionType = "edit_slots"
+	editTags           actionType = "edit_tags"
+	pickTags           actionType = "pick_tags"
+	needOffer          actionType = "need_offer"
+	cancelNeed         actionType = "cancel_need"
+	deleteChallenge    actionType = "delete_challenge"
+	removeReviewer     actionType = "remove_reviewer"
+	candidateCard      actionType = "candidate_card"
+
+	// Link buttons, Slack still sends the action but there is nothing to do
+	downloadCalendar actionType = "download_calendar"
+
+	removeRule        actionType = "remove_rule"
+	removeOutOfOffice actionType = "remove_out_of_office"
 )
 
 func encodeAction(action actionType, input string) string {
@@ -60,33 +102,123 @@ func decodeAction(input string) (actionType, string, error) {
 }
 
 func encodeScheduleActionInfo(input scheduleActionInfo) string {
-	return fmt.Sprintf("%s-%s-%d-%d", input.SlotID, input.ReviewerID, input.WeekNo, input.Year)
+	return fmt.Sprintf("%s-%s-%s", input.SlotID, input.ReviewerID, input.Date)
 }
 
 func decodeScheduleActionInfo(actionID string) (scheduleActionInfo, error) {
 	s := strings.Split(actionID, "-")
-	if len(s) < 4 {
+	if len(s) < 3 {
 		return scheduleActionInfo{}, errors.New("[ERROR] Encoding for actionID is not correct")
 	}
 
-	weekNo, err := strconv.Atoi(s[2])
-	if err != nil {
-		return scheduleActionInfo{}, err
-	}
-
-	year, err := strconv.Atoi(s[3])
-	if err != nil {
-		return scheduleActionInfo{}, err
-	}
-
 	return scheduleActionInfo{
 		SlotID:     s[0],
 		ReviewerID: s[1],
-		WeekNo:     weekNo,
-		Year:       year,
+		Date:       s[2],
 	}, nil
 }
 
+// encodeRuleActionInfo identifies a rule or out of office entry of a reviewer
+func encodeRuleActionInfo(reviewerID, id string) string {
+	return fmt.Sprintf("%s-%s", reviewerID, id)
+}
+
+func decodeRuleActionInfo(input string) (string, string, error) {
+	s := strings.Split(input, "-")
+	if len(s) < 2 {
+		return "", "", errors.New("[ERROR] Encoding for rule action is not correct")
+	}
+	return s[0], s[1], nil
+}
+
+type slotOperation = string
+
+const (
+	addSlot      slotOperation = "add"
+	moveSlotUp   slotOperation = "up"
+	moveSlotDown slotOperation = "down"
+	deleteSlot   slotOperation = "remove"
+	editSlot     slotOperation = "edit"
+)
+
+// encodeSlotActionInfo identifies an operation on a challenge slot, e.g. "up-MondayMorning". The challenge ID is
+// in the button value, as challenge names can contain any character.
+func encodeSlotActionInfo(operation slotOperation, slotID string) string {
+	return fmt.Sprintf("%s-%s", operation, slotID)
+}
+
+func decodeSlotActionInfo(input string) (slotOperation, string, error) {
+	s := strings.SplitN(input, "-", 2)
+	if len(s) < 2 {
+		return "", "", errors.New("[ERROR] Encoding for slot action is not correct")
+	}
+	return s[0], s[1], nil
+}
+
+// encodeSlotState keeps the slot of a challenge in a modal. Slot IDs only contain letters and digits, so the
+// challenge ID comes after the first separator.
+func encodeSlotState(slotID, challengeID string) string {
+	return fmt.Sprintf("%s-%s", slotID, challengeID)
+}
+
+func decodeSlotState(input string) (string, string, error) {
+	s := strings.SplitN(input, "-", 2)
+	if len(s) < 2 {
+		return "", "", errors.New("[ERROR] Encoding for slot state is not correct")
+	}
+	return s[0], s[1], nil
+}
+
+// Operations on a challenge of a reviewer, encoded with encodeRuleActionInfo. The challenge ID is in the button value.
+const (
+	editMembership  = "edit"
+	leaveMembership = "leave"
+)
+
+// Answers to a slot offered for a need, encoded with encodeRuleActionInfo. The need ID is in the button value.
+const (
+	acceptOffer = "accept"
+	passOffer   = "pass"
+)
+
+// Answers to deleting a challenge, the challenge ID is in the button value
+const (
+	confirmDelete = "delete"
+	keepChallenge = "keep"
+)
+
+// Answers to removing a reviewer, the reviewer's Slack ID is in the button value
+const (
+	confirmRemove = "remove"
+	keepReviewer  = "keep"
+)
+
+// Operations on a candidate card, the candidate ID is in the button value
+const (
+	extendDeadline    = "extend"
+	withdrawCandidate = "withdraw"
+)
+
+// Operations on the tag vocabulary, the tag ID or the page number is in the button value
+const (
+	addTag   = "add"
+	editTag  = "edit"
+	tagsPage = "page"
+)
+
+// encodeTagPickerState identifies the reviewer's challenge to pick the tags of, e.g. "U123,android-123"
+func encodeTagPickerState(reviewerSlackID, challengeID string) string {
+	return fmt.Sprintf("%s,%s", reviewerSlackID, challengeID)
+}
+
+func decodeTagPickerState(input string) (string, string, error) {
+	s := strings.SplitN(input, ",", 2)
+	if len(s) < 2 {
+		return "", "", errors.New("[ERROR] Encoding for tag picker is not correct")
+	}
+	return s[0], s[1], nil
+}
+
 func encodeDay(dayNo int) string {
 	return strconv.Itoa(dayNo)
 }
@@ -98,3 +230,25 @@ func decodeDay(input string) (int, error) {
 	}
 	return day, err
 }
+
+// encodeAssignment joins the slots of the proposed reviewers, e.g. "TuesdayAfternoon-U123-20190716,MondayMorning-U456-20190715"
+func encodeAssignment(infos []scheduleActionInfo) string {
+	encoded := make([]string, 0, len(infos))
+	for _, info := range infos {
+		encoded = append(encoded, encodeScheduleActionInfo(info))
+	}
+	return strings.Join(encoded, ",")
+}
+
+func decodeAssignment(input string) ([]scheduleActionInfo, error) {
+	s := strings.Split(input, ",")
+	infos := make([]scheduleActionInfo, 0, len(s))
+	for _, encoded := range s {
+		info, err := decodeScheduleActionInfo(encoded)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
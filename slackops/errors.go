@@ -1,7 +0,0 @@
-package slackops
-
-type ValidationError struct{}
-
-func (e ValidationError) Error() string {
-	return "Invalid validation token received from Slack server"
-}
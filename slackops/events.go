@@ -0,0 +1,221 @@
+package slackops
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/models"
+)
+
+// eventEnvelope is the outer event of the Events API, see https://api.slack.com/events-api#receiving_events
+type eventEnvelope struct {
+	Type      string          `json:"type"`
+	Challenge string          `json:"challenge"`
+	TeamID    string          `json:"team_id"`
+	EventID   string          `json:"event_id"`
+	Event     json.RawMessage `json:"event"`
+}
+
+type innerEvent struct {
+	Type string `json:"type"`
+}
+
+type appHomeOpenedEvent struct {
+	User string `json:"user"`
+	Tab  string `json:"tab"`
+}
+
+type userChangeEvent struct {
+	User struct {
+		ID string `json:"id"`
+		TZ string `json:"tz"`
+	} `json:"user"`
+}
+
+type memberJoinedChannelEvent struct {
+	User    string `json:"user"`
+	Channel string `json:"channel"`
+}
+
+type fileSharedEvent struct {
+	FileID    string `json:"file_id"`
+	UserID    string `json:"user_id"`
+	ChannelID string `json:"channel_id"`
+}
+
+type tokensRevokedEvent struct {
+	Tokens struct {
+		OAuth []string `json:"oauth"`
+		Bot   []string `json:"bot"`
+	} `json:"tokens"`
+}
+
+// eventHandler decodes the inner event into its type and handles it
+type eventHandler func(ctx commCtx, event json.RawMessage) error
+
+// eventHandlers are the handlers of the event types the app subscribes to
+var eventHandlers = map[string]eventHandler{
+	"app_home_opened":       handleAppHomeOpened,
+	"user_change":           handleUserChange,
+	"member_joined_channel": handleMemberJoinedChannel,
+	"file_shared":           handleFileShared,
+	"app_uninstalled":       handleAppUninstalled,
+	"tokens_revoked":        handleTokensRevoked,
+}
+
+// HandleEvent handles the events of the Events API, and returns the response to the URL verification. Retries of
+// events that are already handled are skipped, retryNum is the X-Slack-Retry-Num header of the request.
+func HandleEvent(env config.Environment, body []byte, retryNum int) ([]byte, error) {
+	var envelope eventEnvelope
+	err := json.Unmarshal(body, &envelope)
+	if err != nil {
+		log.Println("[ERROR] Unable to unmarshall the event ", err)
+		return nil, err
+	}
+
+	switch envelope.Type {
+	case "url_verification":
+		return json.Marshal(struct {
+			Challenge string `json:"challenge"`
+		}{envelope.Challenge})
+	case "event_callback":
+		isHandled, err := models.RecordSlackEvent(env, envelope.EventID, time.Now())
+		if err != nil {
+			log.Println("[ERROR] Unable to record the event - ", envelope.EventID, err)
+		}
+		if isHandled && retryNum > 0 {
+			log.Println("[INFO] Skipping the retry of the event - ", envelope.EventID, retryNum)
+			return nil, nil
+		}
+		return nil, dispatchEvent(env, envelope)
+	default:
+		log.Println("[ERROR] Unknown event - ", envelope.Type)
+		return nil, errors.New("[ERROR] Unknown event")
+	}
+}
+
+// dispatchEvent runs the handler of the inner event in the background, as Slack expects a response in 3 seconds
+func dispatchEvent(env config.Environment, envelope eventEnvelope) error {
+	var event innerEvent
+	err := json.Unmarshal(envelope.Event, &event)
+	if err != nil {
+		log.Println("[ERROR] Unable to unmarshall the inner event ", err)
+		return err
+	}
+
+	handler, ok := eventHandlers[event.Type]
+	if !ok {
+		log.Println("[INFO] Ignoring the event - ", event.Type)
+		return nil
+	}
+	ctx := newCommCtx(env, "", envelope.TeamID, false)
+	go func() {
+		err := handler(ctx, envelope.Event)
+		if err != nil {
+			log.Println("[ERROR] Cannot handle the event - ", event.Type, err)
+		}
+	}()
+	return nil
+}
+
+func handleAppHomeOpened(ctx commCtx, event json.RawMessage) error {
+	var e appHomeOpenedEvent
+	err := json.Unmarshal(event, &e)
+	if err != nil {
+		return err
+	}
+	// The Messages tab of the app has nothing to publish
+	if e.Tab != "" && e.Tab != "home" {
+		return nil
+	}
+	return ctx.publishHome(e.User)
+}
+
+// handleUserChange keeps the time zone of the reviewers in sync with their Slack profile
+func handleUserChange(ctx commCtx, event json.RawMessage) error {
+	var e userChangeEvent
+	err := json.Unmarshal(event, &e)
+	if err != nil {
+		return err
+	}
+
+	reviewer, err := models.GetReviewerBySlackID(ctx.Env, e.User.ID)
+	if err != nil {
+		// Not a reviewer
+		return nil
+	}
+	timeZone := models.ValidTimeZone(e.User.TZ)
+	if e.User.TZ == "" || reviewer.TimeZone == timeZone {
+		return nil
+	}
+	log.Printf("[INFO] Time zone of the reviewer %s changed from %s to %s", reviewer.Name, reviewer.TimeZone, timeZone)
+	reviewer.TimeZone = timeZone
+	return models.UpdateReviewer(ctx.Env, reviewer)
+}
+
+// handleMemberJoinedChannel introduces the app when it is added to a channel
+func handleMemberJoinedChannel(ctx commCtx, event json.RawMessage) error {
+	var e memberJoinedChannelEvent
+	err := json.Unmarshal(event, &e)
+	if err != nil {
+		return err
+	}
+
+	team, err := models.GetSlackTeam(ctx.Env, ctx.TeamID)
+	if err != nil {
+		return err
+	}
+	if team.BotUserID == "" || e.User != team.BotUserID {
+		return nil
+	}
+	msg := "Hello, I help you send coding challenges to candidates and book reviewers for them. Type /challenge help or /reviewer help to see what I can do."
+	return ctx.postMessage(e.Channel, toMsgOption(msg))
+}
+
+// handleFileShared imports the calendar files that reviewers share in a DM with the app
+func handleFileShared(ctx commCtx, event json.RawMessage) error {
+	var e fileSharedEvent
+	err := json.Unmarshal(event, &e)
+	if err != nil {
+		return err
+	}
+	// Only the DMs, the files shared in channels are not for the app
+	if !strings.HasPrefix(e.ChannelID, "D") {
+		return nil
+	}
+	if _, err := models.GetReviewerBySlackID(ctx.Env, e.UserID); err != nil {
+		msg := "Only reviewers can import their calendar. Please register first using /reviewer new command."
+		return ctx.postMessage(e.ChannelID, toMsgOption(msg))
+	}
+	return ctx.postMessage(e.ChannelID, importCalendar(ctx, e.UserID, e.FileID, defaultImportWeeks, e.UserID))
+}
+
+func handleAppUninstalled(ctx commCtx, event json.RawMessage) error {
+	log.Println("[INFO] App is uninstalled from the team - ", ctx.TeamID)
+	return models.DeactivateSlackTeam(ctx.Env, ctx.TeamID)
+}
+
+// handleTokensRevoked purges the revoked tokens, the team is inactive without its bot token
+func handleTokensRevoked(ctx commCtx, event json.RawMessage) error {
+	var e tokensRevokedEvent
+	err := json.Unmarshal(event, &e)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range e.Tokens.OAuth {
+		err = models.RevokeSlackUserToken(ctx.Env, userID)
+		if err != nil {
+			log.Println("[ERROR] Cannot purge the token of the user - ", userID, err)
+		}
+	}
+	if len(e.Tokens.Bot) > 0 {
+		log.Println("[INFO] Bot token of the team is revoked - ", ctx.TeamID)
+		return models.DeactivateSlackTeam(ctx.Env, ctx.TeamID)
+	}
+	return nil
+}
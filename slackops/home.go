@@ -0,0 +1,126 @@
+package slackops
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/scheduling"
+	"github.com/nlopes/slack"
+)
+
+// homeCallbackID is the callback ID of the Home tab, the block actions in it publish the Home tab again
+const homeCallbackID = "home"
+
+// Most blocks a Home tab can have
+const maxHomeBlocks = 100
+
+// Most candidates a coordinator sees in their Home tab
+const maxHomeCandidates = 10
+
+// publishHome publishes the Home tab of the user, with their bookings, this week's availability, the reviews waiting
+// for them and the candidates they sent
+func (c commCtx) publishHome(userID string) error {
+	blocks := c.homeBlocks(userID, time.Now())
+	if len(blocks) > maxHomeBlocks {
+		blocks = blocks[:maxHomeBlocks]
+	}
+	viewBlocks := make([]interface{}, 0, len(blocks))
+	for _, block := range blocks {
+		viewBlocks = append(viewBlocks, block)
+	}
+
+	request := struct {
+		UserID string `json:"user_id"`
+		View   view   `json:"view"`
+	}{
+		UserID: userID,
+		View: view{
+			Type:       "home",
+			CallbackID: homeCallbackID,
+			Blocks:     viewBlocks,
+		},
+	}
+	err := c.callAPI("views.publish", request)
+	if err != nil {
+		log.Println("[ERROR] Cannot publish the Home tab - ", err)
+	}
+	return err
+}
+
+func (c commCtx) homeBlocks(userID string, now time.Time) []slack.Block {
+	loc := c.getUserLocation(userID)
+	blocks := make([]slack.Block, 0, maxHomeBlocks)
+
+	candidates, err := models.GetAllCandidates(c.Env)
+	if err != nil {
+		log.Println("[ERROR] Cannot load the candidates - ", err)
+	}
+	reviews := make([]models.Candidate, 0)
+	sent := make([]models.Candidate, 0)
+	for _, candidate := range candidates {
+		if candidate.IsReviewer(userID) && candidate.IsReviewPending() {
+			reviews = append(reviews, candidate)
+		}
+		if candidate.SentBy == userID && candidate.InFlight() {
+			sent = append(sent, candidate)
+		}
+	}
+	challengeNames := make(map[string]string)
+	for _, option := range challengeOptions(c.Env, nil) {
+		challengeNames[option.Value] = option.Label
+	}
+
+	reviewer, err := models.GetReviewerBySlackID(c.Env, userID)
+	if err == nil {
+		blocks = append(blocks, renderPendingReviews(reviews, challengeNames, loc)...)
+		blocks = append(blocks, c.reviewerHomeBlocks(reviewer, now, loc)...)
+	}
+	if len(sent) > 0 {
+		sort.Slice(sent, func(i, j int) bool { return sent[i].CreatedAt.After(sent[j].CreatedAt) })
+		if len(sent) > maxHomeCandidates {
+			sent = sent[:maxHomeCandidates]
+		}
+		blocks = append(blocks, slack.NewDividerBlock())
+		blocks = append(blocks, renderCandidatesInFlight(sent, challengeNames, loc)...)
+	}
+
+	if len(blocks) == 0 {
+		introText := "Register as a reviewer with /reviewer new, or send a coding challenge to a candidate with /challenge send. Your bookings, schedule and candidates will show up here."
+		blocks = append(blocks, slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", introText, false, false), nil, nil))
+	}
+	return blocks
+}
+
+// reviewerHomeBlocks has the reviewer's availability this week and their upcoming bookings, for each of their challenges
+func (c commCtx) reviewerHomeBlocks(reviewer models.Reviewer, now time.Time, loc *time.Location) []slack.Block {
+	blocks := make([]slack.Block, 0, maxHomeBlocks)
+	weekStart := scheduling.FirstDayOfWeek(now)
+	for _, challengeID := range reviewer.ChallengeIDs() {
+		challenge, err := models.GetChallengeSetupByID(c.Env, challengeID)
+		if err != nil {
+			log.Println("[ERROR] Invalid challenge for reviewer", err)
+			continue
+		}
+		member, _ := reviewer.ForChallenge(challengeID)
+
+		headerText := fmt.Sprintf("*Your %s availability this week* (%s)", challenge.Name, scheduling.WeekDescription(weekStart))
+		blocks = append(blocks, slack.NewDividerBlock(),
+			slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", headerText, false, false), nil, nil))
+		slots := scheduling.SlotsForWeek(weekStart, member, challenge)
+		scheduleBlock := renderSchedule(weekStart, member, slots, loc)
+		// Block IDs are unique in a view
+		scheduleBlock.BlockID = fmt.Sprintf("interview_slots_%s", challenge.ID)
+		blocks = append(blocks, &scheduleBlock)
+
+		blocks = append(blocks, renderBookings(member, challenge, loc)...)
+	}
+	return blocks
+}
+
+// fromHome checks if the block action is from the Home tab
+func (r request) fromHome() bool {
+	return r.icb.CallbackID == homeCallbackID
+}
@@ -0,0 +1,361 @@
+package slackops
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/nlopes/slack"
+)
+
+// Interactions of the modals
+const (
+	viewSubmission  slack.InteractionType = "view_submission"
+	blockSuggestion slack.InteractionType = "block_suggestion"
+)
+
+type inputKind = string
+
+const (
+	textInput      inputKind = "plain_text_input"
+	staticSelect   inputKind = "static_select"
+	externalSelect inputKind = "external_select"
+	usersSelect    inputKind = "users_select"
+	// multiStaticSelect submits the picked values separated by commas
+	multiStaticSelect inputKind = "multi_static_select"
+)
+
+// modal is a form shown as a Block Kit modal. On submission the values are keyed by the names of the inputs.
+type modal struct {
+	CallbackID  string
+	Title       string
+	SubmitLabel string
+	// State is passed back on submission, along with the channel the modal was opened from
+	State    string
+	Elements []*modalInput
+}
+
+type modalInput struct {
+	Kind      inputKind
+	Name      string
+	Label     string
+	Value     string
+	Hint      string
+	Optional  bool
+	Multiline bool
+	Options   []option
+	// SelectedLabel is shown for the Value of an external select, as its options are not known upfront
+	SelectedLabel string
+	// Values are the picked values of a multi select, its options can be in groups instead
+	Values       []string
+	OptionGroups []optionGroup
+}
+
+// optionGroup is a labelled group of the options of a select
+type optionGroup struct {
+	Label   string
+	Options []option
+}
+
+// modalMetadata is the private metadata of the modal views
+type modalMetadata struct {
+	Channel string `json:"channel"`
+	State   string `json:"state,omitempty"`
+	// Values are the submitted values of the previous steps of a multi-step flow
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// modalResponse is the response to a view submission, see https://api.slack.com/surfaces/modals/using#responding_to_view_submissions
+type modalResponse struct {
+	ResponseAction string            `json:"response_action"`
+	Errors         map[string]string `json:"errors,omitempty"`
+	View           *view             `json:"view,omitempty"`
+}
+
+// inputErrors are shown next to the inputs of the modal, keyed by the input names, so they can be fixed in place
+type inputErrors map[string]string
+
+func (e inputErrors) Error() string {
+	errs := make([]string, 0, len(e))
+	for name, msg := range e {
+		errs = append(errs, name+": "+msg)
+	}
+	return "[ERROR] Invalid input - " + strings.Join(errs, ", ")
+}
+
+// inputErrorsOf shows the input the models found invalid next to it, other errors are kept
+func inputErrorsOf(err error) error {
+	if inputErr, ok := err.(models.InputError); ok {
+		return inputErrors{inputErr.Input: inputErr.Message}
+	}
+	return err
+}
+
+type view struct {
+	Type            string                 `json:"type"`
+	CallbackID      string                 `json:"callback_id"`
+	Title           *slack.TextBlockObject `json:"title,omitempty"`
+	Submit          *slack.TextBlockObject `json:"submit,omitempty"`
+	Close           *slack.TextBlockObject `json:"close,omitempty"`
+	Blocks          []interface{}          `json:"blocks"`
+	PrivateMetadata string                 `json:"private_metadata,omitempty"`
+}
+
+type inputBlock struct {
+	Type     string                 `json:"type"`
+	BlockID  string                 `json:"block_id"`
+	Label    *slack.TextBlockObject `json:"label"`
+	Element  interface{}            `json:"element"`
+	Hint     *slack.TextBlockObject `json:"hint,omitempty"`
+	Optional bool                   `json:"optional"`
+}
+
+type textInputElement struct {
+	Type         string `json:"type"`
+	ActionID     string `json:"action_id"`
+	InitialValue string `json:"initial_value,omitempty"`
+	Multiline    bool   `json:"multiline,omitempty"`
+}
+
+type selectElement struct {
+	Type           string                     `json:"type"`
+	ActionID       string                     `json:"action_id"`
+	Placeholder    *slack.TextBlockObject     `json:"placeholder"`
+	Options        []*slack.OptionBlockObject `json:"options,omitempty"`
+	InitialOption  *slack.OptionBlockObject   `json:"initial_option,omitempty"`
+	InitialUser    string                     `json:"initial_user,omitempty"`
+	MinQueryLength *int                       `json:"min_query_length,omitempty"`
+	// Multi selects
+	OptionGroups   []*slack.OptionGroupBlockObject `json:"option_groups,omitempty"`
+	InitialOptions []*slack.OptionBlockObject      `json:"initial_options,omitempty"`
+}
+
+func newTextInput(name, label, value string) *modalInput {
+	return &modalInput{
+		Kind:  textInput,
+		Name:  name,
+		Label: label,
+		Value: value,
+	}
+}
+
+func newTextAreaInput(name, label, value string) *modalInput {
+	return &modalInput{
+		Kind:      textInput,
+		Name:      name,
+		Label:     label,
+		Value:     value,
+		Multiline: true,
+	}
+}
+
+func newExternalSelectInput(name, label, value string, optional bool) *modalInput {
+	return &modalInput{
+		Kind:     externalSelect,
+		Name:     name,
+		Label:    label,
+		Value:    value,
+		Optional: optional,
+	}
+}
+
+func newStaticSelectInput(name, label, value string, optional bool, options []option) *modalInput {
+	return &modalInput{
+		Kind:     staticSelect,
+		Name:     name,
+		Label:    label,
+		Value:    value,
+		Optional: optional,
+		Options:  options,
+	}
+}
+
+func newMultiSelectInput(name, label string, values []string, groups []optionGroup) *modalInput {
+	return &modalInput{
+		Kind:         multiStaticSelect,
+		Name:         name,
+		Label:        label,
+		Values:       values,
+		Optional:     true,
+		OptionGroups: groups,
+	}
+}
+
+func newUsersSelect(name, label string, optional bool) *modalInput {
+	return &modalInput{
+		Kind:     usersSelect,
+		Name:     name,
+		Label:    label,
+		Optional: optional,
+	}
+}
+
+// modalView renders the modal, the channel and the state are kept in the private metadata of the view
+func modalView(m modal, metadata modalMetadata) view {
+	metadata.State = m.State
+	js, err := json.Marshal(metadata)
+	if err != nil {
+		log.Println("[ERROR] Cannot encode the modal metadata - ", err)
+	}
+
+	blocks := make([]interface{}, 0, len(m.Elements))
+	for _, input := range m.Elements {
+		blocks = append(blocks, input.block())
+	}
+	return view{
+		Type:            "modal",
+		CallbackID:      m.CallbackID,
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, m.Title, false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, m.SubmitLabel, false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		Blocks:          blocks,
+		PrivateMetadata: string(js),
+	}
+}
+
+func (i modalInput) block() inputBlock {
+	block := inputBlock{
+		Type:     "input",
+		BlockID:  i.Name,
+		Label:    slack.NewTextBlockObject(slack.PlainTextType, i.Label, false, false),
+		Optional: i.Optional,
+	}
+	if i.Hint != "" {
+		block.Hint = slack.NewTextBlockObject(slack.PlainTextType, i.Hint, false, false)
+	}
+
+	switch i.Kind {
+	case textInput:
+		block.Element = textInputElement{
+			Type:         textInput,
+			ActionID:     i.Name,
+			InitialValue: i.Value,
+			Multiline:    i.Multiline,
+		}
+	default:
+		element := selectElement{
+			Type:        i.Kind,
+			ActionID:    i.Name,
+			Placeholder: slack.NewTextBlockObject(slack.PlainTextType, "Select an item", false, false),
+		}
+		switch i.Kind {
+		case staticSelect:
+			element.Options = optionBlocks(i.Options)
+			for _, o := range element.Options {
+				if o.Value == i.Value {
+					element.InitialOption = o
+				}
+			}
+		case externalSelect:
+			minQueryLength := 0
+			element.MinQueryLength = &minQueryLength
+			if i.Value != "" && i.SelectedLabel != "" {
+				element.InitialOption = optionBlock(option{Label: i.SelectedLabel, Value: i.Value})
+			}
+		case usersSelect:
+			element.InitialUser = i.Value
+		case multiStaticSelect:
+			picked := make(map[string]bool, len(i.Values))
+			for _, value := range i.Values {
+				picked[value] = true
+			}
+			for _, group := range i.OptionGroups {
+				options := optionBlocks(group.Options)
+				for _, o := range options {
+					if picked[o.Value] {
+						element.InitialOptions = append(element.InitialOptions, o)
+					}
+				}
+				label := slack.NewTextBlockObject(slack.PlainTextType, group.Label, false, false)
+				element.OptionGroups = append(element.OptionGroups, slack.NewOptionGroupBlockElement(label, options...))
+			}
+		}
+		block.Element = element
+	}
+	return block
+}
+
+func optionBlocks(options []option) []*slack.OptionBlockObject {
+	blocks := make([]*slack.OptionBlockObject, 0, len(options))
+	for _, o := range options {
+		blocks = append(blocks, optionBlock(o))
+	}
+	return blocks
+}
+
+func optionBlock(o option) *slack.OptionBlockObject {
+	return slack.NewOptionBlockObject(o.Value, slack.NewTextBlockObject(slack.PlainTextType, o.Label, false, false))
+}
+
+// viewPayload has the view fields of the view_submission and block_suggestion payloads, that slack.InteractionCallback
+// does not have
+type viewPayload struct {
+	View struct {
+		ID              string `json:"id"`
+		Type            string `json:"type"`
+		CallbackID      string `json:"callback_id"`
+		PrivateMetadata string `json:"private_metadata"`
+		State           struct {
+			Values map[string]map[string]viewValue `json:"values"`
+		} `json:"state"`
+	} `json:"view"`
+	// The input of a block_suggestion and what the user typed
+	ActionID string `json:"action_id"`
+	Value    string `json:"value"`
+}
+
+type viewValue struct {
+	Type           string                   `json:"type"`
+	Value          string                   `json:"value"`
+	SelectedOption *slack.OptionBlockObject `json:"selected_option"`
+	SelectedUser   string                   `json:"selected_user"`
+	// Multi selects
+	SelectedOptions []*slack.OptionBlockObject `json:"selected_options"`
+}
+
+func parseViewPayload(payload string) (viewPayload, modalMetadata, error) {
+	var vp viewPayload
+	err := json.Unmarshal([]byte(payload), &vp)
+	if err != nil {
+		log.Println("[ERROR] Unable to unmarshall the view ", err)
+		return vp, modalMetadata{}, err
+	}
+
+	var metadata modalMetadata
+	if vp.View.PrivateMetadata != "" {
+		err = json.Unmarshal([]byte(vp.View.PrivateMetadata), &metadata)
+		if err != nil {
+			log.Println("[ERROR] Unable to unmarshall the view metadata ", err)
+			return vp, metadata, err
+		}
+	}
+	return vp, metadata, nil
+}
+
+// submission returns the submitted values of the modal and its previous steps, keyed by the input names
+func (vp viewPayload) submission(metadata modalMetadata) map[string]string {
+	submission := make(map[string]string)
+	for name, value := range metadata.Values {
+		submission[name] = value
+	}
+	for blockID, actions := range vp.View.State.Values {
+		for _, value := range actions {
+			switch {
+			case value.Type == multiStaticSelect:
+				values := make([]string, 0, len(value.SelectedOptions))
+				for _, o := range value.SelectedOptions {
+					values = append(values, o.Value)
+				}
+				submission[blockID] = strings.Join(values, ",")
+			case value.SelectedOption != nil:
+				submission[blockID] = value.SelectedOption.Value
+			case value.SelectedUser != "":
+				submission[blockID] = value.SelectedUser
+			default:
+				submission[blockID] = value.Value
+			}
+		}
+	}
+	return submission
+}
@@ -0,0 +1,17 @@
+package slackops
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInputErrorsOf(t *testing.T) {
+	err := inputErrorsOf(models.InputError{Input: "count", Message: "Pick how many reviewers are needed."})
+	assert.Equal(t, inputErrors{"count": "Pick how many reviewers are needed."}, err)
+
+	other := errors.New("[ERROR] Cannot save")
+	assert.Equal(t, other, inputErrorsOf(other))
+}
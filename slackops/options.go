@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"log"
+	"strings"
 
 	"github.com/keremk/challenge-bot/config"
 	"github.com/keremk/challenge-bot/models"
@@ -16,63 +17,76 @@ type option struct {
 	Value string `json:"value,omitempty"`
 }
 
+// options are the response to a block_suggestion, see https://api.slack.com/reference/block-kit/block-elements#external_select
 type options struct {
-	Options []option `json:"options,omitempty"`
+	Options []*slack.OptionBlockObject `json:"options"`
 }
 
+// HandleOptions returns the options of the external selects of the modals, matching what the user typed
 func HandleOptions(env config.Environment, readCloser io.ReadCloser) ([]byte, error) {
-	icb, err := parseInteractionCallback(readCloser, env.VerificationToken)
+	icb, err := parseInteractionCallback(readCloser)
 	if err != nil {
 		return nil, err
 	}
 
-	// log.Println("[INFO] ICB state is - ", icb.State)
+	var optionList []option
 	switch icb.CallbackID {
 	case "send_challenge":
-		respJSON, err := handleSendChallengeOptions(env, icb)
-		return respJSON, err
-	case "edit_challenge":
 		fallthrough
-	case "new_challenge":
-		respJSON, err := handleNewChallengeOptions(env, icb)
-		return respJSON, err
+	case "new_need":
+		fallthrough
 	case "edit_reviewer":
 		fallthrough
 	case "new_reviewer":
-		respJSON, err := handleNewReviewerOptions(env, icb)
-		return respJSON, err
+		fallthrough
+	case "assign_reviewers":
+		fallthrough
 	case "find_reviewers":
-		respJSON, err := handleNewReviewerOptions(env, icb)
-		return respJSON, err
+		optionList, err = handleChallengeOptions(env, icb)
+	case "edit_challenge":
+		fallthrough
+	case "new_challenge":
+		optionList, err = handleNewChallengeOptions(env, icb)
 	default:
 		err = errors.New("[ERROR] Unknown Callback ID for options")
 		log.Println("[ERROR] Unknown Callback ID for options - ", icb.CallbackID)
 	}
-	return nil, err
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(options{
+		Options: optionBlocks(matchingOptions(optionList, icb.Value)),
+	})
 }
 
-func handleSendChallengeOptions(env config.Environment, icb *slack.InteractionCallback) ([]byte, error) {
-	switch icb.Name {
-	case "challenge_id":
-		js, err := getChallengeList(env)
-		if err != nil {
-			return nil, err
+// matchingOptions are the options with the typed text in their labels, at most the number a select can show
+func matchingOptions(optionList []option, typed string) []option {
+	typed = strings.ToLower(strings.TrimSpace(typed))
+	matching := make([]option, 0, len(optionList))
+	for _, o := range optionList {
+		if len(matching) == maxSelectOptions {
+			break
 		}
-		return js, nil
-	case "reviewer1_id":
-		fallthrough
-	case "reviewer2_id":
-		js, err := getReviewerList(env, icb.State)
-		if err != nil {
-			return nil, err
+		if strings.Contains(strings.ToLower(o.Label), typed) {
+			matching = append(matching, o)
 		}
-		return js, nil
+	}
+	return matching
+}
+
+func handleChallengeOptions(env config.Environment, icb *slack.InteractionCallback) ([]option, error) {
+	switch icb.Name {
+	case "challenge_name":
+		fallthrough
+	case "challenge_id":
+		return getChallengeList(env)
 	default:
 		return nil, nil
 	}
 }
 
-func getChallengeList(env config.Environment) ([]byte, error) {
+func getChallengeList(env config.Environment) ([]option, error) {
 	challengeList, err := models.GetAllChallenges(env)
 	if err != nil {
 		return nil, err
@@ -84,68 +98,19 @@ func getChallengeList(env config.Environment) ([]byte, error) {
 			Value: challenge.ID,
 		})
 	}
-
-	options := options{
-		Options: optionList,
-	}
-
-	js, err := json.Marshal(options)
-	if err != nil {
-		return nil, err
-	}
-	return js, nil
+	return optionList, nil
 }
 
-func getReviewerList(env config.Environment, challengeName string) ([]byte, error) {
-	log.Println("[INFO] Challenge Name is ", challengeName)
-	var reviewerList []models.Reviewer
-	var err error
-	if challengeName == "" {
-		reviewerList, err = models.GetAllReviewers(env)
-	} else {
-		challenge, err := models.GetChallengeSetupByName(env, challengeName)
-		if err != nil {
-			log.Println("[ERROR]Challenge not found - ", challengeName)
-			return nil, err
-		}
-		reviewerList, err = models.GetAllReviewersForChallenge(env, challenge.ID)
-	}
-	if err != nil {
-		return nil, err
-	}
-	optionList := make([]option, 0, len(reviewerList))
-	for _, reviewer := range reviewerList {
-		optionList = append(optionList, option{
-			Label: reviewer.Name,
-			Value: reviewer.SlackID,
-		})
-	}
-
-	options := options{
-		Options: optionList,
-	}
-
-	js, err := json.Marshal(options)
-	if err != nil {
-		return nil, err
-	}
-	return js, nil
-}
-
-func handleNewChallengeOptions(env config.Environment, icb *slack.InteractionCallback) ([]byte, error) {
+func handleNewChallengeOptions(env config.Environment, icb *slack.InteractionCallback) ([]option, error) {
 	switch icb.Name {
 	case "github_account":
-		js, err := getAccountsList(env)
-		if err != nil {
-			return nil, err
-		}
-		return js, nil
+		return getAccountsList(env)
 	default:
 		return nil, nil
 	}
 }
 
-func getAccountsList(env config.Environment) ([]byte, error) {
+func getAccountsList(env config.Environment) ([]option, error) {
 	accountList, err := models.GetAllAccounts(env)
 	if err != nil {
 		return nil, err
@@ -157,29 +122,5 @@ func getAccountsList(env config.Environment) ([]byte, error) {
 			Value: account.Name,
 		})
 	}
-
-	options := options{
-		Options: optionList,
-	}
-
-	js, err := json.Marshal(options)
-	if err != nil {
-		return nil, err
-	}
-	return js, nil
-}
-
-func handleNewReviewerOptions(env config.Environment, icb *slack.InteractionCallback) ([]byte, error) {
-	switch icb.Name {
-	case "challenge_name":
-		fallthrough
-	case "challenge_id":
-		js, err := getChallengeList(env)
-		if err != nil {
-			return nil, err
-		}
-		return js, nil
-	default:
-		return nil, nil
-	}
+	return optionList, nil
 }
@@ -0,0 +1,45 @@
+package slackops
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/models"
+)
+
+// NotifyPairing DMs the reviewers and the coordinator about the live pairing session the candidate picked
+func NotifyPairing(env config.Environment, challenge models.ChallengeSetup, candidate models.Candidate, reviewers []models.Reviewer) {
+	ctx := newCommCtx(env, candidate.SentBy, challenge.CreatedByTeamID, false)
+
+	for _, reviewer := range reviewers {
+		loc := models.LoadLocation(reviewer.TimeZone)
+		msg := fmt.Sprintf("%s picked a live pairing session with you: %s. The challenge is at %s",
+			candidate.Name, renderOccurrence(challenge, candidate.Pairing, loc), candidate.ChallengeURL)
+		err := ctx.postMessage(reviewer.SlackID, toMsgOption(msg))
+		if err != nil {
+			log.Println("[ERROR] Cannot tell the reviewer about the pairing session - ", reviewer.Name, err)
+		}
+	}
+
+	if candidate.SentBy == "" {
+		return
+	}
+	msg := fmt.Sprintf("%s picked a live pairing session: %s, with %s.",
+		candidate.Name, renderOccurrence(challenge, candidate.Pairing, ctx.getUserLocation(candidate.SentBy)), reviewerMentions(reviewers))
+	err := ctx.postMessage(candidate.SentBy, toMsgOption(msg))
+	if err != nil {
+		log.Println("[ERROR] Cannot tell the coordinator about the pairing session - ", err)
+	}
+}
+
+func reviewerMentions(reviewers []models.Reviewer) string {
+	mentions := ""
+	for i, reviewer := range reviewers {
+		if i > 0 {
+			mentions += " and "
+		}
+		mentions += fmt.Sprintf("<@%s>", reviewer.SlackID)
+	}
+	return mentions
+}
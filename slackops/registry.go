@@ -0,0 +1,321 @@
+package slackops
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+)
+
+// argKind is the type of the value of an argument or a flag
+type argKind int
+
+const (
+	// textArg is a word or a quoted text. The last argument of a command takes the rest of the text if it is a textArg.
+	textArg argKind = iota
+	// mentionArg is a user mention, its value is the Slack ID of the user
+	mentionArg
+	intArg
+	// dateArg is a date like 2006-01-02
+	dateArg
+	// choiceArg is one of the Choices, in lower case
+	choiceArg
+)
+
+// commandGroup is a slash command with its subcommands
+type commandGroup struct {
+	Name string
+	// Aliases are the other slash commands of the group, e.g. the ones of the test Slack app
+	Aliases  []string
+	Commands []commandSpec
+}
+
+// commandSpec declares a subcommand with its arguments and flags, the usage and the help are generated from it
+type commandSpec struct {
+	Name        string
+	Args        []argSpec
+	Flags       []flagSpec
+	Description string
+	Run         func(c command) error
+}
+
+type argSpec struct {
+	// Name is shown in the usage, e.g. CHALLENGENAME
+	Name     string
+	Kind     argKind
+	Optional bool
+	Choices  []string
+}
+
+// flagSpec is an optional --name VALUE, or --name=VALUE, anywhere after the subcommand
+type flagSpec struct {
+	Name  string
+	Value argSpec
+}
+
+// usageError explains why the arguments of a command are not valid
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// Slack clients turn the double quotes into smart quotes
+const quotes = "\"“”"
+
+var mentionRegexp = regexp.MustCompile(`^<@([UW][A-Z0-9]+)(\|[^>]*)?>$`)
+var slackIDRegexp = regexp.MustCompile(`^[UW][A-Z0-9]{2,}$`)
+
+// shareFlag can be added to any command, to post its reply in the channel instead of only to the user
+const shareFlag = "--share"
+
+// takeShareFlag removes the share flag from the words, and tells whether it was there
+func takeShareFlag(words []string) ([]string, bool) {
+	rest := make([]string, 0, len(words))
+	share := false
+	for _, word := range words {
+		if strings.EqualFold(word, shareFlag) {
+			share = true
+			continue
+		}
+		rest = append(rest, word)
+	}
+	return rest, share
+}
+
+func (g commandGroup) isNamed(name string) bool {
+	if g.Name == name {
+		return true
+	}
+	for _, alias := range g.Aliases {
+		if alias == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (g commandGroup) find(name string) (commandSpec, bool) {
+	for _, spec := range g.Commands {
+		if spec.Name == name {
+			return spec, true
+		}
+	}
+	return commandSpec{}, false
+}
+
+// suggest returns the subcommand that was most likely meant, empty if none is close enough
+func (g commandGroup) suggest(name string) string {
+	if name == "" {
+		return ""
+	}
+	var prefixed []string
+	for _, spec := range g.Commands {
+		if strings.HasPrefix(spec.Name, name) {
+			prefixed = append(prefixed, spec.Name)
+		}
+	}
+	if len(prefixed) == 1 {
+		return prefixed[0]
+	}
+
+	suggestion := ""
+	best := 3 // At most 2 edits away
+	for _, spec := range g.Commands {
+		if d := editDistance(name, spec.Name); d < best {
+			best = d
+			suggestion = spec.Name
+		}
+	}
+	return suggestion
+}
+
+// usage is the subcommand with its arguments and flags, the optional ones in brackets
+func (s commandSpec) usage(command string) string {
+	words := []string{command, s.Name}
+	for _, arg := range s.Args {
+		words = append(words, arg.usage())
+	}
+	for _, flag := range s.Flags {
+		words = append(words, fmt.Sprintf("[--%s %s]", flag.Name, flag.Value.usage()))
+	}
+	return strings.Join(words, " ")
+}
+
+func (a argSpec) usage() string {
+	var u string
+	switch a.Kind {
+	case mentionArg:
+		u = "@" + a.Name
+	case choiceArg:
+		u = strings.Join(a.Choices, "|")
+	default:
+		u = a.Name
+	}
+	if a.Optional {
+		return "[" + u + "]"
+	}
+	return u
+}
+
+// parse returns the values of the arguments and the flags by their names
+func (s commandSpec) parse(words []string) (map[string]string, map[string]string, error) {
+	args := make(map[string]string)
+	flags := make(map[string]string)
+
+	positional := make([]string, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if !strings.HasPrefix(word, "--") {
+			positional = append(positional, word)
+			continue
+		}
+
+		name := strings.TrimPrefix(word, "--")
+		var value string
+		eq := strings.Index(name, "=")
+		if eq >= 0 {
+			name, value = name[:eq], name[eq+1:]
+		}
+		flag, ok := s.flag(name)
+		if !ok {
+			return nil, nil, usageError(fmt.Sprintf("There is no --%s option.", name))
+		}
+		if eq >= 0 && value == "" {
+			return nil, nil, usageError(fmt.Sprintf("--%s needs a %s.", name, flag.Value.Name))
+		}
+		if eq < 0 {
+			if i+1 == len(words) {
+				return nil, nil, usageError(fmt.Sprintf("--%s needs a %s.", name, flag.Value.Name))
+			}
+			i++
+			value = words[i]
+		}
+		v, err := flag.Value.parse(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		flags[name] = v
+	}
+
+	for i, arg := range s.Args {
+		if i >= len(positional) {
+			if !arg.Optional {
+				return nil, nil, usageError(fmt.Sprintf("%s is missing.", arg.Name))
+			}
+			continue
+		}
+		value := positional[i]
+		if i == len(s.Args)-1 && arg.Kind == textArg {
+			value = strings.Join(positional[i:], " ")
+		}
+		v, err := arg.parse(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		args[arg.Name] = v
+	}
+	if len(positional) > len(s.Args) && (len(s.Args) == 0 || s.Args[len(s.Args)-1].Kind != textArg) {
+		return nil, nil, usageError(fmt.Sprintf("There are too many arguments, %s is not expected.", positional[len(s.Args)]))
+	}
+	return args, flags, nil
+}
+
+func (s commandSpec) flag(name string) (flagSpec, bool) {
+	for _, flag := range s.Flags {
+		if flag.Name == name {
+			return flag, true
+		}
+	}
+	return flagSpec{}, false
+}
+
+func (a argSpec) parse(value string) (string, error) {
+	switch a.Kind {
+	case mentionArg:
+		if m := mentionRegexp.FindStringSubmatch(value); m != nil {
+			return m[1], nil
+		}
+		if slackIDRegexp.MatchString(value) {
+			return value, nil
+		}
+		return "", usageError(fmt.Sprintf("%s needs to be a user mention like @name, %s is not one.", a.Name, value))
+	case intArg:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", usageError(fmt.Sprintf("%s needs to be a number, %s is not one.", a.Name, value))
+		}
+	case dateArg:
+		if _, err := time.Parse(models.DateFormat, value); err != nil {
+			return "", usageError(fmt.Sprintf("%s needs to be a date like 2006-01-02, %s is not one.", a.Name, value))
+		}
+	case choiceArg:
+		for _, choice := range a.Choices {
+			if strings.EqualFold(choice, value) {
+				return choice, nil
+			}
+		}
+		return "", usageError(fmt.Sprintf("%s needs to be one of %s.", a.Name, strings.Join(a.Choices, ", ")))
+	}
+	return value, nil
+}
+
+// tokenize splits the text of a command into words, a text in double quotes is one word
+func tokenize(text string) ([]string, error) {
+	words := make([]string, 0)
+	var word strings.Builder
+	inWord, inQuotes := false, false
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune(quotes, r):
+			inQuotes = !inQuotes
+			inWord = true
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if inQuotes {
+		return nil, usageError("A quote is not closed.")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// editDistance is the Levenshtein distance of the words
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < cur[j] {
+				cur[j] = d
+			}
+			if d := cur[j-1] + 1; d < cur[j] {
+				cur[j] = d
+			}
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
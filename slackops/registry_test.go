@@ -0,0 +1,85 @@
+package slackops
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSpec = commandSpec{
+	Name: "assign",
+	Args: []argSpec{
+		{Name: "REVIEWER", Kind: mentionArg},
+		{Name: "CANDIDATE", Kind: textArg},
+	},
+	Flags: []flagSpec{
+		{Name: "weeks", Value: argSpec{Name: "WEEKS", Kind: intArg}},
+		{Name: "name", Value: argSpec{Name: "NAME", Kind: textArg}},
+	},
+}
+
+func TestTokenize(t *testing.T) {
+	words, err := tokenize(`assign <@U123> "Jane Doe"  --name “Ann Lee”`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"assign", "<@U123>", "Jane Doe", "--name", "Ann Lee"}, words)
+
+	words, err = tokenize(`--name="" next`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"--name=", "next"}, words)
+
+	_, err = tokenize(`assign "Jane Doe`)
+	assert.NotNil(t, err)
+}
+
+func TestParse(t *testing.T) {
+	args, flags, err := testSpec.parse([]string{"<@U123|jane>", "Jane", "Doe", "--weeks", "3", "--name=Ann"})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"REVIEWER": "U123", "CANDIDATE": "Jane Doe"}, args)
+	assert.Equal(t, map[string]string{"weeks": "3", "name": "Ann"}, flags)
+
+	tests := []struct {
+		name  string
+		words []string
+	}{
+		{"unknown flag", []string{"U123", "Jane", "--days", "3"}},
+		{"missing required arg", []string{"U123"}},
+		{"invalid mention", []string{"jane", "Jane"}},
+		{"flag without value", []string{"U123", "Jane", "--weeks"}},
+		{"flag with invalid value", []string{"U123", "Jane", "--weeks", "many"}},
+		{"empty flag value", []string{"U123", "--name=", "Jane"}},
+	}
+	for _, test := range tests {
+		_, _, err := testSpec.parse(test.words)
+		_, ok := err.(usageError)
+		assert.True(t, ok, test.name)
+	}
+}
+
+func TestParseTooManyArgs(t *testing.T) {
+	spec := commandSpec{Name: "show", Args: []argSpec{{Name: "CHALLENGENAME", Kind: choiceArg, Choices: []string{"ios", "android"}}}}
+
+	args, _, err := spec.parse([]string{"IOS"})
+	assert.Nil(t, err)
+	assert.Equal(t, "ios", args["CHALLENGENAME"])
+
+	_, _, err = spec.parse([]string{"ios", "android"})
+	assert.NotNil(t, err)
+}
+
+func TestSuggest(t *testing.T) {
+	group := commandGroup{Commands: []commandSpec{{Name: "new"}, {Name: "edit"}, {Name: "send"}, {Name: "slots"}}}
+
+	assert.Equal(t, "slots", group.suggest("sl"))
+	assert.Equal(t, "edit", group.suggest("eidt"))
+	assert.Equal(t, "send", group.suggest("snd"))
+	assert.Equal(t, "", group.suggest("s"))
+	assert.Equal(t, "", group.suggest("remove"))
+	assert.Equal(t, "", group.suggest(""))
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("edit", "edit"))
+	assert.Equal(t, 2, editDistance("eidt", "edit"))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+	assert.Equal(t, 4, editDistance("", "send"))
+}
@@ -0,0 +1,143 @@
+package slackops
+
+import (
+	"log"
+	"time"
+
+	"github.com/keremk/challenge-bot/config"
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/scheduling"
+	"github.com/nlopes/slack"
+)
+
+// SendReminders DMs the reviewers about their upcoming bookings and sends the weekly digest. A scheduler calls it
+// every few minutes, each reminder is marked as sent in the store before it goes out, so it is sent once.
+func SendReminders(env config.Environment, now time.Time) error {
+	reviewers, err := models.GetAllReviewers(env)
+	if err != nil {
+		log.Println("[ERROR] Cannot load the reviewers for reminders - ", err)
+		return err
+	}
+
+	challenges := make(map[string]models.ChallengeSetup)
+	for _, reviewer := range reviewers {
+		if reviewer.RemindersOff {
+			continue
+		}
+
+		sent := make([]scheduling.Reminder, 0)
+		digestWeek := ""
+		for _, challengeID := range reviewer.ChallengeIDs() {
+			challenge, ok := challenges[challengeID]
+			if !ok {
+				challenge, err = models.GetChallengeSetupByID(env, challengeID)
+				if err != nil {
+					log.Println("[ERROR] Invalid challenge for reviewer - ", reviewer.Name, err)
+					continue
+				}
+				challenges[challengeID] = challenge
+			}
+
+			member, err := reviewer.ForChallenge(challengeID)
+			if err != nil {
+				continue
+			}
+			reminded, digested := remindReviewer(env, member, challenge, now)
+			sent = append(sent, reminded...)
+			if digested != "" {
+				digestWeek = digested
+			}
+		}
+
+		if len(sent) == 0 && digestWeek == "" {
+			continue
+		}
+		markReminded(env, reviewer.SlackID, sent, digestWeek)
+	}
+	return nil
+}
+
+// remindReviewer sends the due reminders and digest for the challenge, and returns what was sent
+func remindReviewer(env config.Environment, reviewer models.Reviewer, challenge models.ChallengeSetup, now time.Time) ([]scheduling.Reminder, string) {
+	ctx := newCommCtx(env, reviewer.SlackID, challenge.CreatedByTeamID, false)
+	loc := models.LoadLocation(reviewer.TimeZone)
+
+	sent := make([]scheduling.Reminder, 0)
+	reminders := scheduling.DueReminders(reviewer, challenge, now)
+	for _, reminder := range reminders {
+		claimed, err := models.ClaimReminder(env, reminder.Key(), now)
+		if err != nil {
+			log.Println("[ERROR] Cannot mark the reminder as sent - ", reminder.Key(), err)
+			continue
+		}
+		if !claimed {
+			// Sent by an earlier run, only the booking is marked
+			sent = append(sent, reminder)
+			continue
+		}
+		err = ctx.postMessage(reviewer.SlackID, slack.MsgOptionBlocks(renderReminder(reminder, challenge, loc)...))
+		if err != nil {
+			log.Println("[ERROR] Cannot send the reminder to - ", reviewer.Name, err)
+			releaseReminder(env, reminder.Key())
+			continue
+		}
+		sent = append(sent, reminder)
+	}
+
+	digestWeek := ""
+	if digest, ok := scheduling.DueDigest(reviewer, challenge, now); ok {
+		key := digest.Key(reviewer.ID, challenge.ID)
+		claimed, err := models.ClaimReminder(env, key, now)
+		if err != nil {
+			log.Println("[ERROR] Cannot mark the weekly digest as sent - ", key, err)
+			return sent, ""
+		}
+		if !claimed {
+			return sent, digest.WeekStart.Format(models.DateFormat)
+		}
+		err = ctx.postMessage(reviewer.SlackID, slack.MsgOptionBlocks(renderDigest(digest, challenge, loc)...))
+		if err != nil {
+			log.Println("[ERROR] Cannot send the weekly digest to - ", reviewer.Name, err)
+			releaseReminder(env, key)
+		} else {
+			digestWeek = digest.WeekStart.Format(models.DateFormat)
+		}
+	}
+	return sent, digestWeek
+}
+
+func releaseReminder(env config.Environment, key string) {
+	err := models.ReleaseReminder(env, key)
+	if err != nil {
+		log.Println("[ERROR] Cannot unmark the reminder that was not sent - ", key, err)
+	}
+}
+
+// markReminded records the sent reminders on a fresh copy of the reviewer, so bookings made in the meantime are kept
+func markReminded(env config.Environment, slackID string, sent []scheduling.Reminder, digestWeek string) {
+	reviewer, err := models.GetReviewerBySlackID(env, slackID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find the reviewer to mark the reminders - ", slackID, err)
+		return
+	}
+
+	for _, reminder := range sent {
+		key := reminder.Booking.Occurrence.Key()
+		booking, ok := reviewer.Bookings[key]
+		if !ok || booking.ID != reminder.Booking.ID {
+			// The booking was cancelled or replaced since
+			continue
+		}
+		booking.DayReminderSent = booking.DayReminderSent || reminder.Booking.DayReminderSent
+		booking.HourReminderSent = booking.HourReminderSent || reminder.Booking.HourReminderSent
+		reviewer.Bookings[key] = booking
+	}
+	if digestWeek != "" {
+		reviewer.LastDigestWeek = digestWeek
+	}
+
+	err = models.UpdateReviewer(env, reviewer)
+	if err != nil {
+		log.Println("[ERROR] Could not update reviewer in db ", err)
+	}
+}
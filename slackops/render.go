@@ -3,7 +3,10 @@ package slackops
 import (
 	"fmt"
 	"log"
+	"path"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/keremk/challenge-bot/models"
@@ -11,28 +14,24 @@ import (
 	"github.com/nlopes/slack"
 )
 
-func renderChallengeHelp() slack.MsgOption {
-	help := `
-Hello and welcome to the coding challenge tool. You can use the following commands:
-*/challenge help* : Displays this message
-*/challenge new* : Opens a dialog to create a new challenge
-*/challenge edit CHALLENGENAME* : Edits the challenge with the name CHALLENGENAME
-*/challenge send* : Opens a dialog to send a challenge to a candidate
-`
-	return renderHelp(help)
-}
-
-func renderReviewerHelp() slack.MsgOption {
-	help := `
-Hello and welcome to the coding challenge tool. You can use the following commands:	
-*/reviewer help* : Displays this message
-*/reviewer new* : Opens a dialog to register a reviewer
-*/reviewer edit @SLACKID* : Opens a dialog to edit the reviewer you specified with SLACKID. If SLACKID is omitted, it assumes you are the reviewer
-*/reviewer find* : Opens a dialog to find reviewers and book them
-*/reviewer schedule @SLACKID* : Opens a dialog to setup a reviewer schedule for all weeks or a specific week. If SLACKID is omitted, assumes you are the reviewer
-*/reviewer bookings @SLACKID* : Shows all active bookings for the reviewer with SLACKID. If SLACKID is omitted, assumes you are the reviewer
-`
-	return renderHelp(help)
+// renderCommandHelp lists the subcommands of the slash command with their usage
+func renderCommandHelp(command string, group commandGroup) slack.MsgOption {
+	lines := make([]string, 0, len(group.Commands)+2)
+	lines = append(lines, "Hello and welcome to the coding challenge tool. You can use the following commands:")
+	for _, spec := range group.Commands {
+		lines = append(lines, fmt.Sprintf("*%s* : %s", spec.usage(command), spec.Description))
+	}
+	lines = append(lines, fmt.Sprintf("The replies are only visible to you, add *%s* to a command to post its reply in the channel.", shareFlag))
+	return renderHelp(strings.Join(lines, "\n"))
+}
+
+// renderUsage shows the usage of the subcommand, and why its arguments are not valid if err is not nil
+func renderUsage(command string, spec commandSpec, err error) slack.MsgOption {
+	text := fmt.Sprintf("Usage: *%s*\n%s", spec.usage(command), spec.Description)
+	if err != nil {
+		text = fmt.Sprintf("%s\n%s", err.Error(), text)
+	}
+	return renderHelp(text)
 }
 
 func renderHelp(text string) slack.MsgOption {
@@ -72,24 +71,137 @@ func renderChallengeSummary(candidate models.Candidate, challengeURL string, tra
 	)
 }
 
-func renderSchedule(weekNo, year int, reviewer models.Reviewer, slots []scheduling.SlotInfo) slack.ActionBlock {
+func renderChallengeSlots(challenge models.ChallengeSetup, bookingCounts map[models.SlotID]int) []slack.Block {
+	sections := make([]slack.Block, 0, 50)
+	headerText := fmt.Sprintf("*Interview slots of %s* (times in %s)", challenge.Name, challenge.TimeZone)
+	headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
+	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+
+	for _, slot := range challenge.GetSlotsInOrder() {
+		slotText := fmt.Sprintf("*%s*: %s %s - %s", slot.Name, slot.Day, slot.StartTime, slot.EndTime)
+		if slot.Duration > 0 {
+			slotText = fmt.Sprintf("%s, %d minute interviews", slotText, slot.Duration)
+		}
+		if count := bookingCounts[slot.ID]; count > 0 {
+			slotText = fmt.Sprintf("%s\n%d upcoming bookings", slotText, count)
+		}
+		slotEl := slack.NewTextBlockObject("mrkdwn", slotText, false, false)
+		sections = append(sections, slack.NewSectionBlock(slotEl, nil, nil))
+
+		upEl := slack.NewButtonBlockElement(encodeAction(editSlots, encodeSlotActionInfo(moveSlotUp, slot.ID)), challenge.ID,
+			slack.NewTextBlockObject("plain_text", "\u2191 Up", false, false))
+		downEl := slack.NewButtonBlockElement(encodeAction(editSlots, encodeSlotActionInfo(moveSlotDown, slot.ID)), challenge.ID,
+			slack.NewTextBlockObject("plain_text", "\u2193 Down", false, false))
+		editEl := slack.NewButtonBlockElement(encodeAction(editSlots, encodeSlotActionInfo(editSlot, slot.ID)), challenge.ID,
+			slack.NewTextBlockObject("plain_text", "Edit", false, false))
+		removeEl := slack.NewButtonBlockElement(encodeAction(editSlots, encodeSlotActionInfo(deleteSlot, slot.ID)), challenge.ID,
+			slack.NewTextBlockObject("plain_text", "Remove", false, false))
+		removeEl.Style = slack.StyleDanger
+		confirmText := fmt.Sprintf("Removing %s does not cancel any upcoming bookings.", slot.Name)
+		if count := bookingCounts[slot.ID]; count > 0 {
+			confirmText = fmt.Sprintf("Removing %s cancels %d upcoming bookings. The reviewers will be told.", slot.Name, count)
+		}
+		removeEl.Confirm = slack.NewConfirmationBlockObject(
+			slack.NewTextBlockObject("plain_text", "Remove Slot", false, false),
+			slack.NewTextBlockObject("plain_text", confirmText, false, false),
+			slack.NewTextBlockObject("plain_text", "Remove", false, false),
+			slack.NewTextBlockObject("plain_text", "Keep", false, false))
+		sections = append(sections, newActionBlock(fmt.Sprintf("slot_%s", slot.ID), []slack.BlockElement{upEl, downEl, editEl, removeEl}))
+	}
+
+	addEl := slack.NewButtonBlockElement(encodeAction(editSlots, encodeSlotActionInfo(addSlot, "")), challenge.ID,
+		slack.NewTextBlockObject("plain_text", "Add Slot", false, false))
+	addEl.Style = slack.StylePrimary
+	sections = append(sections, newActionBlock("add_slot", []slack.BlockElement{addEl}))
+	return sections
+}
+
+func renderChallengeList(challenges []models.Challenge, reviewerCounts, inFlightCounts map[string]int) []slack.Block {
+	sections := make([]slack.Block, 0, len(challenges)+1)
+	headerEl := slack.NewTextBlockObject("mrkdwn", "*Challenges*", false, false)
+	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+	if len(challenges) == 0 {
+		emptyEl := slack.NewTextBlockObject("mrkdwn", "No challenges are registered yet. Create one with /challenge new.", false, false)
+		return append(sections, slack.NewSectionBlock(emptyEl, nil, nil))
+	}
+
+	for _, challenge := range challenges {
+		challengeText := fmt.Sprintf("*%s*: Github account %s, %d slots\n%d reviewers, %d candidates in flight",
+			challenge.Name, challenge.GithubAccountName, len(challenge.Slots), reviewerCounts[challenge.ID], inFlightCounts[challenge.ID])
+		challengeEl := slack.NewTextBlockObject("mrkdwn", challengeText, false, false)
+		sections = append(sections, slack.NewSectionBlock(challengeEl, nil, nil))
+	}
+	return sections
+}
+
+func renderChallengeDetails(challenge models.ChallengeSetup, reviewers []models.Reviewer, candidates []models.Candidate, loc *time.Location) []slack.Block {
+	sections := make([]slack.Block, 0, 4)
+	repo := fmt.Sprintf("%s/%s", challenge.OrgOrOwner(), challenge.TemplateRepo)
+	headerText := fmt.Sprintf("*%s*\nTemplate repository: <https://github.com/%s|%s>\nRepository names: %s\nTime zone: %s",
+		challenge.Name, repo, repo, challenge.RepoNameFormat, challenge.TimeZone)
+	sections = append(sections, slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", headerText, false, false), nil, nil))
+
+	slotLines := make([]string, 0, len(challenge.Slots))
+	for _, slot := range challenge.GetSlotsInOrder() {
+		slotLines = append(slotLines, fmt.Sprintf("%s: %s %s - %s", slot.Name, slot.Day, slot.StartTime, slot.EndTime))
+	}
+	slotsText := fmt.Sprintf("*Slots*\n%s", strings.Join(slotLines, "\n"))
+	sections = append(sections, slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", slotsText, false, false), nil, nil))
+
+	reviewerIDs := make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		reviewerIDs = append(reviewerIDs, reviewer.SlackID)
+	}
+	reviewersText := fmt.Sprintf("*Reviewers* (%d)\n%s", len(reviewers), renderMentions(reviewerIDs))
+	sections = append(sections, slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", reviewersText, false, false), nil, nil))
+
+	candidateLines := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		candidateLines = append(candidateLines, fmt.Sprintf("<%s|%s>, sent on %s", candidate.ChallengeURL, candidate.Name, candidate.CreatedAt.In(loc).Format("2 January")))
+	}
+	if len(candidateLines) == 0 {
+		candidateLines = append(candidateLines, "none")
+	}
+	candidatesText := fmt.Sprintf("*Candidates in flight* (%d)\n%s", len(candidates), strings.Join(candidateLines, "\n"))
+	sections = append(sections, slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", candidatesText, false, false), nil, nil))
+	return sections
+}
+
+func renderDeleteChallenge(challenge models.ChallengeSetup, reviewerCount int) []slack.Block {
+	questionText := fmt.Sprintf("Delete *%s*? It is removed from its %d reviewers. The repositories already sent to candidates are kept on Github.",
+		challenge.Name, reviewerCount)
+	questionEl := slack.NewTextBlockObject("mrkdwn", questionText, false, false)
+
+	deleteEl := slack.NewButtonBlockElement(encodeAction(deleteChallenge, confirmDelete), challenge.ID,
+		slack.NewTextBlockObject("plain_text", "Delete", false, false))
+	deleteEl.Style = slack.StyleDanger
+	deleteEl.Confirm = slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject("plain_text", "Delete Challenge", false, false),
+		slack.NewTextBlockObject("plain_text", fmt.Sprintf("%s cannot be sent anymore. This cannot be undone.", challenge.Name), false, false),
+		slack.NewTextBlockObject("plain_text", "Delete", false, false),
+		slack.NewTextBlockObject("plain_text", "Keep", false, false))
+	keepEl := slack.NewButtonBlockElement(encodeAction(deleteChallenge, keepChallenge), challenge.ID,
+		slack.NewTextBlockObject("plain_text", "Keep", false, false))
+
+	return []slack.Block{
+		slack.NewSectionBlock(questionEl, nil, nil),
+		newActionBlock("delete_challenge", []slack.BlockElement{deleteEl, keepEl}),
+	}
+}
+
+func renderSchedule(weekStart time.Time, reviewer models.Reviewer, slots []scheduling.SlotInfo, loc *time.Location) slack.ActionBlock {
 	// Schedule Action Blocks
 	blockEls := make([]slack.BlockElement, 0, len(slots))
 	for _, slot := range slots {
 		var buttonText string
 		if slot.IsSelected {
-			buttonText = fmt.Sprintf("\u2713 %s : %s - %s", slot.Slot.Day, slot.Slot.StartTime, slot.Slot.EndTime)
+			buttonText = fmt.Sprintf("\u2713 %s", renderSlotTime(slot.Slot, weekStart, loc))
 		} else {
-			buttonText = fmt.Sprintf("\u2717 %s : %s - %s", slot.Slot.Day, slot.Slot.StartTime, slot.Slot.EndTime)
+			buttonText = fmt.Sprintf("\u2717 %s", renderSlotTime(slot.Slot, weekStart, loc))
 		}
 		buttonTextBlock := slack.NewTextBlockObject("plain_text", buttonText, false, false)
 		encodedValue := strconv.FormatBool(slot.IsSelected)
-		encodedScheduleAction := encodeScheduleActionInfo(scheduleActionInfo{
-			SlotID:     slot.Slot.ID,
-			ReviewerID: reviewer.SlackID,
-			WeekNo:     weekNo,
-			Year:       year,
-		})
+		encodedScheduleAction := encodeScheduleActionInfo(newScheduleActionInfo(reviewer.SlackID, slot.Slot.ID, slot.Occurrence))
 		encodedID := encodeAction(scheduleUpdate, encodedScheduleAction)
 		blockEl := slack.NewButtonBlockElement(encodedID, encodedValue, buttonTextBlock)
 		blockEls = append(blockEls, blockEl)
@@ -99,40 +211,35 @@ func renderSchedule(weekNo, year int, reviewer models.Reviewer, slots []scheduli
 	return slotsBlock
 }
 
-func renderReviewers(weekNo, year int, slots map[string]*scheduling.SlotAvailability) slack.MsgOption {
+func renderReviewers(weekStart time.Time, slots map[string]*scheduling.SlotAvailability, loc *time.Location) slack.MsgOption {
 	sections := make([]slack.Block, 0, 50)
-	weekDescription, err := scheduling.WeekDescriptionFromWeekNo(weekNo, year)
-	if err != nil {
-		log.Println("[ERROR] Week number not valid", err)
-	}
+	weekDescription := scheduling.WeekDescription(weekStart)
 	headerText := fmt.Sprintf("*Interviewer List For Week:* %s ", weekDescription)
 	headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
 	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
 
-	for slotID, slotAvailability := range slots {
-		slotHeaderText := fmt.Sprintf("*Interview Slot:* %s ", slotAvailability.Slot.Name)
+	for _, slotAvailability := range slots {
+		slotHeaderText := fmt.Sprintf("*Interview Slot:* %s (%s)", slotAvailability.Slot.Name, renderSlotTime(*slotAvailability.Slot, weekStart, loc))
 		slotHeaderEl := slack.NewTextBlockObject("mrkdwn", slotHeaderText, false, false)
 		sections = append(sections, slack.NewSectionBlock(slotHeaderEl, nil, nil))
 		for _, reviewerInfo := range slotAvailability.Reviewers {
-			sections = append(sections, renderReviewer(reviewerInfo, slotID, weekNo, year))
+			sections = append(sections, renderReviewer(reviewerInfo, slotAvailability.Occurrence))
 		}
 	}
 	return slack.MsgOptionBlocks(sections...)
 }
 
-func renderReviewer(reviewerInfo scheduling.ReviewerInfo, slotID string, weekNo, year int) *slack.SectionBlock {
+func renderReviewer(reviewerInfo scheduling.ReviewerInfo, occurrence models.SlotOccurrence) *slack.SectionBlock {
 	reviewer := reviewerInfo.Reviewer
 	isBooked := reviewerInfo.IsBooked
 
 	reviewerNameText := fmt.Sprintf("*<@%s|%s>* (%s)", reviewer.SlackID, reviewer.Name, reviewer.TechnologyList)
+	if isBooked {
+		reviewerNameText = fmt.Sprintf("%s\nBooked: %s", reviewerNameText, renderBookingPurpose(reviewerInfo.Booking))
+	}
 	reviewerNameEl := slack.NewTextBlockObject("mrkdwn", reviewerNameText, false, false)
 
-	encodedScheduleAction := encodeScheduleActionInfo(scheduleActionInfo{
-		SlotID:     slotID,
-		WeekNo:     weekNo,
-		Year:       year,
-		ReviewerID: reviewer.SlackID,
-	})
+	encodedScheduleAction := encodeScheduleActionInfo(newScheduleActionInfo(reviewer.SlackID, occurrence.SlotID, occurrence))
 
 	var buttonText string
 	if isBooked {
@@ -150,55 +257,51 @@ func renderReviewer(reviewerInfo scheduling.ReviewerInfo, slotID string, weekNo,
 	return slack.NewSectionBlock(reviewerNameEl, nil, accessory)
 }
 
-func renderBookings(reviewer models.Reviewer, challenge models.ChallengeSetup) []slack.Block {
-	bookings := reviewer.Bookings
-
+func renderBookings(reviewer models.Reviewer, challenge models.ChallengeSetup, loc *time.Location) []slack.Block {
 	sections := make([]slack.Block, 0, 50)
-	reviewerNameText := fmt.Sprintf("All bookings for *<@%s>* (%s)", reviewer.SlackID, reviewer.TechnologyList)
+	reviewerNameText := fmt.Sprintf("All %s bookings for *<@%s>* (%s)", challenge.Name, reviewer.SlackID, reviewer.TechnologyList)
 	reviewerNameEl := slack.NewTextBlockObject("mrkdwn", reviewerNameText, false, false)
 	sections = append(sections, slack.NewSectionBlock(reviewerNameEl, nil, nil))
 
-	currentYear, currentWeek := time.Now().ISOWeek()
-	noBookingsFound := true
-	for weekInfo, bookingsPerWeek := range bookings {
-		if len(bookingsPerWeek) == 0 {
+	today := time.Now().Format(models.DateFormat)
+	bookings := make([]models.Booking, 0, len(reviewer.Bookings))
+	for _, booking := range reviewer.Bookings {
+		if booking.Occurrence.Date < today || !booking.IsForChallenge(challenge.ID) {
 			continue
 		}
-		weekNo, year := decodeWeekAndYear(weekInfo)
-		if weekNo < currentWeek || year < currentYear {
+		bookings = append(bookings, booking)
+	}
+	sort.Slice(bookings, func(i, j int) bool { return bookings[i].Occurrence.Key() < bookings[j].Occurrence.Key() })
+
+	var currentWeek time.Time
+	for _, booking := range bookings {
+		bookingSlot := challenge.Slots[booking.Occurrence.SlotID]
+		weekStart, err := scheduling.WeekOfOccurrence(booking.Occurrence)
+		if bookingSlot == nil || err != nil {
+			log.Println("[ERROR] Booking is not for a valid slot - ", booking.Occurrence.Key())
 			continue
 		}
-		weekDescription, err := scheduling.WeekDescriptionFromWeekNo(weekNo, year)
-		if err != nil {
-			log.Println("[ERROR] Week description is not retrieved")
-			weekDescription = fmt.Sprintf("Week # %d - ", weekNo)
-		}
-		weekDescriptionEl := slack.NewTextBlockObject("mrkdwn", weekDescription, false, false)
-		sections = append(sections, slack.NewSectionBlock(weekDescriptionEl, nil, nil))
-
-		for _, bookingKey := range bookingsPerWeek {
-			bookingSlot := challenge.Slots[bookingKey]
-			sections = append(sections, renderBooking(*bookingSlot, weekNo, year, reviewer))
+		if !weekStart.Equal(currentWeek) {
+			currentWeek = weekStart
+			weekDescriptionEl := slack.NewTextBlockObject("mrkdwn", scheduling.WeekDescription(weekStart), false, false)
+			sections = append(sections, slack.NewSectionBlock(weekDescriptionEl, nil, nil))
 		}
-		noBookingsFound = false
+		sections = append(sections, renderBooking(*bookingSlot, booking, reviewer, loc))
 	}
-	if noBookingsFound {
+	if len(bookings) == 0 {
 		noBookingsFoundEl := slack.NewTextBlockObject("mrkdwn", "No bookings found.", false, false)
 		sections = append(sections, slack.NewSectionBlock(noBookingsFoundEl, nil, nil))
 	}
 	return sections
 }
 
-func renderBooking(slot models.Slot, weekNo, year int, reviewer models.Reviewer) *slack.SectionBlock {
-	slotDescriptionText := fmt.Sprintf("*Slot*: %s", slot.Name)
+func renderBooking(slot models.Slot, booking models.Booking, reviewer models.Reviewer, loc *time.Location) *slack.SectionBlock {
+	occurrence := booking.Occurrence
+	weekStart, _ := scheduling.WeekOfOccurrence(occurrence)
+	slotDescriptionText := fmt.Sprintf("*Slot*: %s (%s)\n%s", slot.Name, renderSlotTime(slot, weekStart, loc), renderBookingDetails(booking))
 	slotDescriptionEl := slack.NewTextBlockObject("mrkdwn", slotDescriptionText, false, false)
 
-	encodedScheduleAction := encodeScheduleActionInfo(scheduleActionInfo{
-		SlotID:     slot.ID,
-		WeekNo:     weekNo,
-		Year:       year,
-		ReviewerID: reviewer.SlackID,
-	})
+	encodedScheduleAction := encodeScheduleActionInfo(newScheduleActionInfo(reviewer.SlackID, slot.ID, occurrence))
 
 	buttonTextBlock := slack.NewTextBlockObject("plain_text", "Unbook", false, false)
 	encodedID := encodeAction(showBookings, encodedScheduleAction)
@@ -210,6 +313,396 @@ func renderBooking(slot models.Slot, weekNo, year int, reviewer models.Reviewer)
 	return slack.NewSectionBlock(slotDescriptionEl, nil, accessory)
 }
 
+// renderImportDiff lists the available slots that conflict with the imported calendar, with buttons to save or cancel
+func renderImportDiff(reviewer models.Reviewer, challenge models.ChallengeSetup, busySlots []scheduling.BusySlot, loc *time.Location) []slack.Block {
+	sections := make([]slack.Block, 0, len(busySlots)+2)
+	headerText := fmt.Sprintf("These slots of *<@%s>* conflict with their calendar and will be marked as unavailable:", reviewer.SlackID)
+	headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
+	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+
+	for _, busySlot := range busySlots {
+		busyStart := busySlot.Busy.Start.In(loc)
+		busyEnd := busySlot.Busy.End.In(loc)
+		slotText := fmt.Sprintf("~%s~\nBusy %s %s - %s", renderOccurrence(challenge, busySlot.Occurrence, loc),
+			busyStart.Format("Mon Jan 2"), busyStart.Format("15:04"), busyEnd.Format("15:04 MST"))
+		if busySlot.IsBooked {
+			slotText = fmt.Sprintf("%s\n:warning: Already booked, the booking is kept.", slotText)
+		}
+		slotEl := slack.NewTextBlockObject("mrkdwn", slotText, false, false)
+		sections = append(sections, slack.NewSectionBlock(slotEl, nil, nil))
+	}
+
+	saveEl := slack.NewButtonBlockElement(encodeAction(importAvailability, encodeRuleActionInfo(reviewer.SlackID, saveImport)), saveImport,
+		slack.NewTextBlockObject("plain_text", "Save", false, false))
+	saveEl.Style = slack.StylePrimary
+	cancelEl := slack.NewButtonBlockElement(encodeAction(importAvailability, encodeRuleActionInfo(reviewer.SlackID, cancelImport)), cancelImport,
+		slack.NewTextBlockObject("plain_text", "Cancel", false, false))
+	sections = append(sections, newActionBlock("import_availability", []slack.BlockElement{saveEl, cancelEl}))
+	return sections
+}
+
+// renderCalendarLinks offers the download of the reviewer's bookings, and the feed URLs to subscribe to from a calendar app
+func renderCalendarLinks(reviewer models.Reviewer, challenge models.ChallengeSetup, reviewerURL, challengeURL string) []slack.Block {
+	linksText := fmt.Sprintf("Subscribe from your calendar app to keep the bookings up to date. Keep these links private.\n*<@%s>:* %s\n*All of %s:* %s",
+		reviewer.SlackID, reviewerURL, challenge.Name, challengeURL)
+	linksEl := slack.NewTextBlockObject("mrkdwn", linksText, false, false)
+
+	buttonEl := slack.NewButtonBlockElement(encodeAction(downloadCalendar, reviewer.SlackID), "", slack.NewTextBlockObject("plain_text", "Download .ics", false, false))
+	buttonEl.URL = reviewerURL
+	return []slack.Block{
+		slack.NewSectionBlock(linksEl, nil, slack.NewAccessory(buttonEl)),
+	}
+}
+
+func renderBookingNotice(reviewer models.Reviewer, booking models.Booking, challenge models.ChallengeSetup, isBooked bool, actorID string, loc *time.Location) []slack.Block {
+	occurrenceText := renderOccurrence(challenge, booking.Occurrence, loc)
+	if !isBooked {
+		noticeText := fmt.Sprintf("<@%s> cancelled your booking for %s: %s", actorID, occurrenceText, renderBookingPurpose(booking))
+		noticeEl := slack.NewTextBlockObject("mrkdwn", noticeText, false, false)
+		return []slack.Block{
+			slack.NewSectionBlock(noticeEl, nil, nil),
+		}
+	}
+
+	noticeText := fmt.Sprintf("<@%s> booked you for %s\n%s", actorID, occurrenceText, renderBookingDetails(booking))
+	noticeEl := slack.NewTextBlockObject("mrkdwn", noticeText, false, false)
+
+	encodedScheduleAction := encodeScheduleActionInfo(newScheduleActionInfo(reviewer.SlackID, booking.Occurrence.SlotID, booking.Occurrence))
+	buttonEl := slack.NewButtonBlockElement(encodeAction(declineBooking, encodedScheduleAction), booking.ID, slack.NewTextBlockObject("plain_text", "Decline", false, false))
+	buttonEl.Style = slack.StyleDanger
+	buttonEl.Confirm = slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject("plain_text", "Decline the booking?", false, false),
+		slack.NewTextBlockObject("plain_text", "The slot is freed and the booker is told to find another reviewer.", false, false),
+		slack.NewTextBlockObject("plain_text", "Decline", false, false),
+		slack.NewTextBlockObject("plain_text", "Keep", false, false),
+	)
+	return []slack.Block{
+		slack.NewSectionBlock(noticeEl, nil, slack.NewAccessory(buttonEl)),
+	}
+}
+
+func renderDeclinedBooking(reviewer models.Reviewer, booking models.Booking, challenge models.ChallengeSetup, alternatives []scheduling.ReviewerInfo, loc *time.Location) []slack.Block {
+	sections := make([]slack.Block, 0, len(alternatives)+2)
+	headerText := fmt.Sprintf("<@%s|%s> declined the booking for %s: %s", reviewer.SlackID, reviewer.Name, renderOccurrence(challenge, booking.Occurrence, loc), renderBookingPurpose(booking))
+	headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
+	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+	return append(sections, renderAlternatives(booking, alternatives)...)
+}
+
+// renderAlternatives lists the reviewers that are free for the slot of the booking, to book one of them instead
+func renderAlternatives(booking models.Booking, alternatives []scheduling.ReviewerInfo) []slack.Block {
+	if len(alternatives) == 0 {
+		emptyEl := slack.NewTextBlockObject("mrkdwn", "No other reviewer is free for that slot, please use `/reviewer find` or `/reviewer assign` to find another one.", false, false)
+		return []slack.Block{slack.NewSectionBlock(emptyEl, nil, nil)}
+	}
+
+	sections := make([]slack.Block, 0, len(alternatives)+1)
+	alternativesEl := slack.NewTextBlockObject("mrkdwn", "*These reviewers are free for the same slot:*", false, false)
+	sections = append(sections, slack.NewSectionBlock(alternativesEl, nil, nil))
+	for _, reviewerInfo := range alternatives {
+		sections = append(sections, renderReviewer(reviewerInfo, booking.Occurrence))
+	}
+	return sections
+}
+
+func renderRemoveReviewer(reviewer models.Reviewer, bookings []models.Booking, challenges map[string]models.ChallengeSetup, loc *time.Location) []slack.Block {
+	questionText := fmt.Sprintf("Remove <@%s|%s>? They have no upcoming bookings.", reviewer.SlackID, reviewer.Name)
+	confirmText := "They are not offered for bookings anymore. This cannot be undone."
+	if len(bookings) > 0 {
+		lines := make([]string, 0, len(bookings))
+		for _, booking := range bookings {
+			challenge := challenges[bookingChallengeID(reviewer, booking)]
+			lines = append(lines, fmt.Sprintf("%s: %s", renderOccurrence(challenge, booking.Occurrence, loc), renderBookingPurpose(booking)))
+		}
+		questionText = fmt.Sprintf("Remove <@%s|%s>? Their %d upcoming bookings are cancelled, you are shown the reviewers that can take them over:\n%s",
+			reviewer.SlackID, reviewer.Name, len(bookings), strings.Join(lines, "\n"))
+		confirmText = fmt.Sprintf("%d upcoming bookings are cancelled. This cannot be undone.", len(bookings))
+	}
+	questionEl := slack.NewTextBlockObject("mrkdwn", questionText, false, false)
+
+	removeEl := slack.NewButtonBlockElement(encodeAction(removeReviewer, confirmRemove), reviewer.SlackID,
+		slack.NewTextBlockObject("plain_text", "Remove", false, false))
+	removeEl.Style = slack.StyleDanger
+	removeEl.Confirm = slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject("plain_text", "Remove Reviewer", false, false),
+		slack.NewTextBlockObject("plain_text", confirmText, false, false),
+		slack.NewTextBlockObject("plain_text", "Remove", false, false),
+		slack.NewTextBlockObject("plain_text", "Keep", false, false))
+	keepEl := slack.NewButtonBlockElement(encodeAction(removeReviewer, keepReviewer), reviewer.SlackID,
+		slack.NewTextBlockObject("plain_text", "Keep", false, false))
+
+	return []slack.Block{
+		slack.NewSectionBlock(questionEl, nil, nil),
+		newActionBlock("remove_reviewer", []slack.BlockElement{removeEl, keepEl}),
+	}
+}
+
+// renderReassignBooking shows a cancelled booking of a removed reviewer with the reviewers that can take it over
+func renderReassignBooking(reviewer models.Reviewer, booking models.Booking, challenge models.ChallengeSetup, alternatives []scheduling.ReviewerInfo, loc *time.Location) []slack.Block {
+	headerText := fmt.Sprintf("The booking of <@%s|%s> for %s is cancelled: %s", reviewer.SlackID, reviewer.Name, renderOccurrence(challenge, booking.Occurrence, loc), renderBookingPurpose(booking))
+	headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
+	return append([]slack.Block{slack.NewSectionBlock(headerEl, nil, nil)}, renderAlternatives(booking, alternatives)...)
+}
+
+// Lines of the load report per message section, sections are limited to 3000 characters
+const loadReportLinesPerSection = 15
+
+func renderLoadReport(report []scheduling.ReviewerLoad, weeks int) []slack.Block {
+	sections := make([]slack.Block, 0, len(report)/loadReportLinesPerSection+2)
+	headerText := fmt.Sprintf("*Reviewer load in the last %d weeks*, bookings per week oldest first", weeks)
+	headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
+	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+
+	lines := make([]string, 0, loadReportLinesPerSection)
+	for i, load := range report {
+		weekly := make([]string, 0, len(load.Weekly))
+		for _, count := range load.Weekly {
+			weekly = append(weekly, strconv.Itoa(count))
+		}
+
+		monthCap := "no cap"
+		if load.Reviewer.BookingsPerMonth > 0 {
+			monthCap = fmt.Sprintf("max %d", load.Reviewer.BookingsPerMonth)
+		}
+		line := fmt.Sprintf("<@%s> `%s` %d total, full in %d weeks (max %d/week), %d this month (%s)",
+			load.Reviewer.SlackID, strings.Join(weekly, " "), load.Total, load.FullWeeks, load.Reviewer.BookingsPerWeek, load.ThisMonth, monthCap)
+		if load.Overloaded {
+			line = fmt.Sprintf(":warning: %s *overloaded*", line)
+		} else if load.Unused {
+			line = fmt.Sprintf(":zzz: %s *never booked*", line)
+		}
+		lines = append(lines, line)
+
+		if len(lines) == loadReportLinesPerSection || i == len(report)-1 {
+			linesEl := slack.NewTextBlockObject("mrkdwn", strings.Join(lines, "\n"), false, false)
+			sections = append(sections, slack.NewSectionBlock(linesEl, nil, nil))
+			lines = lines[:0]
+		}
+	}
+	return sections
+}
+
+func renderReminder(reminder scheduling.Reminder, challenge models.ChallengeSetup, loc *time.Location) []slack.Block {
+	when := "tomorrow"
+	if reminder.Kind == scheduling.HourReminder {
+		when = "in less than an hour"
+	}
+	start := reminder.Start.In(loc)
+	reminderText := fmt.Sprintf(":alarm_clock: *Reminder:* you have a booking %s for %s, %s %s\n%s",
+		when, challenge.Name, start.Format("Monday 15:04"), start.Format("MST"), renderBookingDetails(reminder.Booking))
+	reminderEl := slack.NewTextBlockObject("mrkdwn", reminderText, false, false)
+	return []slack.Block{
+		slack.NewSectionBlock(reminderEl, nil, nil),
+	}
+}
+
+func renderDigest(digest scheduling.Digest, challenge models.ChallengeSetup, loc *time.Location) []slack.Block {
+	sections := make([]slack.Block, 0, 10)
+	headerText := fmt.Sprintf("*Your %s bookings this week:* %s", challenge.Name, scheduling.WeekDescription(digest.WeekStart))
+	headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
+	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+
+	if len(digest.Bookings) == 0 {
+		emptyEl := slack.NewTextBlockObject("mrkdwn", "No bookings this week.", false, false)
+		sections = append(sections, slack.NewSectionBlock(emptyEl, nil, nil))
+	}
+	for _, booking := range digest.Bookings {
+		start := booking.Start.In(loc)
+		bookingText := fmt.Sprintf("*%s %s*\n%s", start.Format("Monday 15:04"), start.Format("MST"), renderBookingDetails(booking.Booking))
+		bookingEl := slack.NewTextBlockObject("mrkdwn", bookingText, false, false)
+		sections = append(sections, slack.NewSectionBlock(bookingEl, nil, nil))
+	}
+
+	if len(digest.UnsetWeeks) > 0 {
+		weeks := make([]string, 0, len(digest.UnsetWeeks))
+		for _, week := range digest.UnsetWeeks {
+			weeks = append(weeks, fmt.Sprintf("• %s", scheduling.WeekDescription(week)))
+		}
+		unsetText := fmt.Sprintf("*You are not available in any slot in:*\n%s\nUse `/reviewer schedule` to update your availability.", strings.Join(weeks, "\n"))
+		unsetEl := slack.NewTextBlockObject("mrkdwn", unsetText, false, false)
+		sections = append(sections, slack.NewDividerBlock(), slack.NewSectionBlock(unsetEl, nil, nil))
+	}
+
+	footerEl := slack.NewTextBlockObject("mrkdwn", "Use `/reviewer reminders off` to stop these messages.", false, false)
+	sections = append(sections, slack.NewContextBlock("", footerEl))
+	return sections
+}
+
+// renderBookingPurpose describes what the booking is for, e.g. "Code review for *Jane Doe*"
+func renderBookingPurpose(booking models.Booking) string {
+	purpose := models.BookingKindLabel(booking.Kind)
+	if booking.CandidateName != "" {
+		purpose = fmt.Sprintf("%s for *%s*", purpose, booking.CandidateName)
+	}
+	if booking.ChallengeURL != "" {
+		purpose = fmt.Sprintf("%s (<%s|challenge>)", purpose, booking.ChallengeURL)
+	}
+	return purpose
+}
+
+func renderBookingDetails(booking models.Booking) string {
+	details := renderBookingPurpose(booking)
+	if booking.BookedBy != "" {
+		details = fmt.Sprintf("%s\nBooked by <@%s>", details, booking.BookedBy)
+	}
+	if booking.Notes != "" {
+		details = fmt.Sprintf("%s\n_%s_", details, booking.Notes)
+	}
+	return details
+}
+
+// renderAssignment lists the ranked reviewers and asks to confirm the proposed pair
+func renderAssignment(candidateName string, weekStart time.Time, challenge models.ChallengeSetup, matches []scheduling.ReviewerMatch, pair []scheduling.ReviewerMatch, loc *time.Location) []slack.Block {
+	sections := make([]slack.Block, 0, 20)
+	headerText := fmt.Sprintf("*Reviewer ranking for %s in the week of:* %s", candidateName, scheduling.WeekDescription(weekStart))
+	headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
+	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+
+	for i, match := range matches {
+		reviewer := match.Reviewer
+		matchText := fmt.Sprintf("%d. *<@%s|%s>* (%s) - score %.1f\n%s", i+1, reviewer.SlackID, reviewer.Name, reviewer.TechnologyList, match.Score,
+			renderOccurrence(challenge, match.Occurrence, loc))
+		matchEl := slack.NewTextBlockObject("mrkdwn", matchText, false, false)
+		sections = append(sections, slack.NewSectionBlock(matchEl, nil, nil))
+	}
+
+	infos := make([]scheduleActionInfo, 0, len(pair))
+	mentions := make([]string, 0, len(pair))
+	for _, match := range pair {
+		infos = append(infos, newScheduleActionInfo(match.Reviewer.SlackID, match.Occurrence.SlotID, match.Occurrence))
+		mentions = append(mentions, fmt.Sprintf("<@%s|%s>", match.Reviewer.SlackID, match.Reviewer.Name))
+	}
+	pairText := fmt.Sprintf("*Proposed pair:* %s\n%s", strings.Join(mentions, " and "), renderOccurrence(challenge, pair[0].Occurrence, loc))
+	sections = append(sections, slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", pairText, false, false), nil, nil))
+
+	encodedID := encodeAction(assignReviewer, encodeAssignment(infos))
+	buttonText := "Book the pair"
+	buttonEl := slack.NewButtonBlockElement(encodedID, candidateName, slack.NewTextBlockObject("plain_text", buttonText, false, false))
+	buttonEl.Style = slack.StylePrimary
+	sections = append(sections, newActionBlock("assign_reviewers", []slack.BlockElement{buttonEl}))
+	return sections
+}
+
+// renderOccurrence describes a slot occurrence, e.g. "Tuesday Afternoon on 2019-07-16 (Tuesday : 14:30 - 16:30 EDT)"
+func renderOccurrence(challenge models.ChallengeSetup, occurrence models.SlotOccurrence, loc *time.Location) string {
+	slot := challenge.Slots[occurrence.SlotID]
+	weekStart, err := scheduling.WeekOfOccurrence(occurrence)
+	if slot == nil || err != nil {
+		return fmt.Sprintf("%s on %s", occurrence.SlotID, occurrence.Date)
+	}
+	return fmt.Sprintf("%s on %s (%s)", slot.Name, occurrence.Date, renderSlotTime(*slot, weekStart, loc))
+}
+
+func renderReviewerChallenges(reviewer models.Reviewer, challengeNames map[string]string) []slack.Block {
+	sections := make([]slack.Block, 0, len(reviewer.Challenges)+1)
+	headerText := fmt.Sprintf("Challenges reviewed by *<@%s>*", reviewer.SlackID)
+	headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
+	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+
+	for _, membership := range reviewer.Challenges {
+		name := challengeNames[membership.ChallengeID]
+		if name == "" {
+			name = membership.ChallengeID
+		}
+		membershipText := fmt.Sprintf("*%s*\nTechnologies: %s\nExperience: %s, max %d bookings/week",
+			name, membership.TechnologyList, experienceLabel(membership.Experience), membership.BookingsPerWeek)
+		if membership.BookingsPerMonth > 0 {
+			membershipText = fmt.Sprintf("%s, max %d bookings/month", membershipText, membership.BookingsPerMonth)
+		}
+		if membership.CooldownWeeks > 0 {
+			membershipText = fmt.Sprintf("%s, %d week(s) off after a full week", membershipText, membership.CooldownWeeks)
+		}
+		membershipEl := slack.NewTextBlockObject("mrkdwn", membershipText, false, false)
+		sections = append(sections, slack.NewSectionBlock(membershipEl, nil, nil))
+
+		editEl := slack.NewButtonBlockElement(encodeAction(reviewerChallenges, encodeRuleActionInfo(reviewer.SlackID, editMembership)), membership.ChallengeID,
+			slack.NewTextBlockObject("plain_text", "Edit", false, false))
+		leaveEl := slack.NewButtonBlockElement(encodeAction(reviewerChallenges, encodeRuleActionInfo(reviewer.SlackID, leaveMembership)), membership.ChallengeID,
+			slack.NewTextBlockObject("plain_text", "Leave", false, false))
+		leaveEl.Style = slack.StyleDanger
+		sections = append(sections, newActionBlock(fmt.Sprintf("membership_%d", len(sections)), []slack.BlockElement{editEl, leaveEl}))
+	}
+	return sections
+}
+
+func renderAvailabilityRules(reviewer models.Reviewer, challenge models.ChallengeSetup) []slack.Block {
+	sections := make([]slack.Block, 0, 50)
+	headerText := fmt.Sprintf("Recurring availability of *<@%s>*", reviewer.SlackID)
+	headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
+	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+
+	for _, rule := range reviewer.AvailabilityRules {
+		ruleText := renderRuleDescription(rule, challenge)
+		ruleEl := slack.NewTextBlockObject("mrkdwn", ruleText, false, false)
+		encodedID := encodeAction(removeRule, encodeRuleActionInfo(reviewer.SlackID, rule.ID))
+		buttonEl := slack.NewButtonBlockElement(encodedID, rule.ID, slack.NewTextBlockObject("plain_text", "Remove", false, false))
+		sections = append(sections, slack.NewSectionBlock(ruleEl, nil, slack.NewAccessory(buttonEl)))
+	}
+	if len(reviewer.AvailabilityRules) == 0 {
+		noRulesEl := slack.NewTextBlockObject("mrkdwn", "No recurring availability rules. Use /reviewer rule to add one.", false, false)
+		sections = append(sections, slack.NewSectionBlock(noRulesEl, nil, nil))
+	}
+
+	awayHeaderEl := slack.NewTextBlockObject("mrkdwn", "*Out of office*", false, false)
+	sections = append(sections, slack.NewSectionBlock(awayHeaderEl, nil, nil))
+	for _, away := range reviewer.OutOfOffice {
+		awayText := fmt.Sprintf("%s - %s", away.From, away.To)
+		if away.Reason != "" {
+			awayText = fmt.Sprintf("%s (%s)", awayText, away.Reason)
+		}
+		awayEl := slack.NewTextBlockObject("mrkdwn", awayText, false, false)
+		encodedID := encodeAction(removeOutOfOffice, encodeRuleActionInfo(reviewer.SlackID, away.ID))
+		buttonEl := slack.NewButtonBlockElement(encodedID, away.ID, slack.NewTextBlockObject("plain_text", "Remove", false, false))
+		sections = append(sections, slack.NewSectionBlock(awayEl, nil, slack.NewAccessory(buttonEl)))
+	}
+	if len(reviewer.OutOfOffice) == 0 {
+		noAwayEl := slack.NewTextBlockObject("mrkdwn", "No out of office dates. Use /reviewer away to add them.", false, false)
+		sections = append(sections, slack.NewSectionBlock(noAwayEl, nil, nil))
+	}
+	return sections
+}
+
+// renderRuleDescription describes a rule, e.g. "*Tuesday Afternoon* every 2 weeks from 2019-07-16, only in March"
+func renderRuleDescription(rule models.AvailabilityRule, challenge models.ChallengeSetup) string {
+	slotName := rule.SlotID
+	if slot := challenge.Slots[rule.SlotID]; slot != nil {
+		slotName = slot.Name
+	}
+
+	var every string
+	if rule.Interval > 1 {
+		every = fmt.Sprintf("every %d weeks", rule.Interval)
+	} else {
+		every = "every week"
+	}
+	description := fmt.Sprintf("*%s* %s from %s", slotName, every, rule.Start)
+
+	if len(rule.ByMonth) > 0 {
+		months := make([]string, 0, len(rule.ByMonth))
+		for _, month := range rule.ByMonth {
+			months = append(months, time.Month(month).String())
+		}
+		description = fmt.Sprintf("%s, only in %s", description, strings.Join(months, ", "))
+	}
+	if rule.Until != "" {
+		description = fmt.Sprintf("%s, until %s", description, rule.Until)
+	}
+	return fmt.Sprintf("%s\n`%s`", description, rule.String())
+}
+
+// renderSlotTime shows the slot in the viewer's time zone, e.g. "Monday : 14:30 - 16:30 EDT"
+func renderSlotTime(slot models.Slot, weekStart time.Time, loc *time.Location) string {
+	start, end, err := slot.Interval(weekStart)
+	if err != nil {
+		log.Println("[ERROR] Slot time cannot be resolved - ", err)
+		return fmt.Sprintf("%s : %s - %s", slot.Day, slot.StartTime, slot.EndTime)
+	}
+
+	start = start.In(loc)
+	end = end.In(loc)
+	return fmt.Sprintf("%s : %s - %s %s", start.Weekday(), start.Format("15:04"), end.Format("15:04"), start.Format("MST"))
+}
+
 func newActionBlock(blockID string, elements []slack.BlockElement) slack.ActionBlock {
 	return slack.ActionBlock{
 		Type:    slack.MBTAction,
@@ -219,3 +712,371 @@ func newActionBlock(blockID string, elements []slack.BlockElement) slack.ActionB
 		},
 	}
 }
+
+// Slack messages have at most 50 blocks and 25 buttons in an actions block
+const (
+	maxBlocks          = 50
+	maxButtonsPerBlock = 25
+)
+
+// renderTags shows a page of the tag groups, with the buttons to edit them. Pages are numbered from 0.
+func renderTags(taxonomy models.Taxonomy, page int) []slack.Block {
+	pages := tagPages(tagGroups(taxonomy))
+	if page < 0 || page >= len(pages) {
+		page = 0
+	}
+
+	sections := make([]slack.Block, 0, maxBlocks)
+	headerText := "*Technology tags*\nReviewers pick their technologies from these. Searching a tag also finds the narrower ones below it."
+	if len(taxonomy) == 0 {
+		headerText = "*Technology tags*\nThere are no tags yet. Reviewers pick their technologies from the tags you add."
+	}
+	if len(pages) > 1 {
+		headerText = fmt.Sprintf("%s\nPage %d of %d", headerText, page+1, len(pages))
+	}
+	headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
+	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+
+	if len(pages) > 0 {
+		for i, group := range pages[page] {
+			lines := make([]string, 0, len(group))
+			buttons := make([]slack.BlockElement, 0, len(group))
+			for _, tag := range group {
+				line := fmt.Sprintf("%s*%s*", strings.Repeat("    ", len(taxonomy.Ancestors(tag.ID))), tag.Name)
+				if len(tag.Aliases) > 0 {
+					line = fmt.Sprintf("%s (also %s)", line, strings.Join(tag.Aliases, ", "))
+				}
+				lines = append(lines, line)
+				buttons = append(buttons, slack.NewButtonBlockElement(encodeAction(editTags, editTag), tag.ID,
+					slack.NewTextBlockObject("plain_text", fmt.Sprintf("Edit %s", tag.Name), false, false)))
+			}
+			groupEl := slack.NewTextBlockObject("mrkdwn", strings.Join(lines, "\n"), false, false)
+			sections = append(sections, slack.NewSectionBlock(groupEl, nil, nil))
+			for j := 0; j < len(buttons); j += maxButtonsPerBlock {
+				end := j + maxButtonsPerBlock
+				if end > len(buttons) {
+					end = len(buttons)
+				}
+				sections = append(sections, newActionBlock(fmt.Sprintf("tags_%d_%d", i, j), buttons[j:end]))
+			}
+		}
+	}
+
+	addEl := slack.NewButtonBlockElement(encodeAction(editTags, addTag), "",
+		slack.NewTextBlockObject("plain_text", "Add Tag", false, false))
+	addEl.Style = slack.StylePrimary
+	buttons := []slack.BlockElement{addEl}
+	if page > 0 {
+		buttons = append(buttons, slack.NewButtonBlockElement(encodeAction(editTags, tagsPage), strconv.Itoa(page-1),
+			slack.NewTextBlockObject("plain_text", "Previous", false, false)))
+	}
+	if page < len(pages)-1 {
+		buttons = append(buttons, slack.NewButtonBlockElement(encodeAction(editTags, tagsPage), strconv.Itoa(page+1),
+			slack.NewTextBlockObject("plain_text", "Next", false, false)))
+	}
+	sections = append(sections, newActionBlock("add_tag", buttons))
+	return sections
+}
+
+// tagPages splits the tag groups into the pages of a message, a group takes a section and an actions block for
+// each 25 of its tags. The header and the buttons below the groups take the other 2 blocks.
+func tagPages(groups [][]models.Tag) [][][]models.Tag {
+	pages := make([][][]models.Tag, 0)
+	blocks := 0
+	for _, group := range groups {
+		groupBlocks := 1 + (len(group)+maxButtonsPerBlock-1)/maxButtonsPerBlock
+		if len(pages) == 0 || blocks+groupBlocks > maxBlocks-2 {
+			pages = append(pages, make([][]models.Tag, 0))
+			blocks = 0
+		}
+		pages[len(pages)-1] = append(pages[len(pages)-1], group)
+		blocks += groupBlocks
+	}
+	return pages
+}
+
+// tagPageOf is the page of the tags that shows the tag
+func tagPageOf(taxonomy models.Taxonomy, tagID string) int {
+	for i, page := range tagPages(tagGroups(taxonomy)) {
+		for _, group := range page {
+			for _, tag := range group {
+				if tag.ID == tagID {
+					return i
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// renderTagPicker shows the reviewer's technologies for the challenge, with a button to pick them from the tags
+func renderTagPicker(reviewer models.Reviewer, taxonomy models.Taxonomy, challengeName string) []slack.Block {
+	sections := make([]slack.Block, 0, 2)
+	if len(taxonomy) == 0 {
+		headerText := fmt.Sprintf("Technologies of *<@%s>* for %s: %s\nThere are no tags to pick yet, add them with /challenge tags.",
+			reviewer.SlackID, challengeName, reviewer.TechnologyList)
+		headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
+		return append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+	}
+
+	picked := make([]string, 0)
+	unknown := make([]string, 0)
+	for _, tag := range taxonomy.TagsOf(reviewer) {
+		if _, ok := taxonomy[tag]; ok {
+			picked = append(picked, tag)
+		} else {
+			unknown = append(unknown, tag)
+		}
+	}
+
+	names := "none picked yet"
+	if len(picked) > 0 {
+		names = strings.Join(taxonomy.Names(picked), ", ")
+	}
+	headerText := fmt.Sprintf("Technologies of *<@%s>* for %s: %s", reviewer.SlackID, challengeName, names)
+	if len(unknown) > 0 {
+		headerText = fmt.Sprintf("%s\nNot in the tags, only found by exact searches: %s", headerText, strings.Join(unknown, ", "))
+	}
+	headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
+	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+
+	pickEl := slack.NewButtonBlockElement(encodeAction(pickTags, reviewer.SlackID), reviewer.ChallengeID,
+		slack.NewTextBlockObject("plain_text", "Pick Tags", false, false))
+	return append(sections, newActionBlock("pick_tags", []slack.BlockElement{pickEl}))
+}
+
+// tagGroups splits the tags by their broadest ancestor, each group is the ancestor and its narrower tags
+func tagGroups(taxonomy models.Taxonomy) [][]models.Tag {
+	groups := make([][]models.Tag, 0)
+	roots := make(map[string]int)
+	for _, tag := range taxonomy.Ordered() {
+		root := taxonomy.Root(tag.ID)
+		i, ok := roots[root]
+		if !ok {
+			i = len(groups)
+			roots[root] = i
+			groups = append(groups, make([]models.Tag, 0))
+		}
+		groups[i] = append(groups[i], tag)
+	}
+	return groups
+}
+
+func renderNeedOffer(need models.Need, challenge models.ChallengeSetup, reviewerID string, occurrence models.SlotOccurrence, loc *time.Location) []slack.Block {
+	offerText := fmt.Sprintf("<@%s> needs %d reviewer(s) for *%s*: %s of %s.\nYou are free on *%s*, can you take it? The first reviewers to accept are booked.",
+		need.CreatedBy, need.Count, need.CandidateName, models.BookingKindLabel(need.Kind), challenge.Name, renderOccurrence(challenge, occurrence, loc))
+	if need.Technology != "" {
+		offerText = fmt.Sprintf("%s\nTechnology: %s", offerText, need.Technology)
+	}
+	offerEl := slack.NewTextBlockObject("mrkdwn", offerText, false, false)
+
+	acceptEl := slack.NewButtonBlockElement(encodeAction(needOffer, encodeRuleActionInfo(reviewerID, acceptOffer)), need.ID,
+		slack.NewTextBlockObject("plain_text", "Accept", false, false))
+	acceptEl.Style = slack.StylePrimary
+	passEl := slack.NewButtonBlockElement(encodeAction(needOffer, encodeRuleActionInfo(reviewerID, passOffer)), need.ID,
+		slack.NewTextBlockObject("plain_text", "Pass", false, false))
+
+	return []slack.Block{
+		slack.NewSectionBlock(offerEl, nil, nil),
+		newActionBlock("need_offer", []slack.BlockElement{acceptEl, passEl}),
+	}
+}
+
+// Each need takes two blocks of the 50 in a message
+const maxNeedsShown = 24
+
+func renderNeeds(needs []models.Need, challengeNames map[string]string) []slack.Block {
+	sections := make([]slack.Block, 0, 50)
+	headerText := "*Reviewers needed*"
+	if len(needs) == 0 {
+		headerText = "*Reviewers needed*\nThere are no needs, register one with /reviewer need."
+	}
+	headerEl := slack.NewTextBlockObject("mrkdwn", headerText, false, false)
+	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+
+	sort.SliceStable(needs, func(i, j int) bool { return needs[i].WeekStart < needs[j].WeekStart })
+	for _, need := range needs {
+		if len(sections) > 2*maxNeedsShown {
+			break
+		}
+		weekStart, _ := time.Parse(models.DateFormat, need.WeekStart)
+		needText := fmt.Sprintf("*%s*: %s of %s, week of %s, %d of %d reviewers booked",
+			need.CandidateName, models.BookingKindLabel(need.Kind), challengeNames[need.ChallengeID], scheduling.WeekDescription(weekStart), len(need.Booked), need.Count)
+		if need.Technology != "" {
+			needText = fmt.Sprintf("%s\nTechnology: %s", needText, need.Technology)
+		}
+		booked := make([]string, 0, len(need.Booked))
+		for _, booking := range need.Booked {
+			booked = append(booked, fmt.Sprintf("<@%s> on %s", booking.ReviewerID, booking.Occurrence.Date))
+		}
+		if len(booked) > 0 {
+			needText = fmt.Sprintf("%s\nBooked: %s", needText, strings.Join(booked, ", "))
+		}
+		if offers := len(need.OpenOffers()); offers > 0 {
+			needText = fmt.Sprintf("%s\n%d offer(s) waiting for an answer", needText, offers)
+		}
+		needEl := slack.NewTextBlockObject("mrkdwn", needText, false, false)
+		sections = append(sections, slack.NewSectionBlock(needEl, nil, nil))
+
+		cancelEl := slack.NewButtonBlockElement(encodeAction(cancelNeed, need.ID), need.ID,
+			slack.NewTextBlockObject("plain_text", "Cancel", false, false))
+		cancelEl.Style = slack.StyleDanger
+		cancelEl.Confirm = slack.NewConfirmationBlockObject(
+			slack.NewTextBlockObject("plain_text", "Cancel Need", false, false),
+			slack.NewTextBlockObject("plain_text", "No more slots are offered. The reviewers that accepted stay booked.", false, false),
+			slack.NewTextBlockObject("plain_text", "Cancel Need", false, false),
+			slack.NewTextBlockObject("plain_text", "Keep", false, false))
+		sections = append(sections, newActionBlock(fmt.Sprintf("need_%s", need.ID), []slack.BlockElement{cancelEl}))
+	}
+	return sections
+}
+
+// renderPendingReviews lists the solutions waiting for the review of the reviewer
+func renderPendingReviews(candidates []models.Candidate, challengeNames map[string]string, loc *time.Location) []slack.Block {
+	sections := make([]slack.Block, 0, len(candidates)+1)
+	headerText := "*Reviews waiting for you*"
+	if len(candidates) == 0 {
+		headerText = "*Reviews waiting for you*\nThere are no solutions to review."
+	}
+	sections = append(sections, slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", headerText, false, false), nil, nil))
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].SubmittedAt.Before(candidates[j].SubmittedAt) })
+	for _, candidate := range candidates {
+		reviewText := fmt.Sprintf("*%s* (%s) submitted <%s|a solution> on %s",
+			candidate.Name, challengeNames[candidate.ChallengeID], candidate.SubmissionURL, candidate.SubmittedAt.In(loc).Format("Monday, 2 January"))
+		sections = append(sections, slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", reviewText, false, false), nil, nil))
+	}
+	return sections
+}
+
+// renderCandidatesInFlight lists the candidates the coordinator sent a challenge to, until their pull request is closed
+func renderCandidatesInFlight(candidates []models.Candidate, challengeNames map[string]string, loc *time.Location) []slack.Block {
+	sections := make([]slack.Block, 0, len(candidates)+1)
+	headerEl := slack.NewTextBlockObject("mrkdwn", "*Your candidates in flight*", false, false)
+	sections = append(sections, slack.NewSectionBlock(headerEl, nil, nil))
+
+	for _, candidate := range candidates {
+		var status string
+		if candidate.IsSubmitted() {
+			status = fmt.Sprintf("<%s|Solution> submitted on %s, waiting for the review", candidate.SubmissionURL, candidate.SubmittedAt.In(loc).Format("2 January"))
+		} else {
+			status = fmt.Sprintf("Challenge sent on %s, waiting for the solution", candidate.CreatedAt.In(loc).Format("2 January"))
+		}
+		if candidate.HasPairing() {
+			status = fmt.Sprintf("%s\nLive pairing session on %s", status, candidate.Pairing.Date)
+		}
+		candidateText := fmt.Sprintf("*%s* (%s), reviewers: %s\n%s", candidate.Name, challengeNames[candidate.ChallengeID], renderMentions(candidate.ReviewerIDs), status)
+		sections = append(sections, slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", candidateText, false, false), nil, nil))
+	}
+	return sections
+}
+
+func renderMentions(slackIDs []string) string {
+	if len(slackIDs) == 0 {
+		return "none"
+	}
+	mentions := make([]string, 0, len(slackIDs))
+	for _, slackID := range slackIDs {
+		mentions = append(mentions, fmt.Sprintf("<@%s>", slackID))
+	}
+	return strings.Join(mentions, ", ")
+}
+
+// Block ID of the actions of a single candidate card, the cards of a list have the candidate ID in theirs
+const candidateCardBlockID = "candidate_card"
+
+// Candidates shown in a list, each card takes two of the 50 blocks of a message
+const maxCandidateCards = 20
+
+func renderCandidateList(title string, candidates []models.Candidate, challengeNames map[string]string, now time.Time, loc *time.Location) []slack.Block {
+	sections := make([]slack.Block, 0, 2*maxCandidateCards+2)
+	headerText := fmt.Sprintf("*%s* (%d)", title, len(candidates))
+	sections = append(sections, slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", headerText, false, false), nil, nil))
+	if len(candidates) == 0 {
+		emptyEl := slack.NewTextBlockObject("mrkdwn", "No candidates found.", false, false)
+		return append(sections, slack.NewSectionBlock(emptyEl, nil, nil))
+	}
+
+	for i, candidate := range candidates {
+		if i == maxCandidateCards {
+			moreText := fmt.Sprintf("%d more candidates are not shown, pick a challenge or a state to see them.", len(candidates)-maxCandidateCards)
+			sections = append(sections, slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", moreText, false, false), nil, nil))
+			break
+		}
+		sections = append(sections, candidateCardBlocks(candidate, challengeNames[candidate.ChallengeID], now, loc, "candidate_"+candidate.ID)...)
+	}
+	return sections
+}
+
+func renderCandidateCard(candidate models.Candidate, challengeName string, now time.Time, loc *time.Location) []slack.Block {
+	return candidateCardBlocks(candidate, challengeName, now, loc, candidateCardBlockID)
+}
+
+// candidateCardBlocks shows the repository, reviewers, deadline, build and verdicts of the candidate, with the buttons to
+// extend their deadline or withdraw them while they are in flight
+func candidateCardBlocks(candidate models.Candidate, challengeName string, now time.Time, loc *time.Location, blockID string) []slack.Block {
+	state := candidate.State(now)
+	headerText := fmt.Sprintf("*%s* (<https://github.com/%s|%s>), %s: *%s*", candidate.Name, candidate.GithubAlias, candidate.GithubAlias, challengeName, state)
+	if candidate.HasPairing() {
+		headerText = fmt.Sprintf("%s\nLive pairing session on %s", headerText, candidate.Pairing.Date)
+	}
+
+	repoText := "*Repository*\nnot created"
+	if candidate.ChallengeURL != "" {
+		repoURL := strings.TrimSuffix(candidate.ChallengeURL, ".git")
+		repoText = fmt.Sprintf("*Repository*\n<%s|%s>", repoURL, path.Base(repoURL))
+	}
+	if candidate.IsSubmitted() {
+		repoText = fmt.Sprintf("%s, <%s|solution>", repoText, candidate.SubmissionURL)
+	}
+	deadlineText := fmt.Sprintf("*Deadline*\n%s", candidate.DueDate().In(loc).Format("Monday, 2 January"))
+	if state == models.CandidateOverdue {
+		deadlineText = fmt.Sprintf("%s, %d days overdue", deadlineText, int(now.Sub(candidate.DueDate()).Hours()/24))
+	}
+	ciStatus := candidate.CIStatus
+	if ciStatus == "" {
+		ciStatus = "no build yet"
+	}
+
+	fields := []*slack.TextBlockObject{
+		slack.NewTextBlockObject("mrkdwn", repoText, false, false),
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Reviewers*\n%s", renderMentions(candidate.ReviewerIDs)), false, false),
+		slack.NewTextBlockObject("mrkdwn", deadlineText, false, false),
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Build*\n%s", ciStatus), false, false),
+		slack.NewTextBlockObject("mrkdwn", fmt.Sprintf("*Verdicts*\n%s", renderVerdicts(candidate.Verdicts)), false, false),
+	}
+	sections := []slack.Block{slack.NewSectionBlock(slack.NewTextBlockObject("mrkdwn", headerText, false, false), fields, nil)}
+	if !candidate.InFlight() {
+		return sections
+	}
+
+	extendEl := slack.NewButtonBlockElement(encodeAction(candidateCard, extendDeadline), candidate.ID,
+		slack.NewTextBlockObject("plain_text", fmt.Sprintf("Extend %d Days", cardExtensionDays), false, false))
+	withdrawEl := slack.NewButtonBlockElement(encodeAction(candidateCard, withdrawCandidate), candidate.ID,
+		slack.NewTextBlockObject("plain_text", "Withdraw", false, false))
+	withdrawEl.Style = slack.StyleDanger
+	withdrawEl.Confirm = slack.NewConfirmationBlockObject(
+		slack.NewTextBlockObject("plain_text", "Withdraw Candidate", false, false),
+		slack.NewTextBlockObject("plain_text", fmt.Sprintf("%s loses their access to the challenge repository, the reviewers are told.", candidate.Name), false, false),
+		slack.NewTextBlockObject("plain_text", "Withdraw", false, false),
+		slack.NewTextBlockObject("plain_text", "Keep", false, false))
+	return append(sections, newActionBlock(blockID, []slack.BlockElement{extendEl, withdrawEl}))
+}
+
+// renderVerdicts lists the review states by the Github alias of the reviewer, e.g. "jane: approved"
+func renderVerdicts(verdicts map[string]string) string {
+	if len(verdicts) == 0 {
+		return "none"
+	}
+	aliases := make([]string, 0, len(verdicts))
+	for alias := range verdicts {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	lines := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		lines = append(lines, fmt.Sprintf("%s: %s", alias, strings.ReplaceAll(verdicts[alias], "_", " ")))
+	}
+	return strings.Join(lines, "\n")
+}
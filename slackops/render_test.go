@@ -0,0 +1,34 @@
+package slackops
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagPages(t *testing.T) {
+	taxonomy := make(models.Taxonomy)
+	for i := 0; i < 30; i++ {
+		id := fmt.Sprintf("tag%02d", i)
+		taxonomy[id] = models.Tag{ID: id, Name: id}
+	}
+	for i := 0; i < 30; i++ {
+		id := fmt.Sprintf("tag00_%02d", i)
+		taxonomy[id] = models.Tag{ID: id, Name: id, Parent: "tag00"}
+	}
+
+	// The first group takes a section and 2 actions blocks, the others a section and an actions block each
+	pages := tagPages(tagGroups(taxonomy))
+	assert.Equal(t, 2, len(pages))
+	assert.Equal(t, 23, len(pages[0]))
+	assert.Equal(t, 7, len(pages[1]))
+	assert.Equal(t, 31, len(pages[0][0]))
+
+	assert.Equal(t, 0, tagPageOf(taxonomy, "tag00_29"))
+	assert.Equal(t, 1, tagPageOf(taxonomy, "tag29"))
+	for page := range pages {
+		assert.True(t, len(renderTags(taxonomy, page)) <= maxBlocks)
+	}
+}
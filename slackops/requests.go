@@ -30,37 +30,104 @@ func newRequest(env config.Environment, icb *slack.InteractionCallback) request
 	}
 }
 
-func HandleRequests(env config.Environment, readCloser io.ReadCloser) error {
-	icb, err := parseInteractionCallback(readCloser, env.VerificationToken)
+// reply answers the user only, through the response URL of the interaction if it has one. The modals and the Home
+// tab do not have one, their replies are posted ephemerally in the channel, or in the app's messages.
+func (r request) reply(msgOption slack.MsgOption) error {
+	if r.icb.ResponseURL != "" {
+		err := respondMessage(r.icb.ResponseURL, msgOption, false)
+		if err == nil {
+			return nil
+		}
+		log.Println("[ERROR] Cannot reply to the interaction, replying ephemerally - ", err)
+	}
+	if r.icb.Channel.ID != "" && r.icb.Channel.ID != r.icb.User.ID {
+		err := r.ctx.postEphemeral(r.icb.Channel.ID, r.icb.User.ID, msgOption)
+		if err == nil {
+			return nil
+		}
+		log.Println("[ERROR] Cannot reply ephemerally, replying in the app's messages - ", err)
+	}
+	return r.ctx.postMessage(r.icb.User.ID, msgOption)
+}
+
+// update replaces the message of the interaction. The ephemeral messages can only be replaced through the response URL.
+func (r request) update(msgOption slack.MsgOption) error {
+	if r.icb.ResponseURL != "" {
+		return respondMessage(r.icb.ResponseURL, msgOption, true)
+	}
+	return r.ctx.updateMessage(r.icb.Channel.ID, r.icb.Message.Timestamp, msgOption)
+}
+
+// HandleRequests handles the interactions, and returns the response to modal submissions
+func HandleRequests(env config.Environment, readCloser io.ReadCloser) ([]byte, error) {
+	icb, err := parseInteractionCallback(readCloser)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	r := newRequest(env, icb)
 
+	if _, err := r.ctx.getToken(); err == errAppNotInstalled {
+		if icb.ResponseURL != "" {
+			respond(icb.ResponseURL, reinstallMessage(env))
+		}
+		return nil, err
+	}
+
 	switch icb.Type {
-	case "dialog_submission":
-		err = r.handleDialogSubmission()
+	case viewSubmission:
+		return r.handleViewSubmission()
 	case "block_actions":
 		err = r.handleBlockActions()
 	default:
-		err = errors.New("[ERROR] Unknown dialog response")
-		log.Println("[ERROR] Unknown dialog response - ", icb.CallbackID)
+		err = errors.New("[ERROR] Unknown interaction")
+		log.Println("[ERROR] Unknown interaction - ", icb.Type)
 	}
 
-	return err
+	return nil, err
 }
 
-func (r request) handleDialogSubmission() error {
+// handleViewSubmission closes the modal, shows the errors of the inputs in it, or pushes the next step
+func (r request) handleViewSubmission() ([]byte, error) {
+	var response modalResponse
 	var err error
 
 	switch r.icb.CallbackID {
 	case "send_challenge":
+		response, err = r.handleSendChallengeCandidate()
+	default:
+		err = r.handleSubmission()
+	}
+
+	if errs, ok := err.(inputErrors); ok {
+		return json.Marshal(modalResponse{
+			ResponseAction: "errors",
+			Errors:         errs,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if response.ResponseAction == "" {
+		response.ResponseAction = "clear"
+	}
+	return json.Marshal(response)
+}
+
+func (r request) handleSubmission() error {
+	var err error
+
+	switch r.icb.CallbackID {
+	case "send_challenge_reviewers":
 		err = r.handleSendChallenge()
 	case "new_challenge":
 		err = r.handleNewChallenge()
 	case "edit_challenge":
 		err = r.handleEditChallenge()
+	case "add_slot":
+		err = r.handleAddSlot()
+	case "edit_slot":
+		err = r.handleEditSlot()
 	case "new_reviewer":
 		err = r.handleNewReviewer()
 	case "edit_reviewer":
@@ -69,6 +136,22 @@ func (r request) handleDialogSubmission() error {
 		err = r.handleShowSchedule()
 	case "find_reviewers":
 		err = r.handleFindReviewers()
+	case "book_reviewer":
+		err = r.handleBookReviewer()
+	case "assign_reviewers":
+		err = r.handleAssignReviewers()
+	case "availability_rule":
+		err = r.handleNewRule()
+	case "out_of_office":
+		err = r.handleOutOfOffice()
+	case "import_availability":
+		err = r.handleImportCalendar()
+	case "edit_tag":
+		err = r.handleEditTag()
+	case "pick_tags":
+		err = r.handlePickTags()
+	case "new_need":
+		err = r.handleNewNeed()
 	default:
 		err = errors.New("[ERROR] Unknown CallbackID")
 		log.Println("[ERROR] Unknown CallbackID - ", r.icb.CallbackID)
@@ -91,6 +174,36 @@ func (r request) handleBlockActions() error {
 		fallthrough
 	case findReviewers:
 		err = r.handleBookings(encodedActionInfo)
+	case assignReviewer:
+		err = r.handleConfirmAssignment(encodedActionInfo)
+	case declineBooking:
+		err = r.handleDeclineBooking(encodedActionInfo)
+	case editSlots:
+		err = r.handleEditSlots(encodedActionInfo)
+	case importAvailability:
+		err = r.handleImportDecision(encodedActionInfo)
+	case reviewerChallenges:
+		err = r.handleReviewerChallenge(encodedActionInfo)
+	case editTags:
+		err = r.handleEditTagAction(encodedActionInfo)
+	case pickTags:
+		err = r.handlePickTagsAction(encodedActionInfo)
+	case needOffer:
+		err = r.handleNeedOffer(encodedActionInfo)
+	case cancelNeed:
+		err = r.handleCancelNeed(encodedActionInfo)
+	case deleteChallenge:
+		err = r.handleDeleteChallenge(encodedActionInfo)
+	case removeReviewer:
+		err = r.handleRemoveReviewer(encodedActionInfo)
+	case candidateCard:
+		err = r.handleCandidateCard(encodedActionInfo)
+	case downloadCalendar:
+		err = nil
+	case removeRule:
+		fallthrough
+	case removeOutOfOffice:
+		err = r.handleRemoveRule(action, encodedActionInfo)
 	default:
 		err = errors.New("[ERROR] Unknown action")
 		log.Println("[ERROR] Unknown action - ", action)
@@ -98,7 +211,7 @@ func (r request) handleBlockActions() error {
 	return err
 }
 
-func parseInteractionCallback(readCloser io.ReadCloser, verificationToken string) (*slack.InteractionCallback, error) {
+func parseInteractionCallback(readCloser io.ReadCloser) (*slack.InteractionCallback, error) {
 	payload, err := payloadContents(readCloser)
 	if err != nil {
 		return nil, err
@@ -111,9 +224,29 @@ func parseInteractionCallback(readCloser io.ReadCloser, verificationToken string
 		return nil, err
 	}
 
-	if icb.Token != verificationToken {
-		log.Println("[ERROR] Unable to validate request ", err)
-		return nil, ValidationError{}
+	// The values and metadata of modals are set as the ones of dialogs
+	if icb.Type == viewSubmission || icb.Type == blockSuggestion {
+		vp, metadata, err := parseViewPayload(payload)
+		if err != nil {
+			return nil, err
+		}
+		icb.CallbackID = vp.View.CallbackID
+		icb.State = metadata.State
+		icb.Channel.ID = metadata.Channel
+		icb.Submission = vp.submission(metadata)
+		icb.Name = vp.ActionID
+		icb.Value = vp.Value
+	}
+	// The block actions of the Home tab reply to the user in the app's messages
+	if icb.Type == "block_actions" {
+		vp, _, err := parseViewPayload(payload)
+		if err != nil {
+			return nil, err
+		}
+		if vp.View.Type == "home" {
+			icb.CallbackID = vp.View.CallbackID
+			icb.Channel.ID = icb.User.ID
+		}
 	}
 
 	return &icb, nil
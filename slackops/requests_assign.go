@@ -0,0 +1,177 @@
+package slackops
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/scheduling"
+	"github.com/nlopes/slack"
+)
+
+// Number of ranked reviewers shown along with the proposed pair
+const maxRankedReviewers = 5
+
+func (r request) handleAssignReviewers() error {
+	input := r.icb.Submission
+
+	weekStart, err := decodeWeek(input["year_week"])
+	if err != nil {
+		weekStart = scheduling.FirstDayOfWeek(time.Now())
+	}
+	experience, err := strconv.Atoi(input["experience"])
+	if err != nil {
+		experience = 1
+	}
+	criteria := scheduling.AssignmentCriteria{
+		Technology: input["technology"],
+		Experience: experience,
+		WeekStart:  weekStart,
+	}
+
+	go r.proposeReviewers(input["candidate_name"], input["challenge_id"], criteria)
+	return nil
+}
+
+func (r request) proposeReviewers(candidateName, challengeID string, criteria scheduling.AssignmentCriteria) {
+	challenge, err := models.GetChallengeSetupByID(r.ctx.Env, challengeID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find the challenge setup - ", err)
+		r.reply(toMsgOption("Cannot find the challenge, please pick a registered challenge."))
+		return
+	}
+
+	matches, err := scheduling.RankReviewers(r.ctx.Env, challenge, criteria)
+	if err != nil {
+		log.Println("[ERROR] Cannot rank the reviewers - ", err)
+		r.reply(toMsgOption("There was an error. Reviewers cannot be ranked."))
+		return
+	}
+	if len(matches) < 2 {
+		errorMsg := fmt.Sprintf("Not enough reviewers available for %s in the week of %s, try another week or use /reviewer find.",
+			challenge.Name, scheduling.WeekDescription(criteria.WeekStart))
+		r.reply(toMsgOption(errorMsg))
+		return
+	}
+
+	pair := scheduling.ProposePair(matches)
+	if len(pair) < 2 {
+		errorMsg := fmt.Sprintf("No two reviewers for %s share a free slot in the week of %s, try another week or use /reviewer find.",
+			challenge.Name, scheduling.WeekDescription(criteria.WeekStart))
+		r.reply(toMsgOption(errorMsg))
+		return
+	}
+	if len(matches) > maxRankedReviewers {
+		matches = matches[:maxRankedReviewers]
+	}
+	loc := r.ctx.getUserLocation(r.icb.User.ID)
+	sections := renderAssignment(candidateName, criteria.WeekStart, challenge, matches, pair, loc)
+
+	r.reply(slack.MsgOptionBlocks(sections...))
+}
+
+func (r request) handleConfirmAssignment(encodedActionInfo string) error {
+	infos, err := decodeAssignment(encodedActionInfo)
+	if err != nil {
+		log.Println("[ERROR] Cannot decode assignment - ", err)
+		return err
+	}
+	candidateName := r.icb.ActionCallback.BlockActions[0].Value
+
+	go r.confirmAssignment(candidateName, infos)
+	return nil
+}
+
+// confirmAssignment books all proposed reviewers, or none of them if one cannot be booked anymore
+func (r request) confirmAssignment(candidateName string, infos []scheduleActionInfo) {
+	booked := make([]models.Reviewer, 0, len(infos))
+	occurrences := make([]models.SlotOccurrence, 0, len(infos))
+	for _, info := range infos {
+		reviewer, occurrence, err := r.bookAssignedReviewer(candidateName, info)
+		if err != nil {
+			r.releaseAssignedReviewers(booked, occurrences)
+			return
+		}
+		booked = append(booked, reviewer)
+		occurrences = append(occurrences, occurrence)
+	}
+
+	lines := make([]string, 0, len(booked))
+	for i, reviewer := range booked {
+		lines = append(lines, fmt.Sprintf("<@%s|%s> for the slot %s on %s", reviewer.SlackID, reviewer.Name, occurrences[i].SlotID, occurrences[i].Date))
+	}
+	msg := fmt.Sprintf("Booked reviewers for %s:\n%s", candidateName, strings.Join(lines, "\n"))
+	r.update(toMsgOption(msg))
+
+	for i, reviewer := range booked {
+		r.notifyReviewer(reviewer, reviewer.Bookings[occurrences[i].Key()], true)
+	}
+}
+
+func (r request) bookAssignedReviewer(candidateName string, info scheduleActionInfo) (models.Reviewer, models.SlotOccurrence, error) {
+	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, info.ReviewerID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered anymore, please run /reviewer assign again.", info.ReviewerID)
+		r.reply(toMsgOption(errorMsg))
+		return reviewer, models.SlotOccurrence{}, err
+	}
+
+	occurrence, err := info.occurrence()
+	if err != nil {
+		log.Println("[ERROR] Cannot decode slot occurrence - ", err)
+		return reviewer, occurrence, err
+	}
+
+	if scheduling.IsBooked(reviewer, occurrence) {
+		errorMsg := fmt.Sprintf("<@%s> was booked for the slot %s on %s in the meantime, please run /reviewer assign again.", reviewer.SlackID, occurrence.SlotID, occurrence.Date)
+		r.reply(toMsgOption(errorMsg))
+		return reviewer, occurrence, errors.New("[ERROR] Slot is already booked")
+	}
+
+	reviewer, challenge, err := scheduling.ChallengeOfSlot(r.ctx.Env, reviewer, occurrence.SlotID)
+	if err != nil {
+		log.Println("[ERROR] Slot is not in a challenge of the reviewer - ", err)
+		return reviewer, occurrence, err
+	}
+
+	booking := models.NewBooking(reviewer.SlackID, occurrence, map[string]string{
+		"candidate_name": candidateName,
+		"challenge_id":   challenge.ID,
+		"booked_by":      r.icb.User.ID,
+		"kind":           models.CodeReview,
+	})
+	reviewer, err = scheduling.UpdateReviewerBooking(r.ctx.Env, reviewer, scheduling.SlotBooking{
+		Occurrence: occurrence,
+		IsBooked:   true,
+		Booking:    booking,
+	})
+	if err != nil {
+		switch err.(type) {
+		case scheduling.MaxBookingsError, scheduling.MaxMonthlyBookingsError, scheduling.CooldownError:
+			errorMsg := fmt.Sprintf("%s Please run /reviewer assign again.", capacityMessage(reviewer, err))
+			r.reply(toMsgOption(errorMsg))
+		default:
+			log.Println("[ERROR] Update booking not successful - ", err)
+			r.reply(toMsgOption("There was an error. Booking cannot be updated."))
+		}
+		return reviewer, occurrence, err
+	}
+	return reviewer, occurrence, nil
+}
+
+func (r request) releaseAssignedReviewers(reviewers []models.Reviewer, occurrences []models.SlotOccurrence) {
+	for i, reviewer := range reviewers {
+		_, err := scheduling.UpdateReviewerBooking(r.ctx.Env, reviewer, scheduling.SlotBooking{
+			Occurrence: occurrences[i],
+			IsBooked:   false,
+		})
+		if err != nil {
+			log.Println("[ERROR] Cannot release the booking - ", err)
+		}
+	}
+}
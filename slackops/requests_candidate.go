@@ -0,0 +1,53 @@
+package slackops
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/nlopes/slack"
+)
+
+// Days the deadline is extended by with the button of the candidate card
+const cardExtensionDays = 3
+
+func (r request) handleCandidateCard(operation string) error {
+	candidateID := r.icb.ActionCallback.BlockActions[0].Value
+	switch operation {
+	case extendDeadline, withdrawCandidate:
+		go r.updateCandidateCard(candidateID, operation)
+		return nil
+	default:
+		return fmt.Errorf("[ERROR] Unknown candidate operation - %s", operation)
+	}
+}
+
+// updateCandidateCard extends the deadline of the candidate or withdraws them, and renders their card again
+func (r request) updateCandidateCard(candidateID string, operation string) {
+	candidate, err := models.GetCandidate(r.ctx.Env, candidateID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find the candidate - ", candidateID, err)
+		r.reply(toMsgOption("Cannot find the candidate."))
+		return
+	}
+
+	if operation == extendDeadline {
+		candidate, err = extendCandidate(r.ctx, candidate, cardExtensionDays)
+	} else {
+		candidate, err = withdrawFromChallenge(r.ctx, candidate, r.icb.User.ID)
+	}
+	if err != nil {
+		r.reply(toMsgOption(err.Error()))
+		return
+	}
+
+	challengeName := candidateChallengeNames(r.ctx, []models.Candidate{candidate})[candidate.ChallengeID]
+	sections := renderCandidateCard(candidate, challengeName, time.Now(), r.ctx.getUserLocation(r.icb.User.ID))
+	if r.icb.ActionCallback.BlockActions[0].BlockID != candidateCardBlockID {
+		// The card is in a list of candidates, the list is kept as it is
+		r.reply(slack.MsgOptionBlocks(sections...))
+		return
+	}
+	r.update(slack.MsgOptionBlocks(sections...))
+}
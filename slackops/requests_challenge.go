@@ -5,11 +5,60 @@ import (
 	"fmt"
 	"log"
 	"regexp"
+	"strings"
+	"time"
 
 	"github.com/keremk/challenge-bot/models"
 	"github.com/keremk/challenge-bot/repo"
 )
 
+// activeReviewers leaves out the reviewers that are paused today
+func activeReviewers(reviewers []models.Reviewer, now time.Time) []models.Reviewer {
+	active := make([]models.Reviewer, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		if !reviewer.IsPausedOn(now.Format(models.DateFormat)) {
+			active = append(active, reviewer)
+		}
+	}
+	return active
+}
+
+// handleSendChallengeCandidate checks the candidate, and pushes the step to pick the reviewers of the challenge
+func (r request) handleSendChallengeCandidate() (modalResponse, error) {
+	input := r.icb.Submission
+	challenge, err := models.GetChallengeSetupByID(r.ctx.Env, input["challenge_id"])
+	if err != nil {
+		log.Println("[ERROR] No such challenge is registered.", err)
+		return modalResponse{}, inputErrors{"challenge_id": "This challenge is not registered anymore, please pick another one."}
+	}
+
+	repoCtx := repo.NewActionContext(r.ctx.Env, challenge)
+	githubAlias := strings.TrimSpace(input["github_alias"])
+	if !repoCtx.CheckUser(githubAlias) {
+		return modalResponse{}, inputErrors{"github_alias": fmt.Sprintf("There is no Github user %s.", githubAlias)}
+	}
+	input["github_alias"] = githubAlias
+
+	reviewers, err := models.GetAllReviewersForChallenge(r.ctx.Env, challenge.ID)
+	if err != nil {
+		log.Println("[ERROR] Cannot get the reviewers of the challenge - ", err)
+	}
+	reviewers = activeReviewers(reviewers, time.Now())
+	if len(reviewers) == 0 {
+		// There are no reviewers to pick, the challenge is sent right away
+		return modalResponse{}, r.handleSendChallenge()
+	}
+
+	next := modalView(sendReviewersDialog(challenge, reviewers), modalMetadata{
+		Channel: r.icb.Channel.ID,
+		Values:  input,
+	})
+	return modalResponse{
+		ResponseAction: "push",
+		View:           &next,
+	}, nil
+}
+
 func (r request) handleSendChallenge() error {
 	candidate, reviewers, err := r.parseSendDialogInput(r.icb.Submission)
 	if err != nil {
@@ -20,8 +69,24 @@ func (r request) handleSendChallenge() error {
 	if err != nil {
 		return err
 	}
-	go r.sendChallenge(challenge, candidate, reviewers)
 
+	// The reviewers need Github access to the challenge repository
+	repoCtx := repo.NewActionContext(r.ctx.Env, challenge)
+	errs := inputErrors{}
+	for i, reviewer := range reviewers {
+		if i > 0 && reviewer.SlackID == reviewers[0].SlackID {
+			errs["reviewer2_id"] = "Please pick another reviewer than Reviewer 1."
+			continue
+		}
+		if !repoCtx.CheckUser(reviewer.GithubAlias) {
+			errs[fmt.Sprintf("reviewer%d_id", i+1)] = fmt.Sprintf("The Github alias %s of %s is not correct, please fix it with /reviewer edit.", reviewer.GithubAlias, reviewer.Name)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	go r.sendChallenge(challenge, candidate, reviewers)
 	return nil
 }
 
@@ -31,10 +96,14 @@ func (r request) parseSendDialogInput(input map[string]string) (models.Candidate
 	reviewerLabels := []string{"reviewer1_id", "reviewer2_id"}
 
 	for _, label := range reviewerLabels {
+		if input[label] == "" {
+			continue
+		}
 		reviewer, err := r.resolveReviewer(input[label])
-		if err == nil {
-			reviewers = append(reviewers, reviewer)
+		if err != nil {
+			return candidate, reviewers, inputErrors{label: "This reviewer is not registered anymore, please pick another one."}
 		}
+		reviewers = append(reviewers, reviewer)
 	}
 	return candidate, reviewers, nil
 }
@@ -55,28 +124,11 @@ func (r request) resolveReviewer(reviewerSlackID string) (models.Reviewer, error
 }
 
 func (r request) sendChallenge(challenge models.ChallengeSetup, candidate models.Candidate, reviewers []models.Reviewer) {
+	// The Github aliases of the candidate and the reviewers were checked in the modal
 	repoCtx := repo.NewActionContext(r.ctx.Env, challenge)
 
-	// Check the candidate
-	if repoCtx.CheckUser(candidate.GithubAlias) == false {
-		errorMsg := fmt.Sprintf("Github Alias %s for candidate %s is not correct", candidate.GithubAlias, candidate.Name)
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(errorMsg))
-		return
-	}
-
-	// Check the reviewers
-	for _, reviewer := range reviewers {
-		if repoCtx.CheckUser(reviewer.GithubAlias) == false {
-			errorMsg := fmt.Sprintf("Github Alias %s for reviewer %s is not correct", reviewer.GithubAlias, reviewer.Name)
-			r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(errorMsg))
-			return
-		}
-	}
-
-	// log.Println("[INFO] Reviewer count = ", len(reviewers))
-
 	// Create the challenge
-	r.ctx.postMessage(r.icb.Channel.ID, toMsgOption("Please be patient, while I go create a coding challenge for you..."))
+	r.reply(toMsgOption("Please be patient, while I go create a coding challenge for you..."))
 	challengeURL, err := repoCtx.CreateChallenge(candidate, challenge, reviewers)
 	if err != nil {
 		re := regexp.MustCompile(dreadedPrivateRepoError)
@@ -84,12 +136,24 @@ func (r request) sendChallenge(challenge models.ChallengeSetup, candidate models
 		if re.FindStringIndex(err.Error()) != nil {
 			errorMsg = fmt.Sprintf("Unable to create challenge. You need to cleanup private repositories, because you exceeded your allowed limit.")
 		} else {
-			errorMsg = fmt.Sprintf("Unable to create challenge for %s because of ", candidate.Name, err.Error())
+			errorMsg = fmt.Sprintf("Unable to create challenge for %s because of %s", candidate.Name, err.Error())
 		}
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(errorMsg))
+		r.reply(toMsgOption(errorMsg))
 		return
 	}
-	r.ctx.postMessage(r.icb.Channel.ID, renderChallengeSummary(candidate, challengeURL, challenge.TrackingIssuesURL()))
+	r.reply(renderChallengeSummary(candidate, challengeURL, challenge.TrackingIssuesURL()))
+
+	// The candidate is saved to invite them to a live pairing session with the reviewers later
+	candidate.ChallengeURL = challengeURL
+	candidate.SentBy = r.icb.User.ID
+	candidate.ReviewerIDs = make([]string, 0, len(reviewers))
+	for _, reviewer := range reviewers {
+		candidate.ReviewerIDs = append(candidate.ReviewerIDs, reviewer.SlackID)
+	}
+	err = models.UpdateCandidate(r.ctx.Env, candidate)
+	if err != nil {
+		log.Println("[ERROR] Could not update candidate in db ", err)
+	}
 }
 
 func (r request) handleNewChallenge() error {
@@ -118,14 +182,65 @@ func (r request) updateChallenge(challenge models.Challenge) {
 	err := models.UpdateChallenge(r.ctx.Env, challenge)
 	if err != nil {
 		log.Println("[ERROR] Could not update challenge in db ", err)
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption("We were not able to create the new challenge"))
+		r.reply(toMsgOption("We were not able to create the new challenge"))
 	}
 
 	challengeSetup, err := models.GetChallengeSetupByName(r.ctx.Env, challenge.Name)
 	if err != nil {
 		log.Println("[ERROR] Could not create a valid challenge setup, perhaps the github repo name is not valid ", err)
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption("We were not able to create a valid challenge"))
+		r.reply(toMsgOption("We were not able to create a valid challenge"))
 	}
 	msgText := fmt.Sprintf("We created a challenge named %s in our database. It is pointing to: %s", challengeSetup.Name, challengeSetup.TemplateRepositoryURL())
-	r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(msgText))
+	r.reply(toMsgOption(msgText))
+}
+
+func (r request) handleDeleteChallenge(answer string) error {
+	challengeID := r.icb.ActionCallback.BlockActions[0].Value
+	switch answer {
+	case confirmDelete:
+		go r.deleteChallenge(challengeID)
+		return nil
+	case keepChallenge:
+		return r.update(toMsgOption("The challenge is kept."))
+	default:
+		return fmt.Errorf("[ERROR] Unknown answer to deleting the challenge - %s", answer)
+	}
+}
+
+// deleteChallenge removes the challenge from its reviewers and deletes it, checking again that nothing is in flight
+func (r request) deleteChallenge(challengeID string) {
+	challenge, err := models.GetChallengeSetupByID(r.ctx.Env, challengeID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find the challenge - ", err)
+		r.update(toMsgOption("Cannot find the challenge, it may have been deleted already."))
+		return
+	}
+	if reason := challengeDeletionBlocker(r.ctx, challenge); reason != "" {
+		r.update(toMsgOption(reason))
+		return
+	}
+
+	reviewers, err := models.GetAllReviewersForChallenge(r.ctx.Env, challenge.ID)
+	if err != nil {
+		log.Println("[ERROR] Cannot get the reviewers of the challenge - ", err)
+		r.reply(toMsgOption("There was an error. The reviewers of the challenge cannot be found."))
+		return
+	}
+	for _, reviewer := range reviewers {
+		err = models.UpdateReviewer(r.ctx.Env, reviewer.DropChallenge(challenge.ID))
+		if err != nil {
+			log.Println("[ERROR] Could not update reviewer in db ", reviewer.Name, err)
+			r.reply(toMsgOption(fmt.Sprintf("We were not able to remove %s from <@%s>, please try again.", challenge.Name, reviewer.SlackID)))
+			return
+		}
+	}
+
+	err = models.DeleteChallenge(r.ctx.Env, challenge.ID)
+	if err != nil {
+		log.Println("[ERROR] Could not delete challenge in db ", err)
+		r.reply(toMsgOption("We were not able to delete the challenge"))
+		return
+	}
+	msg := fmt.Sprintf("<@%s> deleted the challenge %s, it is removed from its %d reviewers.", r.icb.User.ID, challenge.Name, len(reviewers))
+	r.update(toMsgOption(msg))
 }
@@ -0,0 +1,116 @@
+package slackops
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/scheduling"
+	"github.com/nlopes/slack"
+)
+
+// Number of alternative reviewers suggested to the booker when a booking is declined
+const maxAlternatives = 3
+
+// notifyReviewer DMs the reviewer when someone else books or unbooks them
+func (r request) notifyReviewer(reviewer models.Reviewer, booking models.Booking, isBooked bool) {
+	if reviewer.SlackID == r.icb.User.ID {
+		return
+	}
+
+	challenge, err := models.GetChallengeSetupByID(r.ctx.Env, bookingChallengeID(reviewer, booking))
+	if err != nil {
+		log.Println("[ERROR] Invalid challenge for reviewer", err)
+	}
+	loc := models.LoadLocation(reviewer.TimeZone)
+	sections := renderBookingNotice(reviewer, booking, challenge, isBooked, r.icb.User.ID, loc)
+
+	err = r.ctx.postMessage(reviewer.SlackID, slack.MsgOptionBlocks(sections...))
+	if err != nil {
+		log.Println("[ERROR] Cannot notify the reviewer - ", reviewer.Name, err)
+	}
+}
+
+func (r request) handleDeclineBooking(encodedActionInfo string) error {
+	scheduleInfo, err := decodeScheduleActionInfo(encodedActionInfo)
+	if err != nil {
+		log.Println("[ERROR] Cannot decode schedule info - ", err)
+		return err
+	}
+	bookingID := r.icb.ActionCallback.BlockActions[0].Value
+
+	go r.declineBooking(scheduleInfo, bookingID)
+	return nil
+}
+
+// declineBooking frees the slot of the reviewer and tells the booker, with the reviewers that could take over
+func (r request) declineBooking(scheduleInfo scheduleActionInfo, bookingID string) {
+	if r.icb.User.ID != scheduleInfo.ReviewerID {
+		r.reply(toMsgOption("Only the booked reviewer can decline the booking."))
+		return
+	}
+
+	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, scheduleInfo.ReviewerID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		return
+	}
+
+	occurrence, err := scheduleInfo.occurrence()
+	if err != nil {
+		log.Println("[ERROR] Cannot decode slot occurrence - ", err)
+		return
+	}
+
+	booking, ok := scheduling.GetBooking(reviewer, occurrence)
+	if !ok || booking.ID != bookingID {
+		r.update(toMsgOption("This booking was already cancelled."))
+		return
+	}
+
+	_, err = scheduling.UpdateReviewerBooking(r.ctx.Env, reviewer, scheduling.SlotBooking{
+		Occurrence: occurrence,
+		IsBooked:   false,
+	})
+	if err != nil {
+		log.Println("[ERROR] Update booking not successful - ", err)
+		r.reply(toMsgOption("There was an error. Booking cannot be declined."))
+		return
+	}
+
+	challengeID := bookingChallengeID(reviewer, booking)
+	challenge, err := models.GetChallengeSetupByID(r.ctx.Env, challengeID)
+	if err != nil {
+		log.Println("[ERROR] Invalid challenge for reviewer", err)
+	}
+	loc := models.LoadLocation(reviewer.TimeZone)
+	msg := fmt.Sprintf("You declined the booking for %s: %s", renderOccurrence(challenge, occurrence, loc), renderBookingPurpose(booking))
+	r.update(toMsgOption(msg))
+	backfill(r.ctx, challengeID, reviewer.SlackID, occurrence)
+
+	if booking.BookedBy == "" {
+		return
+	}
+
+	alternatives, err := scheduling.AlternativeReviewers(r.ctx.Env, challengeID, occurrence, reviewer.SlackID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find alternative reviewers - ", err)
+	}
+	if len(alternatives) > maxAlternatives {
+		alternatives = alternatives[:maxAlternatives]
+	}
+
+	bookerLoc := r.ctx.getUserLocation(booking.BookedBy)
+	sections := renderDeclinedBooking(reviewer, booking, challenge, alternatives, bookerLoc)
+	err = r.ctx.postMessage(booking.BookedBy, slack.MsgOptionBlocks(sections...))
+	if err != nil {
+		log.Println("[ERROR] Cannot notify the booker - ", err)
+	}
+}
+
+func bookingChallengeID(reviewer models.Reviewer, booking models.Booking) string {
+	if booking.ChallengeID != "" {
+		return booking.ChallengeID
+	}
+	return reviewer.ChallengeID
+}
@@ -0,0 +1,129 @@
+package slackops
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/keremk/challenge-bot/calendar"
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/scheduling"
+	"github.com/nlopes/slack"
+)
+
+const (
+	saveImport   = "save"
+	cancelImport = "cancel"
+)
+
+func (r request) handleImportCalendar() error {
+	input := r.icb.Submission
+	weeks, err := strconv.Atoi(input["weeks"])
+	if err != nil || weeks < 1 {
+		weeks = defaultImportWeeks
+	}
+
+	go r.importCalendar(r.icb.State, input["calendar_url"], weeks)
+	return nil
+}
+
+func (r request) importCalendar(reviewerSlackID string, calendarURL string, weeks int) {
+	r.reply(importCalendar(r.ctx, reviewerSlackID, calendarURL, weeks, r.icb.User.ID))
+}
+
+// importCalendar finds the available slots of the reviewer that conflict with the calendar file shared in Slack, and
+// keeps them until the user saves or cancels the import. It returns the reply to the user.
+func importCalendar(ctx commCtx, reviewerSlackID string, fileLink string, weeks int, userID string) slack.MsgOption {
+	reviewer, err := models.GetReviewerBySlackID(ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", reviewerSlackID)
+		return toMsgOption(errorMsg)
+	}
+
+	challenge, err := models.GetChallengeSetupByID(ctx.Env, reviewer.ChallengeID)
+	if err != nil {
+		log.Println("[ERROR] Invalid challenge for reviewer", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> does not seem to have a valid challenge they registered. Please use /reviewer edit to register a challenge.", reviewerSlackID)
+		return toMsgOption(errorMsg)
+	}
+
+	contents, err := ctx.downloadFile(fileLink)
+	if err != nil {
+		log.Println("[ERROR] Cannot download the calendar - ", err)
+		return toMsgOption("Cannot download the calendar file. Please upload it to Slack, e.g. in a DM to the app, and use the link of the file.")
+	}
+
+	now := time.Now()
+	weekStart := scheduling.FirstDayOfWeek(now)
+	// Slots in the challenge time zone can start up to a day before the UTC week starts
+	from := weekStart.AddDate(0, 0, -1)
+	to := weekStart.AddDate(0, 0, 7*weeks+1)
+	busy, err := calendar.ParseBusy(bytes.NewReader(contents), from, to, models.LoadLocation(reviewer.TimeZone))
+	if err != nil {
+		log.Println("[ERROR] Cannot parse the calendar - ", err)
+		return toMsgOption("The file is not a valid iCalendar (.ics) file.")
+	}
+
+	busySlots := scheduling.FindBusySlots(reviewer, challenge, busy, weekStart, weeks, now)
+	if len(busySlots) == 0 {
+		msg := fmt.Sprintf("The calendar of <@%s> has no conflicts with their available slots in the next %d weeks.", reviewer.SlackID, weeks)
+		return toMsgOption(msg)
+	}
+
+	reviewer.PendingImport = make([]models.SlotOccurrence, 0, len(busySlots))
+	for _, busySlot := range busySlots {
+		reviewer.PendingImport = append(reviewer.PendingImport, busySlot.Occurrence)
+	}
+	err = models.UpdateReviewer(ctx.Env, reviewer)
+	if err != nil {
+		log.Println("[ERROR] Cannot save the pending import - ", err)
+		return toMsgOption("There was an error. The calendar cannot be imported.")
+	}
+
+	loc := ctx.getUserLocation(userID)
+	return slack.MsgOptionBlocks(renderImportDiff(reviewer, challenge, busySlots, loc)...)
+}
+
+func (r request) handleImportDecision(encodedActionInfo string) error {
+	reviewerSlackID, decision, err := decodeRuleActionInfo(encodedActionInfo)
+	if err != nil {
+		log.Println("[ERROR] Cannot decode import action - ", err)
+		return err
+	}
+
+	go r.finishImport(reviewerSlackID, decision)
+	return nil
+}
+
+func (r request) finishImport(reviewerSlackID, decision string) {
+	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		return
+	}
+	if len(reviewer.PendingImport) == 0 {
+		r.update(toMsgOption("This import is not pending anymore."))
+		return
+	}
+
+	pending := reviewer.PendingImport
+	reviewer.PendingImport = nil
+
+	var msg string
+	if decision == saveImport {
+		reviewer, err = scheduling.MarkUnavailable(r.ctx.Env, reviewer, pending)
+		msg = fmt.Sprintf("<@%s> is now unavailable for %d slots that conflict with their calendar.", reviewer.SlackID, len(pending))
+	} else {
+		err = models.UpdateReviewer(r.ctx.Env, reviewer)
+		msg = fmt.Sprintf("The calendar import for <@%s> is cancelled, their availability did not change.", reviewer.SlackID)
+	}
+	if err != nil {
+		log.Println("[ERROR] Cannot finish the import - ", err)
+		r.reply(toMsgOption("There was an error. Availability cannot be updated."))
+		return
+	}
+	r.update(toMsgOption(msg))
+}
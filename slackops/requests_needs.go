@@ -0,0 +1,296 @@
+package slackops
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/scheduling"
+	"github.com/nlopes/slack"
+)
+
+// errOfferTaken is returned when an offer is answered after the need was filled, cancelled or the offer expired
+var errOfferTaken = errors.New("[ERROR] The offer is not open anymore")
+
+func (r request) handleNewNeed() error {
+	input := r.icb.Submission
+	input["created_by"] = r.icb.User.ID
+
+	weekStart, err := decodeWeek(input["year_week"])
+	if err != nil {
+		weekStart = scheduling.FirstDayOfWeek(time.Now())
+	}
+	need, err := models.NewNeed(input, weekStart)
+	if err != nil {
+		log.Println("[ERROR] Invalid need - ", err)
+		return inputErrorsOf(err)
+	}
+
+	go r.registerNeed(need)
+	return nil
+}
+
+func (r request) registerNeed(need models.Need) {
+	err := models.UpdateNeed(r.ctx.Env, need)
+	if err != nil {
+		log.Println("[ERROR] Could not update need in db ", err)
+		r.reply(toMsgOption("There was an error. The need cannot be registered."))
+		return
+	}
+
+	weekStart, _ := time.Parse(models.DateFormat, need.WeekStart)
+	msg := fmt.Sprintf("Looking for %d reviewer(s) for %s in the week of %s. Matching reviewers are offered a slot, you will be told when they accept. Use /reviewer needs to see the needs.",
+		need.Count, need.CandidateName, scheduling.WeekDescription(weekStart))
+	r.reply(toMsgOption(msg))
+
+	need = offerNeed(r.ctx, need)
+	if len(need.OpenOffers()) == 0 {
+		errorMsg := fmt.Sprintf("No reviewer is available for %s right now. They are offered a slot as soon as one opens.", need.CandidateName)
+		r.reply(toMsgOption(errorMsg))
+	}
+}
+
+// offerNeed offers a slot to the best matching reviewers for the reviewers still needed, and saves the offers
+func offerNeed(ctx commCtx, need models.Need) models.Need {
+	challenge, err := models.GetChallengeSetupByID(ctx.Env, need.ChallengeID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find the challenge of the need - ", need.ChallengeID, err)
+		return need
+	}
+	reviewers, err := models.GetAllReviewersForChallenge(ctx.Env, challenge.ID)
+	if err != nil {
+		log.Println("[ERROR] No reviewers for the challenge - ", challenge.Name)
+		return need
+	}
+	taxonomy, err := models.GetTaxonomy(ctx.Env)
+	if err != nil {
+		log.Println("[ERROR] Cannot load the tags, matching technologies by name - ", err)
+	}
+
+	matches := scheduling.NeedOffers(reviewers, challenge, need, taxonomy, time.Now())
+	if len(matches) == 0 {
+		return need
+	}
+	offers := make([]models.NeedOffer, 0, len(matches))
+	for _, match := range matches {
+		loc := models.LoadLocation(match.Reviewer.TimeZone)
+		sections := renderNeedOffer(need, challenge, match.Reviewer.SlackID, match.Occurrence, loc)
+		channel, timestamp, err := ctx.postMessageTs(match.Reviewer.SlackID, slack.MsgOptionBlocks(sections...))
+		if err != nil {
+			log.Println("[ERROR] Cannot offer the slot to - ", match.Reviewer.Name, err)
+			continue
+		}
+		offers = append(offers, models.NeedOffer{
+			ReviewerID: match.Reviewer.SlackID,
+			Occurrence: match.Occurrence,
+			Status:     models.OfferOpen,
+			Channel:    channel,
+			Timestamp:  timestamp,
+		})
+	}
+
+	changed, err := models.ChangeNeed(ctx.Env, need.ID, func(need models.Need) (models.Need, error) {
+		for _, offer := range offers {
+			need = need.AddOffer(offer)
+		}
+		return need, nil
+	})
+	if err != nil {
+		log.Println("[ERROR] Could not update need in db ", err)
+		return need
+	}
+	return changed
+}
+
+// backfill is called when a booking is cancelled. A need the booking was for looks for another reviewer, and the
+// needs in the same week are offered to the reviewers that have room again.
+func backfill(ctx commCtx, challengeID string, reviewerID string, occurrence models.SlotOccurrence) {
+	needs, err := models.GetCurrentNeeds(ctx.Env, time.Now())
+	if err != nil {
+		log.Println("[ERROR] Cannot load the needs - ", err)
+		return
+	}
+
+	for _, need := range scheduling.NeedsInWeek(needs, challengeID, occurrence) {
+		released := false
+		need, err := models.ChangeNeed(ctx.Env, need.ID, func(need models.Need) (models.Need, error) {
+			need, released = need.ReleaseBooking(reviewerID, occurrence)
+			return need, nil
+		})
+		if err != nil {
+			log.Println("[ERROR] Could not update need in db ", err)
+			continue
+		}
+		if released {
+			msg := fmt.Sprintf("<@%s>'s booking for %s on %s was cancelled, looking for another reviewer.",
+				reviewerID, need.CandidateName, occurrence.Date)
+			ctx.postMessage(need.CreatedBy, toMsgOption(msg))
+		}
+		if need.Remaining() > 0 {
+			offerNeed(ctx, need)
+		}
+	}
+}
+
+func (r request) handleNeedOffer(encodedActionInfo string) error {
+	reviewerSlackID, answer, err := decodeRuleActionInfo(encodedActionInfo)
+	if err != nil {
+		log.Println("[ERROR] Cannot decode the offer - ", err)
+		return err
+	}
+	needID := r.icb.ActionCallback.BlockActions[0].Value
+
+	go r.answerOffer(needID, reviewerSlackID, answer)
+	return nil
+}
+
+// answerOffer books the reviewer if the need still needs reviewers, or offers the slot to the next reviewer if they pass
+func (r request) answerOffer(needID, reviewerSlackID, answer string) {
+	if r.icb.User.ID != reviewerSlackID {
+		r.reply(toMsgOption("Only the reviewer the slot is offered to can answer."))
+		return
+	}
+
+	// The reviewer that accepts claims a place in the need before they are booked, so the need is never overbooked
+	var offer models.NeedOffer
+	need, err := models.ChangeNeed(r.ctx.Env, needID, func(need models.Need) (models.Need, error) {
+		var ok bool
+		offer, ok = need.Offer(reviewerSlackID)
+		if !ok || offer.Status != models.OfferOpen || !need.IsCurrent(time.Now()) || need.Remaining() == 0 {
+			return need, errOfferTaken
+		}
+		if answer == passOffer {
+			return need.SetOfferStatus(reviewerSlackID, models.OfferPassed), nil
+		}
+		return need.Accept(offer), nil
+	})
+	if err == errOfferTaken {
+		r.update(toMsgOption("Thanks, but this slot was taken by another reviewer in the meantime."))
+		return
+	}
+	if err != nil {
+		log.Println("[ERROR] Cannot answer the offer - ", err)
+		r.update(toMsgOption("This offer is not valid anymore."))
+		return
+	}
+
+	if answer == passOffer {
+		r.update(toMsgOption(fmt.Sprintf("You passed on reviewing %s.", need.CandidateName)))
+		offerNeed(r.ctx, need)
+		return
+	}
+
+	reviewer, booking, err := r.bookOffer(need, offer)
+	if err != nil {
+		// The reviewer cannot take the slot anymore, so their place is released and the next reviewer is asked
+		need, err = models.ChangeNeed(r.ctx.Env, needID, func(need models.Need) (models.Need, error) {
+			need, _ = need.ReleaseBooking(offer.ReviewerID, offer.Occurrence)
+			return need.SetOfferStatus(reviewerSlackID, models.OfferPassed), nil
+		})
+		if err != nil {
+			log.Println("[ERROR] Could not update need in db ", err)
+			return
+		}
+		offerNeed(r.ctx, need)
+		return
+	}
+
+	if need.Remaining() == 0 {
+		var expired []models.NeedOffer
+		need, err = models.ChangeNeed(r.ctx.Env, needID, func(need models.Need) (models.Need, error) {
+			expired = need.OpenOffers()
+			return need.ExpireOffers(), nil
+		})
+		if err != nil {
+			log.Println("[ERROR] Could not update need in db ", err)
+		}
+		for _, other := range expired {
+			r.ctx.updateMessage(other.Channel, other.Timestamp, toMsgOption(fmt.Sprintf("Thanks, %s has all the reviewers needed now.", need.CandidateName)))
+		}
+	}
+
+	msg := fmt.Sprintf("You are booked for %s on %s: %s", need.CandidateName, offer.Occurrence.Date, renderBookingPurpose(booking))
+	r.update(toMsgOption(msg))
+
+	coordinatorMsg := fmt.Sprintf("<@%s|%s> accepted to review %s on %s, %d of %d reviewers booked.",
+		reviewer.SlackID, reviewer.Name, need.CandidateName, offer.Occurrence.Date, len(need.Booked), need.Count)
+	r.ctx.postMessage(need.CreatedBy, toMsgOption(coordinatorMsg))
+}
+
+func (r request) bookOffer(need models.Need, offer models.NeedOffer) (models.Reviewer, models.Booking, error) {
+	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, offer.ReviewerID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		return reviewer, models.Booking{}, err
+	}
+	reviewer, err = reviewer.ForChallenge(need.ChallengeID)
+	if err != nil {
+		log.Println("[ERROR] Reviewer does not review the challenge - ", err)
+		return reviewer, models.Booking{}, err
+	}
+
+	if scheduling.IsBooked(reviewer, offer.Occurrence) || !scheduling.IsAvailable(reviewer, offer.Occurrence) {
+		msg := fmt.Sprintf("Sorry, you are not free on %s anymore, the slot is offered to another reviewer.", offer.Occurrence.Date)
+		r.update(toMsgOption(msg))
+		return reviewer, models.Booking{}, fmt.Errorf("[ERROR] Reviewer is not free for the offered slot - %s", offer.Occurrence.Key())
+	}
+
+	booking := models.NewBooking(reviewer.SlackID, offer.Occurrence, map[string]string{
+		"candidate_name": need.CandidateName,
+		"challenge_id":   need.ChallengeID,
+		"booked_by":      need.CreatedBy,
+		"kind":           need.Kind,
+	})
+	reviewer, err = scheduling.UpdateReviewerBooking(r.ctx.Env, reviewer, scheduling.SlotBooking{
+		Occurrence: offer.Occurrence,
+		IsBooked:   true,
+		Booking:    booking,
+	})
+	if err != nil {
+		var msg string
+		switch err.(type) {
+		case scheduling.MaxBookingsError, scheduling.MaxMonthlyBookingsError, scheduling.CooldownError:
+			msg = fmt.Sprintf("%s The slot is offered to another reviewer.", capacityMessage(reviewer, err))
+		default:
+			log.Println("[ERROR] Update booking not successful - ", err)
+			msg = "There was an error. The booking cannot be made, the slot is offered to another reviewer."
+		}
+		r.update(toMsgOption(msg))
+		return reviewer, booking, err
+	}
+	return reviewer, booking, nil
+}
+
+func (r request) handleCancelNeed(needID string) error {
+	go r.cancelNeed(needID)
+	return nil
+}
+
+// cancelNeed stops offering slots for the need, the reviewers that accepted stay booked
+func (r request) cancelNeed(needID string) {
+	var expired []models.NeedOffer
+	need, err := models.ChangeNeed(r.ctx.Env, needID, func(need models.Need) (models.Need, error) {
+		expired = need.OpenOffers()
+		need = need.ExpireOffers()
+		need.Cancelled = true
+		return need, nil
+	})
+	if err != nil {
+		log.Println("[ERROR] Cannot cancel the need - ", err)
+		return
+	}
+
+	for _, offer := range expired {
+		r.ctx.updateMessage(offer.Channel, offer.Timestamp, toMsgOption(fmt.Sprintf("Thanks, reviewers for %s are not needed anymore.", need.CandidateName)))
+	}
+
+	needs, err := models.GetCurrentNeeds(r.ctx.Env, time.Now())
+	if err != nil {
+		log.Println("[ERROR] Cannot load the needs - ", err)
+		return
+	}
+	sections := renderNeeds(needs, needChallengeNames(r.ctx, needs))
+	r.update(slack.MsgOptionBlocks(sections...))
+}
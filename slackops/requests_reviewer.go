@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"time"
 
 	"github.com/keremk/challenge-bot/models"
 	"github.com/keremk/challenge-bot/scheduling"
@@ -20,38 +21,53 @@ func (r request) handleNewReviewer() error {
 	return nil
 }
 
-func (r request) createNewReviewer(reviewerSlackID string, input map[string]string)  {
+func (r request) createNewReviewer(reviewerSlackID string, input map[string]string) {
 	user, err := r.ctx.getUserInfo(reviewerSlackID)
 	if err != nil {
 		log.Println("[ERROR] Could not update reviewer in db ", err)
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption("Cannot find the reviewer in Slack, please make sure reviewer is a Slack member"))
+		r.reply(toMsgOption("Cannot find the reviewer in Slack, please make sure reviewer is a Slack member"))
 	}
 
-	reviewer := models.NewReviewer(user.Name, input)
+	input["time_zone"] = user.TZ
+	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, reviewerSlackID)
+	if err == nil {
+		// Registered reviewers join another challenge
+		r.joinChallenge(reviewer, input)
+		return
+	}
+	reviewer = models.NewReviewer(user.Name, input)
 	// log.Println("[INFO] Reviewer is ", reviewer)
 
 	err = models.UpdateReviewer(r.ctx.Env, reviewer)
 	if err != nil {
 		log.Println("[ERROR] Could not update reviewer in db ", err)
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption("We were not able to create the new reviewer"))
+		r.reply(toMsgOption("We were not able to create the new reviewer"))
 	}
 
 	msgText := fmt.Sprintf("We created a reviewer <@%s> in our database. Their Github alias is: %s", reviewer.SlackID, reviewer.GithubAlias)
-	r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(msgText))
+	r.reply(toMsgOption(msgText))
+	postTagPicker(r.ctx, r.reply, reviewer, reviewer.ChallengeID)
 }
 
 func (r request) handleEditReviewer() error {
-	reviewer, err := models.EditReviewer(r.ctx.Env, r.icb.State, r.icb.Submission)
+	input := r.icb.Submission
+	user, err := r.ctx.getUserInfo(r.icb.State)
+	if err == nil {
+		input["time_zone"] = user.TZ
+	}
+
+	reviewer, err := models.EditReviewer(r.ctx.Env, r.icb.State, input)
 	// log.Println("[INFO] Reviewer is ", reviewer)
 
 	if err != nil {
 		log.Println("[ERROR] Could not update reviewer in db ", err)
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption("We were not able to create the new reviewer"))
+		r.reply(toMsgOption("We were not able to create the new reviewer"))
 		return err
 	}
 
 	msgText := fmt.Sprintf("We edited the reviewer <@%s> in our database.", reviewer.SlackID)
-	r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(msgText))
+	r.reply(toMsgOption(msgText))
+	go postTagPicker(r.ctx, r.reply, reviewer, reviewer.ChallengeID)
 	return nil
 }
 
@@ -59,55 +75,71 @@ func (r request) handleShowSchedule() error {
 	scheduleInput := r.icb.Submission
 	log.Println("[INFO] Reviewer data", scheduleInput)
 
-	week, year := decodeWeekAndYear(scheduleInput["year_week"])
+	week := scheduleInput["year_week"]
+	if week == "" {
+		week = generalWeek
+	}
 	log.Println("[INFO] Week ", week)
 
 	reviewerSlackID := r.icb.State
 	log.Println("[INFO] Reviewer ID", reviewerSlackID)
 
-	go r.showSchedule(week, year, reviewerSlackID)
+	go r.showSchedule(week, reviewerSlackID, scheduleInput["challenge_id"])
 
 	return nil
 }
 
-func (r request) showSchedule(week, year int, reviewerSlackID string) {
+// showSchedule shows the schedule for the slots of the challenge, the reviewer's first challenge if it is empty
+func (r request) showSchedule(week string, reviewerSlackID string, challengeID string) {
 	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, reviewerSlackID)
 	// log.Println("INFO: Reviewer - ", reviewer)
 	// log.Println("INFO: Error - ", err)
 	if err != nil {
 		log.Println("[ERROR] No such reviewer registered.", err)
 		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", reviewerSlackID)
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(errorMsg))
+		r.reply(toMsgOption(errorMsg))
 		return
 	}
 
-	challenge, err := models.GetChallengeSetupByName(r.ctx.Env, reviewer.ChallengeName)
+	if challengeID == "" {
+		challengeID = reviewer.ChallengeID
+	}
+	challenge, err := models.GetChallengeSetupByID(r.ctx.Env, challengeID)
 	if err != nil {
 		log.Println("[ERROR] Reviewer did not register to a challenge.", err)
 		errorMsg := fmt.Sprintf("Reviewer <%s> did not register for a specific challenge.", reviewer.Name)
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(errorMsg))
+		r.reply(toMsgOption(errorMsg))
 		return
 	}
 
-	slots := scheduling.SlotsForWeek(week, year, reviewer, challenge)
+	var slots []scheduling.SlotInfo
+	var weekDescription string
+	weekStart := scheduling.FirstDayOfWeek(time.Now())
+	if week == generalWeek {
+		slots = scheduling.GeneralSlots(reviewer, challenge)
+		weekDescription = "General"
+	} else {
+		weekStart, err = decodeWeek(week)
+		if err != nil {
+			log.Println("[ERROR] Week not valid", err)
+			return
+		}
+		slots = scheduling.SlotsForWeek(weekStart, reviewer, challenge)
+		weekDescription = scheduling.WeekDescription(weekStart)
+	}
 	// log.Println("[INFO] Slots available: ", slots)
 	// log.Println("[INFO] Reviewer is ", reviewer)
 
-	weekDescription, err := scheduling.WeekDescriptionFromWeekNo(week, year)
-	if err != nil {
-		log.Println("[ERROR] Week number not valid", err)
-		return
-	}
-
-	headerMsgText := fmt.Sprintf("<@%s>'s schedule in %s", reviewer.SlackID, weekDescription)
-	err = r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(headerMsgText))
+	headerMsgText := fmt.Sprintf("<@%s>'s %s schedule in %s", reviewer.SlackID, challenge.Name, weekDescription)
+	err = r.reply(toMsgOption(headerMsgText))
 	if err != nil {
 		log.Println("[ERROR] Cannot send the reviewer schedule header - ", err)
 		return
 	}
 
-	scheduleMsgBlock := renderSchedule(week, year, reviewer, slots)
-	err = r.ctx.postMessage(r.icb.Channel.ID, slack.MsgOptionBlocks(&scheduleMsgBlock))
+	loc := r.ctx.getUserLocation(r.icb.User.ID)
+	scheduleMsgBlock := renderSchedule(weekStart, reviewer, slots, loc)
+	err = r.reply(slack.MsgOptionBlocks(&scheduleMsgBlock))
 	if err != nil {
 		log.Println("[ERROR] Cannot send the reviewer schedule details - ", err)
 	}
@@ -135,39 +167,58 @@ func (r request) updateSchedule(slotChecked bool, scheduleInfo scheduleActionInf
 	if err != nil {
 		log.Println("[ERROR] No such reviewer registered.", err)
 		errorMsg := fmt.Sprintf("Reviewer <%s> is not registered.", scheduleInfo.ReviewerID)
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(errorMsg))
+		r.reply(toMsgOption(errorMsg))
+		return
 	}
 	// log.Println("[INFO] Reviewer is - ", reviewer)
 
-	challenge, err := models.GetChallengeSetupByName(r.ctx.Env, reviewer.ChallengeName)
+	reviewer, challenge, err := scheduling.ChallengeOfSlot(r.ctx.Env, reviewer, scheduleInfo.SlotID)
 	if err != nil {
 		log.Println("[ERROR] Reviewer did not register to a challenge.", err)
 		errorMsg := fmt.Sprintf("Reviewer <%s> did not register for a specific challenge.", reviewer.Name)
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(errorMsg))
+		r.reply(toMsgOption(errorMsg))
+		return
 	}
 	// log.Println("[INFO] Challenge is - ", challenge)
 
 	slotChecked = !slotChecked
 
-	reviewer, err = scheduling.UpdateReviewerAvailability(r.ctx.Env, reviewer, scheduling.SlotReference{
-		SlotID:    scheduleInfo.SlotID,
-		WeekNo:    scheduleInfo.WeekNo,
-		Year:      scheduleInfo.Year,
-		Available: slotChecked,
-	})
+	var slots []scheduling.SlotInfo
+	weekStart := scheduling.FirstDayOfWeek(time.Now())
+	if scheduleInfo.isGeneral() {
+		reviewer, err = scheduling.UpdateGeneralAvailability(r.ctx.Env, reviewer, scheduleInfo.SlotID, slotChecked)
+		slots = scheduling.GeneralSlots(reviewer, challenge)
+	} else {
+		var occurrence models.SlotOccurrence
+		occurrence, err = scheduleInfo.occurrence()
+		if err != nil {
+			log.Println("[ERROR] Cannot decode slot occurrence - ", err)
+			return
+		}
+		reviewer, err = scheduling.UpdateReviewerAvailability(r.ctx.Env, reviewer, scheduling.SlotReference{
+			Occurrence: occurrence,
+			Available:  slotChecked,
+		})
+		weekStart, _ = scheduling.WeekOfOccurrence(occurrence)
+		slots = scheduling.SlotsForWeek(weekStart, reviewer, challenge)
+	}
 	if err != nil {
 		log.Println("[ERROR] Update availability not successful - ", err)
 		errorMsg := fmt.Sprintf("There was an error. Availability cannot be updated.")
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(errorMsg))
+		r.reply(toMsgOption(errorMsg))
 	}
 	// log.Println("[INFO] Updated reviewer is - ", reviewer)
 
-	slots := scheduling.SlotsForWeek(scheduleInfo.WeekNo, scheduleInfo.Year, reviewer, challenge)
-	scheduleMsgBlock := renderSchedule(scheduleInfo.WeekNo, scheduleInfo.Year, reviewer, slots)
+	loc := r.ctx.getUserLocation(r.icb.User.ID)
+	scheduleMsgBlock := renderSchedule(weekStart, reviewer, slots, loc)
 
 	msg := slack.MsgOptionBlocks(&scheduleMsgBlock)
 
-	r.ctx.updateMessage(r.icb.Channel.ID, r.icb.Message.Timestamp, msg)
+	if r.fromHome() {
+		r.ctx.publishHome(r.icb.User.ID)
+		return
+	}
+	r.update(msg)
 
 	// respJSON, err := json.Marshal(scheduleMsgBlock)
 	// if err != nil {
@@ -180,33 +231,37 @@ func (r request) handleFindReviewers() error {
 	scheduleInput := r.icb.Submission
 	// log.Println("[INFO] Reviewer data", scheduleInput)
 
-	week, year := decodeWeekAndYear(scheduleInput["year_week"])
+	weekStart, err := decodeWeek(scheduleInput["year_week"])
+	if err != nil {
+		weekStart = scheduling.FirstDayOfWeek(time.Now())
+	}
 	day := scheduleInput["day"]
 	// log.Println("[INFO] Day ", day)
-	// log.Println("[INFO] Week ", week)
+	// log.Println("[INFO] Week ", weekStart)
 
 	challengeName := scheduleInput["challenge_name"]
 	technology := scheduleInput["technology"]
 
-	go r.findAvailableReviewers(challengeName, technology, day, week, year)
+	go r.findAvailableReviewers(challengeName, technology, day, weekStart)
 
 	return nil
 }
 
-func (r request) findAvailableReviewers(challengeName, technology, day string, week, year int) {
-	availableReviewers, err := scheduling.FindAvailableReviewers(r.ctx.Env, challengeName, technology, week, year)
+func (r request) findAvailableReviewers(challengeName, technology, day string, weekStart time.Time) {
+	availableReviewers, err := scheduling.FindAvailableReviewers(r.ctx.Env, challengeName, technology, weekStart)
 	if err != nil {
 		log.Println("[ERROR] Found no results", err)
 	}
 
 	scheduleInfo := availableReviewers[day]
 	if scheduleInfo == nil {
-		errorMsg := fmt.Sprintf("No reviewers available for %s on the week of %d, %d", day, week, year)
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(errorMsg))
+		errorMsg := fmt.Sprintf("No reviewers available for %s on the %s", day, scheduling.WeekDescription(weekStart))
+		r.reply(toMsgOption(errorMsg))
 	}
-	scheduleMsg := renderReviewers(week, year, scheduleInfo)
+	loc := r.ctx.getUserLocation(r.icb.User.ID)
+	scheduleMsg := renderReviewers(weekStart, scheduleInfo, loc)
 
-	r.ctx.postMessage(r.icb.Channel.ID, scheduleMsg)
+	r.reply(scheduleMsg)
 }
 
 func (r request) handleBookings(encodedActionInfo string) error {
@@ -222,45 +277,255 @@ func (r request) handleBookings(encodedActionInfo string) error {
 		return err
 	}
 
-	r.updateBooking(isBooked, scheduleInfo)
+	if !isBooked {
+		// Ask for the candidate and the kind of booking first
+		dialog := newBookingDialog(encodedActionInfo)
+		return r.ctx.openModal(r.icb.TriggerID, r.icb.Channel.ID, dialog)
+	}
+
+	r.updateBooking(isBooked, scheduleInfo, nil)
 	return nil
 }
 
-func (r request) updateBooking(isBooked bool, scheduleInfo scheduleActionInfo) {
+func (r request) handleBookReviewer() error {
+	scheduleInfo, err := decodeScheduleActionInfo(r.icb.State)
+	if err != nil {
+		log.Println("[ERROR] Cannot decode schedule info - ", err)
+		return err
+	}
+
+	input := r.icb.Submission
+	input["booked_by"] = r.icb.User.ID
+
+	go r.updateBooking(false, scheduleInfo, input)
+	return nil
+}
+
+// updateBooking toggles the booking, the input holds the booking details when booking
+func (r request) updateBooking(isBooked bool, scheduleInfo scheduleActionInfo, input map[string]string) {
 	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, scheduleInfo.ReviewerID)
 	if err != nil {
 		log.Println("[ERROR] No such reviewer registered.", err)
 		errorMsg := fmt.Sprintf("Reviewer <%s> is not registered.", scheduleInfo.ReviewerID)
-		r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(errorMsg))
+		r.reply(toMsgOption(errorMsg))
+		return
 	}
 	// log.Println("[INFO] Reviewer is - ", reviewer)
 	isBooked = !isBooked // Toggle booking
 
+	occurrence, err := scheduleInfo.occurrence()
+	if err != nil {
+		log.Println("[ERROR] Cannot decode slot occurrence - ", err)
+		return
+	}
+
+	// The capacity is per challenge, so book the reviewer for the challenge of the slot
+	reviewer, challenge, err := scheduling.ChallengeOfSlot(r.ctx.Env, reviewer, occurrence.SlotID)
+	if err != nil {
+		log.Println("[ERROR] Slot is not in a challenge of the reviewer - ", err)
+		errorMsg := fmt.Sprintf("Cannot find the challenge of the slot %s of <@%s>, the booking cannot be updated.", occurrence.SlotID, reviewer.SlackID)
+		r.reply(toMsgOption(errorMsg))
+		return
+	}
+
+	var booking models.Booking
+	if isBooked {
+		if input["challenge_id"] == "" {
+			input["challenge_id"] = challenge.ID
+		}
+		booking = models.NewBooking(reviewer.SlackID, occurrence, input)
+	} else {
+		// Keep the details of the cancelled booking for the reviewer's notification
+		booking, _ = scheduling.GetBooking(reviewer, occurrence)
+	}
+
 	reviewer, err = scheduling.UpdateReviewerBooking(r.ctx.Env, reviewer, scheduling.SlotBooking{
-		SlotID:   scheduleInfo.SlotID,
-		WeekNo:   scheduleInfo.WeekNo,
-		Year:     scheduleInfo.Year,
-		IsBooked: isBooked,
+		Occurrence: occurrence,
+		IsBooked:   isBooked,
+		Booking:    booking,
 	})
 	if err != nil {
 		switch err.(type) {
-		case scheduling.MaxBookingsError:
-			errorMsg := fmt.Sprintf("Reviewer can only be booked a maximum of %d times/week. Please unbook another appointment in that week.", reviewer.BookingsPerWeek)
-			r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(errorMsg))
+		case scheduling.MaxBookingsError, scheduling.MaxMonthlyBookingsError, scheduling.CooldownError:
+			errorMsg := fmt.Sprintf("%s Please unbook another appointment or pick another reviewer.", capacityMessage(reviewer, err))
+			r.reply(toMsgOption(errorMsg))
 			return
 		default:
 			log.Println("[ERROR] Update booking not successful - ", err)
 			errorMsg := fmt.Sprintf("There was an error. Booking cannot be updated.")
-			r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(errorMsg))
+			r.reply(toMsgOption(errorMsg))
 			return
 		}
 	}
 
 	var msg string
 	if isBooked {
-		msg = fmt.Sprintf("<@%s|%s> is now booked for the slot %s on week %d", reviewer.SlackID, reviewer.Name, scheduleInfo.SlotID, scheduleInfo.WeekNo)
+		msg = fmt.Sprintf("<@%s|%s> is now booked for the slot %s on %s: %s", reviewer.SlackID, reviewer.Name, occurrence.SlotID, occurrence.Date, renderBookingPurpose(booking))
 	} else {
-		msg = fmt.Sprintf("<@%s|%s> is now free for the slot %s on week %d", reviewer.SlackID, reviewer.Name, scheduleInfo.SlotID, scheduleInfo.WeekNo)
+		msg = fmt.Sprintf("<@%s|%s> is now free for the slot %s on %s", reviewer.SlackID, reviewer.Name, occurrence.SlotID, occurrence.Date)
+	}
+	r.reply(toMsgOption(msg))
+	r.notifyReviewer(reviewer, booking, isBooked)
+	if !isBooked {
+		backfill(r.ctx, bookingChallengeID(reviewer, booking), reviewer.SlackID, occurrence)
+	}
+	if r.fromHome() {
+		r.ctx.publishHome(r.icb.User.ID)
+	}
+}
+
+// capacityMessage explains why the reviewer's caps do not allow the booking
+func capacityMessage(reviewer models.Reviewer, err error) string {
+	switch e := err.(type) {
+	case scheduling.MaxBookingsError:
+		return fmt.Sprintf("<@%s> can only be booked a maximum of %d times/week.", reviewer.SlackID, reviewer.BookingsPerWeek)
+	case scheduling.MaxMonthlyBookingsError:
+		return fmt.Sprintf("<@%s> can only be booked a maximum of %d times/month.", reviewer.SlackID, reviewer.BookingsPerMonth)
+	case scheduling.CooldownError:
+		return fmt.Sprintf("<@%s> had a fully booked week and is cooling down until the %s.", reviewer.SlackID, scheduling.WeekDescription(e.Until))
+	default:
+		return err.Error()
+	}
+}
+
+func (r request) joinChallenge(reviewer models.Reviewer, input map[string]string) {
+	reviewer, err := models.EditReviewer(r.ctx.Env, reviewer.SlackID, input)
+	if err != nil {
+		log.Println("[ERROR] Could not update reviewer in db ", err)
+		r.reply(toMsgOption("We were not able to add the challenge to the reviewer"))
+		return
+	}
+
+	msgText := fmt.Sprintf("<@%s> is already a reviewer, they now review %d challenges. Use /reviewer challenges to see them.", reviewer.SlackID, len(reviewer.Challenges))
+	r.reply(toMsgOption(msgText))
+	postTagPicker(r.ctx, r.reply, reviewer, reviewer.ChallengeID)
+}
+
+func (r request) handleReviewerChallenge(encodedActionInfo string) error {
+	reviewerSlackID, operation, err := decodeRuleActionInfo(encodedActionInfo)
+	if err != nil {
+		log.Println("[ERROR] Cannot decode the reviewer challenge action - ", err)
+		return err
+	}
+	challengeID := r.icb.ActionCallback.BlockActions[0].Value
+
+	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		return err
+	}
+
+	switch operation {
+	case editMembership:
+		// The dialog needs the trigger ID before it expires, so it is opened right away
+		reviewer, err = reviewer.ForChallenge(challengeID)
+		if err != nil {
+			log.Println("[ERROR] Reviewer does not review the challenge - ", err)
+			return err
+		}
+		dialog := newEditReviewerDialog(reviewer, challengeOptions(r.ctx.Env, nil))
+		return r.ctx.openModal(r.icb.TriggerID, r.icb.Channel.ID, dialog)
+	case leaveMembership:
+		go r.leaveChallenge(reviewer, challengeID)
+		return nil
+	default:
+		return fmt.Errorf("[ERROR] Unknown reviewer challenge operation - %s", operation)
+	}
+}
+
+// leaveChallenge removes the challenge from the reviewer, unless they still have upcoming bookings for it
+func (r request) leaveChallenge(reviewer models.Reviewer, challengeID string) {
+	today := time.Now().Format(models.DateFormat)
+	for _, booking := range reviewer.Bookings {
+		if booking.ChallengeID == challengeID && booking.Occurrence.Date >= today {
+			errorMsg := fmt.Sprintf("<@%s> has upcoming bookings for the challenge, please unbook them first.", reviewer.SlackID)
+			r.reply(toMsgOption(errorMsg))
+			return
+		}
+	}
+
+	reviewer, err := reviewer.LeaveChallenge(challengeID)
+	if err != nil {
+		log.Println("[ERROR] Cannot leave the challenge - ", err)
+		errorMsg := fmt.Sprintf("<@%s> cannot leave their only challenge, use /reviewer edit to add another one first.", reviewer.SlackID)
+		r.reply(toMsgOption(errorMsg))
+		return
+	}
+	err = models.UpdateReviewer(r.ctx.Env, reviewer)
+	if err != nil {
+		log.Println("[ERROR] Could not update reviewer in db ", err)
+		r.reply(toMsgOption("We were not able to update the reviewer"))
+		return
+	}
+
+	sections := renderReviewerChallenges(reviewer, challengeNames(r.ctx.Env, reviewer))
+	r.update(slack.MsgOptionBlocks(sections...))
+}
+
+func (r request) handleRemoveReviewer(answer string) error {
+	reviewerSlackID := r.icb.ActionCallback.BlockActions[0].Value
+	switch answer {
+	case confirmRemove:
+		go r.removeReviewer(reviewerSlackID)
+		return nil
+	case keepReviewer:
+		return r.update(toMsgOption(fmt.Sprintf("<@%s> is kept.", reviewerSlackID)))
+	default:
+		return fmt.Errorf("[ERROR] Unknown answer to removing the reviewer - %s", answer)
+	}
+}
+
+// removeReviewer deletes the reviewer, and offers the reviewers that can take over each of their upcoming bookings
+func (r request) removeReviewer(reviewerSlackID string) {
+	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		r.update(toMsgOption(fmt.Sprintf("<@%s> is not a reviewer anymore.", reviewerSlackID)))
+		return
+	}
+
+	err = models.DeleteReviewer(r.ctx.Env, reviewer)
+	if err != nil {
+		log.Println("[ERROR] Could not delete reviewer in db ", err)
+		r.reply(toMsgOption("We were not able to remove the reviewer"))
+		return
+	}
+
+	err = models.RemoveReviewerFromCandidates(r.ctx.Env, reviewer.SlackID)
+	if err != nil {
+		log.Println("[ERROR] Could not unassign the reviewer from the candidates ", err)
+		r.reply(toMsgOption("There was an error. The reviewer is still assigned to some candidates."))
+	}
+
+	bookings := reviewer.UpcomingBookings(time.Now().Format(models.DateFormat))
+	msg := fmt.Sprintf("<@%s> removed the reviewer <@%s>, with %d upcoming bookings.", r.icb.User.ID, reviewer.SlackID, len(bookings))
+	r.update(toMsgOption(msg))
+
+	challenges := bookingChallenges(r.ctx, reviewer, bookings)
+	loc := r.ctx.getUserLocation(r.icb.User.ID)
+	for _, booking := range bookings {
+		challengeID := bookingChallengeID(reviewer, booking)
+		backfill(r.ctx, challengeID, reviewer.SlackID, booking.Occurrence)
+
+		alternatives, err := scheduling.AlternativeReviewers(r.ctx.Env, challengeID, booking.Occurrence, reviewer.SlackID)
+		if err != nil {
+			log.Println("[ERROR] Cannot find alternative reviewers - ", err)
+		}
+		if len(alternatives) > maxAlternatives {
+			alternatives = alternatives[:maxAlternatives]
+		}
+
+		sections := renderReassignBooking(reviewer, booking, challenges[challengeID], alternatives, loc)
+		err = r.reply(slack.MsgOptionBlocks(sections...))
+		if err != nil {
+			log.Println("[ERROR] Cannot send the booking to reassign - ", err)
+		}
+		if booking.BookedBy != "" && booking.BookedBy != r.icb.User.ID {
+			sections = renderReassignBooking(reviewer, booking, challenges[challengeID], alternatives, r.ctx.getUserLocation(booking.BookedBy))
+			err = r.ctx.postMessage(booking.BookedBy, slack.MsgOptionBlocks(sections...))
+			if err != nil {
+				log.Println("[ERROR] Cannot notify the booker - ", err)
+			}
+		}
 	}
-	r.ctx.postMessage(r.icb.Channel.ID, toMsgOption(msg))
 }
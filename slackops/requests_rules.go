@@ -0,0 +1,123 @@
+package slackops
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/scheduling"
+	"github.com/nlopes/slack"
+)
+
+func (r request) handleNewRule() error {
+	input := r.icb.Submission
+	startWeek, err := decodeWeek(input["start_date"])
+	if err != nil {
+		return inputErrors{"start_date": "Pick the week the rule starts."}
+	}
+	input["start_date"] = startWeek.Format(models.DateFormat)
+
+	rule, err := models.NewAvailabilityRule(input)
+	if err != nil {
+		log.Println("[ERROR] Invalid availability rule - ", err)
+		return inputErrorsOf(err)
+	}
+
+	go r.addRule(r.icb.State, rule)
+	return nil
+}
+
+func (r request) addRule(reviewerSlackID string, rule models.AvailabilityRule) {
+	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", reviewerSlackID)
+		r.reply(toMsgOption(errorMsg))
+		return
+	}
+
+	reviewer, err = scheduling.AddAvailabilityRule(r.ctx.Env, reviewer, rule)
+	if err != nil {
+		log.Println("[ERROR] Could not update reviewer in db ", err)
+		r.reply(toMsgOption("There was an error. The recurring availability cannot be added."))
+		return
+	}
+
+	r.postRules(reviewer)
+}
+
+func (r request) handleOutOfOffice() error {
+	away, err := models.NewDateRange(r.icb.Submission)
+	if err != nil {
+		log.Println("[ERROR] Invalid out of office dates - ", err)
+		return inputErrorsOf(err)
+	}
+
+	go r.addOutOfOffice(r.icb.State, away)
+	return nil
+}
+
+func (r request) addOutOfOffice(reviewerSlackID string, away models.DateRange) {
+	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		errorMsg := fmt.Sprintf("Reviewer <@%s> is not registered. Please register first using /reviewer new command.", reviewerSlackID)
+		r.reply(toMsgOption(errorMsg))
+		return
+	}
+
+	reviewer, err = scheduling.AddOutOfOffice(r.ctx.Env, reviewer, away)
+	if err != nil {
+		log.Println("[ERROR] Could not update reviewer in db ", err)
+		r.reply(toMsgOption("There was an error. The out of office dates cannot be added."))
+		return
+	}
+
+	r.postRules(reviewer)
+}
+
+func (r request) postRules(reviewer models.Reviewer) {
+	challenge, err := models.GetChallengeSetupByID(r.ctx.Env, reviewer.ChallengeID)
+	if err != nil {
+		log.Println("[ERROR] Invalid challenge for reviewer", err)
+	}
+
+	sections := renderAvailabilityRules(reviewer, challenge)
+	err = r.reply(slack.MsgOptionBlocks(sections...))
+	if err != nil {
+		log.Println("[ERROR] Cannot send the availability rules - ", err)
+	}
+}
+
+func (r request) handleRemoveRule(action actionType, encodedActionInfo string) error {
+	reviewerSlackID, id, err := decodeRuleActionInfo(encodedActionInfo)
+	if err != nil {
+		log.Println("[ERROR] Cannot decode rule info - ", err)
+		return err
+	}
+
+	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		return err
+	}
+
+	if action == removeRule {
+		reviewer, err = scheduling.RemoveAvailabilityRule(r.ctx.Env, reviewer, id)
+	} else {
+		reviewer, err = scheduling.RemoveOutOfOffice(r.ctx.Env, reviewer, id)
+	}
+	if err != nil {
+		log.Println("[ERROR] Could not update reviewer in db ", err)
+		r.reply(toMsgOption("There was an error. The recurring availability cannot be updated."))
+		return err
+	}
+
+	challenge, err := models.GetChallengeSetupByID(r.ctx.Env, reviewer.ChallengeID)
+	if err != nil {
+		log.Println("[ERROR] Invalid challenge for reviewer", err)
+	}
+
+	sections := renderAvailabilityRules(reviewer, challenge)
+	return r.update(slack.MsgOptionBlocks(sections...))
+}
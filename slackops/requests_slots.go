@@ -0,0 +1,222 @@
+package slackops
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/keremk/challenge-bot/scheduling"
+	"github.com/nlopes/slack"
+)
+
+func (r request) handleEditSlots(encodedActionInfo string) error {
+	operation, slotID, err := decodeSlotActionInfo(encodedActionInfo)
+	if err != nil {
+		log.Println("[ERROR] Cannot decode slot action - ", err)
+		return err
+	}
+	challengeID := r.icb.ActionCallback.BlockActions[0].Value
+
+	if operation == addSlot {
+		dialog := newSlotDialog(challengeID)
+		return r.ctx.openModal(r.icb.TriggerID, r.icb.Channel.ID, dialog)
+	}
+	if operation == editSlot {
+		challenge, err := models.GetChallengeByID(r.ctx.Env, challengeID)
+		if err != nil {
+			log.Println("[ERROR] Cannot find the challenge - ", err)
+			return err
+		}
+		slot := challenge.Slots[slotID]
+		if slot == nil {
+			r.reply(toMsgOption("Cannot find the slot, it may have been removed."))
+			return nil
+		}
+		return r.ctx.openModal(r.icb.TriggerID, r.icb.Channel.ID, editSlotDialog(challengeID, *slot))
+	}
+
+	go r.editSlots(challengeID, operation, slotID)
+	return nil
+}
+
+func (r request) editSlots(challengeID string, operation slotOperation, slotID string) {
+	challenge, err := models.GetChallengeByID(r.ctx.Env, challengeID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find the challenge - ", err)
+		r.reply(toMsgOption("Cannot find the challenge, it may have been removed."))
+		return
+	}
+	slotName := slotID
+	if slot := challenge.Slots[slotID]; slot != nil {
+		slotName = slot.Name
+	}
+
+	switch operation {
+	case moveSlotUp:
+		challenge, err = models.MoveSlot(challenge, slotID, -1)
+	case moveSlotDown:
+		challenge, err = models.MoveSlot(challenge, slotID, 1)
+	case deleteSlot:
+		challenge, err = models.RemoveSlot(challenge, slotID)
+	default:
+		err = fmt.Errorf("[ERROR] Unknown slot operation - %s", operation)
+	}
+	if err != nil {
+		log.Println("[ERROR] Cannot change the slots - ", err)
+		r.reply(toMsgOption(fmt.Sprintf("Cannot change the slot %s. A challenge needs at least one slot.", slotName)))
+		return
+	}
+
+	err = models.UpdateChallenge(r.ctx.Env, challenge)
+	if err != nil {
+		log.Println("[ERROR] Could not update challenge in db ", err)
+		r.reply(toMsgOption("There was an error. The slots cannot be updated."))
+		return
+	}
+
+	if operation == deleteSlot {
+		r.releaseSlot(challenge, slotID, slotName)
+	}
+	r.refreshSlots(challenge.ID, true)
+}
+
+// releaseSlot cancels the bookings of the removed slot and tells the reviewers
+func (r request) releaseSlot(challenge models.Challenge, slotID string, slotName string) {
+	reviewers, err := models.GetAllReviewersForChallenge(r.ctx.Env, challenge.ID)
+	if err != nil {
+		log.Println("[ERROR] Cannot get the reviewers of the challenge - ", err)
+		return
+	}
+
+	affected, cancelled, err := scheduling.ReleaseSlot(r.ctx.Env, reviewers, slotID, time.Now())
+	if err != nil {
+		log.Println("[ERROR] Cannot cancel the bookings of the slot - ", err)
+		r.reply(toMsgOption("There was an error. Some bookings of the removed slot could not be cancelled."))
+	}
+
+	lines := make([]string, 0, len(affected))
+	for _, reviewer := range affected {
+		bookings := cancelled[reviewer.SlackID]
+		dates := make([]string, 0, len(bookings))
+		for _, booking := range bookings {
+			dates = append(dates, fmt.Sprintf("%s (%s)", booking.Occurrence.Date, renderBookingPurpose(booking)))
+			backfill(r.ctx, challenge.ID, reviewer.SlackID, booking.Occurrence)
+		}
+		msg := fmt.Sprintf("The %s slot was removed from the %s challenge by <@%s>. Your bookings on %s are cancelled.",
+			slotName, challenge.Name, r.icb.User.ID, strings.Join(dates, ", "))
+		r.ctx.postMessage(reviewer.SlackID, toMsgOption(msg))
+		lines = append(lines, fmt.Sprintf("<@%s>: %s", reviewer.SlackID, strings.Join(dates, ", ")))
+	}
+	if len(lines) > 0 {
+		msg := fmt.Sprintf("Removing the %s slot cancelled these bookings, the reviewers are told:\n%s", slotName, strings.Join(lines, "\n"))
+		r.reply(toMsgOption(msg))
+	}
+}
+
+func (r request) handleAddSlot() error {
+	challengeID := r.icb.State
+	challenge, err := models.GetChallengeByID(r.ctx.Env, challengeID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find the challenge - ", err)
+		return err
+	}
+
+	slot, err := models.NewSlot(challenge, r.icb.Submission)
+	if err != nil {
+		log.Println("[ERROR] Invalid slot - ", err)
+		return inputErrorsOf(err)
+	}
+
+	go r.addSlot(challenge, slot)
+	return nil
+}
+
+func (r request) addSlot(challenge models.Challenge, slot models.Slot) {
+	challenge = models.AddSlot(challenge, slot)
+	err := models.UpdateChallenge(r.ctx.Env, challenge)
+	if err != nil {
+		log.Println("[ERROR] Could not update challenge in db ", err)
+		r.reply(toMsgOption("There was an error. The slot cannot be added."))
+		return
+	}
+
+	r.refreshSlots(challenge.ID, false)
+}
+
+func (r request) handleEditSlot() error {
+	slotID, challengeID, err := decodeSlotState(r.icb.State)
+	if err != nil {
+		log.Println("[ERROR] Cannot decode the slot - ", err)
+		return err
+	}
+	challenge, err := models.GetChallengeByID(r.ctx.Env, challengeID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find the challenge - ", err)
+		return err
+	}
+	existing := challenge.Slots[slotID]
+	if existing == nil {
+		r.reply(toMsgOption("Cannot find the slot, it may have been removed."))
+		return nil
+	}
+	previous := *existing
+
+	challenge, err = models.EditSlot(challenge, slotID, r.icb.Submission)
+	if err != nil {
+		log.Println("[ERROR] Invalid slot - ", err)
+		return inputErrorsOf(err)
+	}
+
+	go r.editSlot(challenge, previous)
+	return nil
+}
+
+// editSlot saves the edited slot. Its day and times cannot change while reviewers are booked for it, as the
+// bookings are for the dates of the current day.
+func (r request) editSlot(challenge models.Challenge, previous models.Slot) {
+	if !challenge.Slots[previous.ID].SameTime(previous) {
+		reviewers, err := models.GetAllReviewersForChallenge(r.ctx.Env, challenge.ID)
+		if err != nil {
+			log.Println("[ERROR] Cannot get the reviewers of the challenge - ", err)
+			r.reply(toMsgOption("There was an error. The slot cannot be updated."))
+			return
+		}
+		count := 0
+		for _, bookings := range scheduling.FutureBookingsForSlot(reviewers, previous.ID, time.Now()) {
+			count += len(bookings)
+		}
+		if count > 0 {
+			msg := fmt.Sprintf("The %s slot has %d upcoming bookings, so its day and times cannot change. Add a new slot and remove this one to cancel them.",
+				previous.Name, count)
+			r.reply(toMsgOption(msg))
+			return
+		}
+	}
+
+	err := models.UpdateChallenge(r.ctx.Env, challenge)
+	if err != nil {
+		log.Println("[ERROR] Could not update challenge in db ", err)
+		r.reply(toMsgOption("There was an error. The slot cannot be updated."))
+		return
+	}
+
+	r.refreshSlots(challenge.ID, false)
+}
+
+// refreshSlots shows the changed slots, in place of the original message if there is one
+func (r request) refreshSlots(challengeID string, replace bool) {
+	challenge, err := models.GetChallengeSetupByID(r.ctx.Env, challengeID)
+	if err != nil {
+		log.Println("[ERROR] Cannot find the challenge setup - ", err)
+		return
+	}
+
+	msg := slack.MsgOptionBlocks(challengeSlotSections(r.ctx, challenge)...)
+	if replace {
+		r.update(msg)
+	} else {
+		r.reply(msg)
+	}
+}
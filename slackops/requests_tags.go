@@ -0,0 +1,174 @@
+package slackops
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/keremk/challenge-bot/models"
+	"github.com/nlopes/slack"
+)
+
+func (r request) handleEditTagAction(operation string) error {
+	taxonomy, err := models.GetTaxonomy(r.ctx.Env)
+	if err != nil {
+		log.Println("[ERROR] Cannot load the tags - ", err)
+		return err
+	}
+
+	if operation == tagsPage {
+		page, err := strconv.Atoi(r.icb.ActionCallback.BlockActions[0].Value)
+		if err != nil {
+			return err
+		}
+		return r.update(slack.MsgOptionBlocks(renderTags(taxonomy, page)...))
+	}
+
+	tag := models.Tag{}
+	if operation == editTag {
+		tagID := r.icb.ActionCallback.BlockActions[0].Value
+		existing, ok := taxonomy[tagID]
+		if !ok {
+			return fmt.Errorf("[ERROR] Unknown tag - %s", tagID)
+		}
+		tag = existing
+	}
+
+	dialog := newTagDialog(tag, taxonomy)
+	return r.ctx.openModal(r.icb.TriggerID, r.icb.Channel.ID, dialog)
+}
+
+func (r request) handleEditTag() error {
+	tag, err := models.NewTag(r.icb.Submission)
+	if err != nil {
+		log.Println("[ERROR] Invalid tag - ", err)
+		return err
+	}
+
+	go r.saveTag(r.icb.State, tag)
+	return nil
+}
+
+// saveTag adds the tag to the vocabulary, or edits the tag with the ID. Tag IDs do not change, so reviewers keep
+// their tags when a tag is renamed.
+func (r request) saveTag(tagID string, tag models.Tag) {
+	taxonomy, err := models.GetTaxonomy(r.ctx.Env)
+	if err != nil {
+		log.Println("[ERROR] Cannot load the tags - ", err)
+		r.reply(toMsgOption("There was an error. The tag cannot be saved."))
+		return
+	}
+
+	if id, ok := taxonomy.Resolve(tag.Name); ok && id != tagID {
+		errorMsg := fmt.Sprintf("There is already a tag or alias %s, please edit it instead.", tag.Name)
+		r.reply(toMsgOption(errorMsg))
+		return
+	}
+	if tagID != "" {
+		tag.ID = tagID
+	}
+
+	err = taxonomy.Check(tag)
+	if err != nil {
+		log.Println("[ERROR] Invalid tag - ", err)
+		errorMsg := fmt.Sprintf("The tag %s cannot be saved. Aliases have to be unique and a tag cannot be broader than itself.", tag.Name)
+		r.reply(toMsgOption(errorMsg))
+		return
+	}
+
+	err = models.UpdateTag(r.ctx.Env, tag)
+	if err != nil {
+		log.Println("[ERROR] Could not update tag in db ", err)
+		r.reply(toMsgOption("There was an error. The tag cannot be saved."))
+		return
+	}
+
+	taxonomy[tag.ID] = tag
+	r.reply(slack.MsgOptionBlocks(renderTags(taxonomy, tagPageOf(taxonomy, tag.ID))...))
+}
+
+func (r request) handlePickTagsAction(reviewerSlackID string) error {
+	challengeID := r.icb.ActionCallback.BlockActions[0].Value
+	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		return err
+	}
+	member, err := reviewer.ForChallenge(challengeID)
+	if err != nil {
+		log.Println("[ERROR] Reviewer does not review the challenge - ", err)
+		return err
+	}
+	taxonomy, err := models.GetTaxonomy(r.ctx.Env)
+	if err != nil {
+		log.Println("[ERROR] Cannot load the tags - ", err)
+		return err
+	}
+
+	dialog := newTagPickerDialog(member, taxonomy)
+	return r.ctx.openModal(r.icb.TriggerID, r.icb.Channel.ID, dialog)
+}
+
+func (r request) handlePickTags() error {
+	reviewerSlackID, challengeID, err := decodeTagPickerState(r.icb.State)
+	if err != nil {
+		log.Println("[ERROR] Cannot decode the tag picker - ", err)
+		return err
+	}
+	picked := make([]string, 0)
+	if value := r.icb.Submission["tags"]; value != "" {
+		picked = strings.Split(value, ",")
+	}
+
+	go r.saveReviewerTags(reviewerSlackID, challengeID, picked)
+	return nil
+}
+
+// saveReviewerTags sets the picked tags of the reviewer for the challenge. The tags that were not in the select,
+// either unknown or left out of it, are kept.
+func (r request) saveReviewerTags(reviewerSlackID, challengeID string, picked []string) {
+	reviewer, err := models.GetReviewerBySlackID(r.ctx.Env, reviewerSlackID)
+	if err != nil {
+		log.Println("[ERROR] No such reviewer registered.", err)
+		return
+	}
+	taxonomy, err := models.GetTaxonomy(r.ctx.Env)
+	if err != nil {
+		log.Println("[ERROR] Cannot load the tags - ", err)
+		return
+	}
+	member, err := reviewer.ForChallenge(challengeID)
+	if err != nil {
+		log.Println("[ERROR] Reviewer does not review the challenge - ", err)
+		return
+	}
+
+	listed := make(map[string]bool)
+	groups, _ := tagOptionGroups(taxonomy)
+	for _, group := range groups {
+		for _, o := range group.Options {
+			listed[o.Value] = true
+		}
+	}
+	tags := picked
+	for _, tag := range taxonomy.TagsOf(member) {
+		if !listed[tag] {
+			tags = append(tags, tag)
+		}
+	}
+
+	reviewer, err = reviewer.SetTags(challengeID, tags, taxonomy)
+	if err != nil {
+		log.Println("[ERROR] Cannot set the tags - ", err)
+		return
+	}
+	err = models.UpdateReviewer(r.ctx.Env, reviewer)
+	if err != nil {
+		log.Println("[ERROR] Could not update reviewer in db ", err)
+		r.reply(toMsgOption("There was an error. The tags cannot be updated."))
+		return
+	}
+
+	postTagPicker(r.ctx, r.reply, reviewer, challengeID)
+}
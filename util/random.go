@@ -1,6 +1,9 @@
 package util
 
 import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"log"
 	"math/rand"
 	"time"
 )
@@ -15,3 +18,14 @@ func RandomString(n int) string {
 	}
 	return string(b)
 }
+
+// SecretToken returns a random token that is safe to use in secret URLs
+func SecretToken() string {
+	b := make([]byte, 24)
+	_, err := crand.Read(b)
+	if err != nil {
+		log.Println("[ERROR] Cannot read random bytes, falling back to pseudo random - ", err)
+		return RandomString(48)
+	}
+	return hex.EncodeToString(b)
+}